@@ -3,10 +3,18 @@ package bari
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"math"
+	"math/big"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 	"unicode"
 	"unicode/utf16"
 	"unicode/utf8"
@@ -34,6 +42,7 @@ const (
 	// StringEvent is emitted for each string.
 	StringEvent
 	// NumberEvent is emitted for each number. The associated value will be either a float64 or a int64.
+	// By default, an integer which doesn't fit in an int64 is a uint64 or a *big.Int, see IntegerOverflow.
 	NumberEvent
 	// BooleanEvent is emitted for each boolean value.
 	BooleanEvent
@@ -41,6 +50,40 @@ const (
 	NullEvent
 	// EOFEvent is emitted when parsing has stopped, either because the source input is finished or because there was an error.
 	EOFEvent
+	// ErrorEvent is emitted for each error the parser recovers from, when it is created with CollectErrors.
+	// Its Error is a ParseError.
+	ErrorEvent
+	// SkippedEvent is emitted in place of a value which can't be parsed, when the parser is created with
+	// SkipInvalidValues. Its Value is the text of the input which was skipped, and its Error a ParseError.
+	SkippedEvent
+	// DocumentStartEvent is emitted before each top-level value, when the parser is created with DocumentEvents.
+	// Its Line, Column and Offset locate the first byte of the document.
+	DocumentStartEvent
+	// DocumentEndEvent is emitted after each top-level value, when the parser is created with DocumentEvents.
+	// Its Line, Column and Offset locate the byte following the document.
+	DocumentEndEvent
+)
+
+// A ValueKind tells which of the typed fields of an Event holds its value.
+type ValueKind uint8
+
+const (
+	// NoValue is the kind of an event without a typed value: the events of objects and arrays, EOFEvent,
+	// ErrorEvent and SkippedEvent, but also a NumberEvent for an integer converted to a uint64 or a *big.Int,
+	// which is only in Value, and the values not decoded by Valid.
+	NoValue ValueKind = iota
+	// StringValue is the kind of a StringEvent, whose string is in Str.
+	StringValue
+	// IntValue is the kind of a NumberEvent for an integer fitting in an int64, which is in Int.
+	IntValue
+	// FloatValue is the kind of a NumberEvent whose number is a float64, in Float.
+	FloatValue
+	// BoolValue is the kind of a BooleanEvent, whose boolean is in Bool.
+	BoolValue
+	// NullValue is the kind of a NullEvent.
+	NullValue
+	// BytesValue is the kind of a StringEvent of a parser created with ByteStrings, whose string is in Bytes.
+	BytesValue
 )
 
 // A Event represents a point of interest in a JSON document.
@@ -61,439 +104,3409 @@ type Event struct {
 	Type  EventType
 	Value interface{}
 	Error error
+
+	// IsInt is true for a NumberEvent whose literal has neither a fraction nor an exponent.
+	// It only depends on the syntax of the number, so 10 is an integer but 10.0 is not.
+	IsInt bool
+
+	// ValueKind tells which of Str, Int, Float and Bool holds the value of the event, which is in Value too unless
+	// the parser was created with TypedValues. Reading them avoids the type assertions on Value.
+	ValueKind ValueKind
+	Bool      bool
+	Str       string
+	Int       int64
+	Float     float64
+	// Bytes is only valid until the next call to Next or Peek, see ByteStrings.
+	Bytes []byte
+
+	// Document is the index of the document of a DocumentStartEvent or a DocumentEndEvent, starting at 0.
+	Document int
+	// Count is the number of members of the object of an ObjectEndEvent, or of elements of the array of an
+	// ArrayEndEvent.
+	Count int
+
+	// Seq is the sequence number of the event, if the parser was created with SequenceNumbers: the number of
+	// events the parser returned before it, across documents, since it was created or Reset. The event returned
+	// by Peek has the number Next gives it.
+	Seq uint64
+
+	// Depth is the number of objects and arrays enclosing the token of the event. The start and end events of a
+	// container have the same depth, 0 for a top-level container, and the events of its members or elements,
+	// keys included, have the next one. The events of an expanded string start at the depth of the string.
+	// An EOFEvent always has a depth of 0.
+	Depth int
+
+	// Line, Column and Offset locate the first byte of the token of the event, if the parser was created with
+	// EventPositions, like the Line, Column and Offset of a ParseError: the bracket of an object or an array
+	// for its start and end events, the opening quote of a string, the first character of a number or a literal.
+	// An ObjectKeyEvent is at the quote of the key, an ObjectValueEvent at the colon following it, and the events
+	// of an expanded string at its quote. ErrorEvent and EOFEvent are at the position of their error.
+	Line   int
+	Column int
+	Offset int64
+	// StartOffset and EndOffset are the span of the event in the input, with EventPositions, from the Offset of
+	// its token to the offset following it: input[StartOffset:EndOffset] is the text of the token, a string with
+	// its quotes and escapes for instance. The span of an ObjectEndEvent or an ArrayEndEvent is its whole
+	// container, from the opening bracket. The events of an expanded string span the string, and ErrorEvent and
+	// EOFEvent are empty spans at their error.
+	StartOffset int64
+	EndOffset   int64
+}
+
+// Text returns the string of a StringEvent, or the key of an ObjectKeyEvent of a parser created with
+// CompactEvents, and false for other events.
+//
+// Like the other accessors of the value, it falls back to Value for an event without a ValueKind, such as an
+// event built by hand.
+func (e Event) Text() (string, bool) {
+	switch e.ValueKind {
+	case StringValue:
+		return e.Str, true
+	case BytesValue:
+		return string(e.Bytes), true
+	}
+	s, ok := e.Value.(string)
+	return s, ok && e.ValueKind == NoValue && (e.Type == StringEvent || e.Type == ObjectKeyEvent)
+}
+
+// Int64 returns the integer of a NumberEvent fitting in an int64, and false for other events. The float64 of a
+// NumberEvent is returned too if it converts to an int64 exactly, like 1e3.
+func (e Event) Int64() (int64, bool) {
+	if i, ok := e.int64(); ok {
+		return i, true
+	}
+
+	f, ok := e.float64()
+	if !ok || f != math.Trunc(f) || f < -(1<<63) || f >= 1<<63 {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+// Float64 returns the number of a NumberEvent holding a float64 or an int64, which is converted, and false for
+// other events.
+func (e Event) Float64() (float64, bool) {
+	if f, ok := e.float64(); ok {
+		return f, true
+	}
+
+	i, ok := e.int64()
+	return float64(i), ok
+}
+
+func (e Event) int64() (int64, bool) {
+	if e.ValueKind == IntValue {
+		return e.Int, true
+	}
+	i, ok := e.Value.(int64)
+	return i, ok && e.ValueKind == NoValue
+}
+
+func (e Event) float64() (float64, bool) {
+	if e.ValueKind == FloatValue {
+		return e.Float, true
+	}
+	f, ok := e.Value.(float64)
+	return f, ok && e.ValueKind == NoValue
+}
+
+// Boolean returns the boolean of a BooleanEvent, and false for other events.
+func (e Event) Boolean() (bool, bool) {
+	if e.ValueKind == BoolValue {
+		return e.Bool, true
+	}
+	b, ok := e.Value.(bool)
+	return b, ok && e.ValueKind == NoValue
+}
+
+// IsScalar reports whether e is a StringEvent, a NumberEvent, a BooleanEvent or a NullEvent.
+func (e Event) IsScalar() bool {
+	switch e.Type {
+	case StringEvent, NumberEvent, BooleanEvent, NullEvent:
+		return true
+	}
+	return false
+}
+
+// IsContainerStart reports whether e is an ObjectStartEvent or an ArrayStartEvent.
+func (e Event) IsContainerStart() bool {
+	return e.Type == ObjectStartEvent || e.Type == ArrayStartEvent
+}
+
+// IsContainerEnd reports whether e is an ObjectEndEvent or an ArrayEndEvent.
+func (e Event) IsContainerEnd() bool {
+	return e.Type == ObjectEndEvent || e.Type == ArrayEndEvent
+}
+
+// An EventKind groups the types of events which are usually handled alike, see Event.Kind.
+type EventKind uint8
+
+const (
+	// OtherKind is the kind of the events which are not part of the values of a document: ObjectValueEvent,
+	// ErrorEvent, SkippedEvent, DocumentStartEvent, DocumentEndEvent and UnknownEvent.
+	OtherKind EventKind = iota
+	// ScalarKind is the kind of StringEvent, NumberEvent, BooleanEvent and NullEvent. The StringEvent holding a
+	// key, following an ObjectKeyEvent unless the parser was created with CompactEvents, is a ScalarKind too.
+	ScalarKind
+	// KeyKind is the kind of ObjectKeyEvent.
+	KeyKind
+	// StartObjectKind is the kind of ObjectStartEvent.
+	StartObjectKind
+	// EndObjectKind is the kind of ObjectEndEvent.
+	EndObjectKind
+	// StartArrayKind is the kind of ArrayStartEvent.
+	StartArrayKind
+	// EndArrayKind is the kind of ArrayEndEvent.
+	EndArrayKind
+	// EOFKind is the kind of EOFEvent.
+	EOFKind
+)
+
+// Kind returns the kind of e, which collapses the types of events for a simpler dispatch.
+func (e Event) Kind() EventKind {
+	switch e.Type {
+	case StringEvent, NumberEvent, BooleanEvent, NullEvent:
+		return ScalarKind
+	case ObjectKeyEvent:
+		return KeyKind
+	case ObjectStartEvent:
+		return StartObjectKind
+	case ObjectEndEvent:
+		return EndObjectKind
+	case ArrayStartEvent:
+		return StartArrayKind
+	case ArrayEndEvent:
+		return EndArrayKind
+	case EOFEvent:
+		return EOFKind
+	}
+	return OtherKind
+}
+
+// Copy returns a copy of e whose Bytes, for a parser created with ByteStrings, stay valid.
+func (e Event) Copy() Event {
+	if e.Bytes != nil {
+		e.Bytes = append([]byte(nil), e.Bytes...)
+	}
+	return e
+}
+
+// String returns a human readable description of e: its type, followed by its value and error if any, like
+// StringEvent("foo"), NumberEvent(10) or EOFEvent(err: unexpected end of file @1:2).
+func (e Event) String() string {
+	var args []string
+	if v, ok := e.value(); ok {
+		if s, ok := v.(string); ok {
+			args = append(args, strconv.Quote(s))
+		} else {
+			args = append(args, fmt.Sprint(v))
+		}
+	}
+	if e.Error != nil {
+		if err, ok := e.Error.(ParseError); ok {
+			args = append(args, fmt.Sprintf("err: %s @%d:%d", err.Message, err.Line, err.Position))
+		} else {
+			args = append(args, "err: "+e.Error.Error())
+		}
+	}
+
+	if len(args) == 0 {
+		return e.Type.String()
+	}
+	return e.Type.String() + "(" + strings.Join(args, ", ") + ")"
+}
+
+// MarshalJSON encodes e as an object with the name of its type, its value and the message of its error, like
+// {"type":"NumberEvent","value":10}. The value and the error are omitted when the event doesn't have one, the
+// value of a NullEvent included.
+func (e Event) MarshalJSON() ([]byte, error) {
+	v := struct {
+		Type  string      `json:"type"`
+		Value interface{} `json:"value,omitempty"`
+		Error string      `json:"error,omitempty"`
+	}{Type: e.Type.String()}
+
+	v.Value, _ = e.value()
+	if e.Error != nil {
+		v.Error = e.Error.Error()
+	}
+
+	return json.Marshal(v)
+}
+
+// value returns the value of e, from its typed fields or from Value, with Bytes as a string.
+func (e Event) value() (interface{}, bool) {
+	switch e.ValueKind {
+	case StringValue:
+		return e.Str, true
+	case IntValue:
+		return e.Int, true
+	case FloatValue:
+		return e.Float, true
+	case BoolValue:
+		return e.Bool, true
+	case BytesValue:
+		return string(e.Bytes), true
+	case NullValue:
+		return nil, false
+	}
+	return e.Value, e.Value != nil
 }
 
 // A Parser reads and parses JSON documents from an input stream.
 type Parser struct {
-	br *bufio.Reader
+	// br is the input of a parser reading from an io.Reader: the reader itself if it is already buffered, or own,
+	// the bufio.Reader of bufSize bytes wrapping it, which is reused by Reset. The input of a parser reading a byte
+	// slice, which sets inMemory, is data, read up to pos.
+	br       byteInput
+	own      *bufio.Reader
+	bufSize  int
+	data     []byte
+	pos      int
+	inMemory bool
 
 	err error
-	ch  chan Event
 
-	unreadChangesLine bool
-	line              int
-	position          int
+	// queue holds the events parsed but not yet returned by Next, from index head.
+	queue []Event
+	head  int
+	// first is set until a document is started, since the creation of the parser or the start of a call to Parse
+	// which follows the end of the input. exhausted is set once the end of the input was reached.
+	first     bool
+	exhausted bool
+
+	// buf holds the bytes of the token being read.
+	buf bytes.Buffer
+	// strs holds the strings of the queued events of a parser created with ByteStrings. It is reused once they
+	// are all returned.
+	strs []byte
+
+	// skipping is set while SkipValue discards the events of a value, skipDepth being the number of its containers
+	// left open. skipped is set once the whole value was discarded.
+	skipping  bool
+	skipDepth int
+	skipped   bool
+	skipper   skipScanner
+
+	// offset is the number of bytes read. line is the current line, which starts at offset lineStart,
+	// and prevLineStart is where the previous line starts, to go back to it when a newline is unread.
+	offset        int64
+	line          int
+	lineStart     int64
+	prevLineStart int64
+	lastNewline   bool
+
+	// column is the number of characters read in the current line, and prevColumn the column of the
+	// newline ending the previous line. columns counts them, and lastColumnStart and prevColumns allow
+	// undoing the last byte.
+	column          int
+	prevColumn      int
+	columns         columnCounter
+	prevColumns     columnCounter
+	lastColumnStart bool
+
+	// last is the last byte read. It is read again after a call to unreadByte, which sets unread.
+	last   byte
+	unread bool
+
+	// raw holds the bytes read while capturing is set, to skip invalid values.
+	raw       []byte
+	capturing bool
+
+	// document is the index of the current document, which starts after docColumn bytes of line docLine.
+	document  int
+	docLine   int
+	docColumn int
+	// seq is the sequence number of the next event returned.
+	seq uint64
+	// stats counts what was parsed, for Stats.
+	stats Stats
+	// nextProgress is the offset at which the Progress callback is called next, and progressAt the offset it was
+	// last called with.
+	nextProgress int64
+	progressAt   int64
+	// teed holds the bytes parsed but not written yet to the writer given to Tee.
+	teed []byte
+
+	// stack holds the state of every object and array enclosing the current position, innermost last, and
+	// counts the number of their members or elements started so far. starts holds their offsets, for
+	// EventPositions.
+	stack  []parseState
+	counts []int
+	starts []int64
+	// keys holds the keys read in every object enclosing the current position, when duplicates are disallowed.
+	keys []map[string]bool
+
+	index *PositionIndex
+	opts  options
+
+	// markLine, markColumn and markOffset locate the start of the last token, for EventPositions.
+	markLine   int
+	markColumn int
+	markOffset int64
+
+	// path follows the location of the events emitted, for error messages. inValue is set while a value of the
+	// innermost container is being read, until its first event is emitted. consumed follows the location of the
+	// events returned by Next, for Path.
+	path     pathTracker
+	inValue  bool
+	consumed pathTracker
+
+	// mu guards the state of the parser, so that More may be called while Parse runs.
+	mu sync.Mutex
+
+	// failure is the error which made the parser fail, guarded by failureMu so that Err may be called concurrently
+	// with Parse.
+	failure   error
+	failureMu sync.Mutex
+
+	// done is closed by Stop, which also sets stopped so that it can be checked cheaply for every byte, and
+	// stopCause, the error of the stopped parser.
+	done      chan struct{}
+	stopped   int32
+	stopCause error
+	stopOnce  *sync.Once
+	// timer stops the parser once the Timeout is exceeded. It is armed when the parser starts reading a document.
+	timer *time.Timer
 }
 
 // A ParseError is attached to an event in case of a parsing error.
 //
 // It contains additional information about where the error occurred in the input stream.
 type ParseError struct {
-	Message  string
+	Message string
+	// Line and Position locate the offending byte in the input, across all of its documents: Position is its
+	// column in bytes, starting at 1, and Offset is its offset from the start of the input, starting at 0.
+	// When the input ends early, the offending byte is the one which is missing, right after the last one.
+	Line     int
+	Position int
+	Offset   int64
+	// Column is like Position, counting characters instead of bytes: a multi-byte UTF-8 sequence counts as one
+	// column, like in text editors. An escape sequence counts as the characters it is written with.
+	Column int
+
+	// Document is the index of the document containing the error, starting at 0. DocumentLine and
+	// DocumentPosition locate the error relatively to the start of the document.
+	Document         int
+	DocumentLine     int
+	DocumentPosition int
+
+	// Path is the JSON pointer of the value being parsed when the error occurred: the member or element the
+	// parser is in or after, or the object or array itself if it is empty so far. Context tells where the parser
+	// is relatively to it, like `after object member "/config/items/3/name"`. Both are empty for an error outside
+	// of any object or array.
+	Path    string
+	Context string
+
+	// Err is the underlying error: ErrUnexpectedEOF when the input ends early, the error of the reader when it
+	// fails, the *strconv.NumError of a number which can't be converted, a LimitError when the input exceeded a
+	// configured limit, or one of ErrInvalidCharacter, ErrInvalidNumber, ErrInvalidString and ErrDuplicateKey
+	// for malformed input.
+	Err error
+}
+
+func (p ParseError) Error() string {
+	if p.Context != "" {
+		return fmt.Sprintf("ParseError: l:%d pos:%d msg:%s %s", p.Line, p.Position, p.Message, p.Context)
+	}
+
+	return fmt.Sprintf("ParseError: l:%d pos:%d msg:%s", p.Line, p.Position, p.Message)
+}
+
+// Unwrap returns the underlying error.
+func (p ParseError) Unwrap() error {
+	return p.Err
+}
+
+// MarshalJSON encodes p as an object with its message and its location, like
+// {"message":"unexpected end of file","line":1,"position":2,"offset":1}.
+func (p ParseError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Message  string `json:"message"`
+		Line     int    `json:"line"`
+		Position int    `json:"position"`
+		Offset   int64  `json:"offset"`
+	}{p.Message, p.Line, p.Position, p.Offset})
+}
+
+// ErrLimitExceeded matches every LimitError with errors.Is.
+var ErrLimitExceeded = errors.New("limit exceeded")
+
+// A Limit names a policy limit enforced by the parser.
+type Limit string
+
+// MaxDepthLimit is the limit configured with MaxDepth.
+const MaxDepthLimit Limit = "max depth"
+
+// A LimitError is wrapped by the ParseError emitted when the input exceeds a limit configured on the parser.
+//
+// It allows callers to tell apart malformed input from input that is merely too big, using errors.As or
+// errors.Is with ErrLimitExceeded.
+type LimitError struct {
+	Limit    Limit
+	Value    int64
 	Line     int
 	Position int
 }
 
-func (p ParseError) Error() string {
-	return fmt.Sprintf("ParseError: l:%d pos:%d msg:%s", p.Line, p.Position, p.Message)
-}
+func (e LimitError) Error() string {
+	return fmt.Sprintf("%s limit of %d exceeded", e.Limit, e.Value)
+}
+
+// Is reports whether target is ErrLimitExceeded.
+func (e LimitError) Is(target error) bool {
+	return target == ErrLimitExceeded
+}
+
+// NewParser creates a new parser that reads from r, configured with opts.
+//
+// A *bufio.Reader, or any reader implementing io.ByteReader like a *bytes.Reader, is read directly instead of
+// being wrapped in a new bufio.Reader, which would copy its input to a second buffer.
+func NewParser(r io.Reader, opts ...Option) *Parser {
+	p := newParser(opts)
+	p.setReader(r)
+
+	return p
+}
+
+// NewParserSize is like NewParser, with a buffer of size bytes for reading r instead of the 4096 bytes of bufio.
+//
+// A small buffer saves memory when many parsers are mostly idle, and a large one saves reads of r on big inputs.
+// Tokens larger than the buffer are still parsed. A size below 16 bytes, the minimum of bufio, is raised to it,
+// and to the length of the longest prefix given to StripXSSIPrefix.
+//
+// Unlike NewParser, only a *bufio.Reader of at least size bytes is read directly.
+func NewParserSize(r io.Reader, size int, opts ...Option) *Parser {
+	p := newParser(opts)
+	p.bufSize = size
+	for _, prefix := range p.opts.xssiPrefixes {
+		if len(prefix) > p.bufSize {
+			p.bufSize = len(prefix)
+		}
+	}
+	p.setReader(r)
+
+	return p
+}
+
+// byteInput is the input of a parser reading from an io.Reader, as provided by bufio.Reader.
+type byteInput interface {
+	ReadByte() (byte, error)
+	// Peek returns up to n of the next bytes without reading them, and Buffered how many can be peeked without
+	// reading the underlying reader.
+	Peek(n int) ([]byte, error)
+	Buffered() int
+	Discard(n int) (int, error)
+}
+
+// setReader makes the parser read r directly if it is already buffered, and through own otherwise.
+func (p *Parser) setReader(r io.Reader) {
+	longest := 0
+	for _, prefix := range p.opts.xssiPrefixes {
+		if len(prefix) > longest {
+			longest = len(prefix)
+		}
+	}
+
+	switch br := r.(type) {
+	case *bufio.Reader:
+		if br.Size() >= p.bufSize && br.Size() >= longest {
+			p.br = br
+			return
+		}
+	case io.ByteReader:
+		if p.bufSize == 0 {
+			p.br = &byteReaderInput{r: br}
+			return
+		}
+	}
+
+	switch {
+	case p.own != nil:
+		p.own.Reset(r)
+	case p.bufSize > 0:
+		p.own = bufio.NewReaderSize(r, p.bufSize)
+	default:
+		p.own = bufio.NewReader(r)
+	}
+	p.br = p.own
+}
+
+// byteReaderInput reads an io.ByteReader given to a parser, holding the bytes peeked from it until they are read.
+type byteReaderInput struct {
+	r io.ByteReader
+	// ahead holds the peeked bytes from index head.
+	ahead []byte
+	head  int
+}
+
+func (b *byteReaderInput) ReadByte() (byte, error) {
+	if b.head < len(b.ahead) {
+		b.head++
+		return b.ahead[b.head-1], nil
+	}
+
+	return b.r.ReadByte()
+}
+
+func (b *byteReaderInput) Peek(n int) ([]byte, error) {
+	if b.head == len(b.ahead) {
+		b.ahead, b.head = b.ahead[:0], 0
+	}
+
+	for len(b.ahead)-b.head < n {
+		c, err := b.r.ReadByte()
+		if err != nil {
+			return b.ahead[b.head:], err
+		}
+		b.ahead = append(b.ahead, c)
+	}
+
+	return b.ahead[b.head : b.head+n], nil
+}
+
+func (b *byteReaderInput) Buffered() int {
+	return len(b.ahead) - b.head
+}
+
+func (b *byteReaderInput) Discard(n int) (int, error) {
+	for i := 0; i < n; i++ {
+		if _, err := b.ReadByte(); err != nil {
+			return i, err
+		}
+	}
+
+	return n, nil
+}
+
+// NewBytesParser creates a new parser that reads data, configured with opts. It reads data in place, without
+// the buffering needed by a reader.
+func NewBytesParser(data []byte, opts ...Option) *Parser {
+	p := newParser(opts)
+	p.data = data
+	p.inMemory = true
+
+	return p
+}
+
+// ParseBytes parses data and emits its events on ch, like Parse.
+func ParseBytes(data []byte, ch chan Event, opts ...Option) error {
+	return NewBytesParser(data, opts...).Parse(ch)
+}
+
+func newParser(opts []Option) *Parser {
+	p := &Parser{
+		line:     1,
+		docLine:  1,
+		first:    true,
+		opts:     options{maxDepth: DefaultMaxDepth},
+		done:     make(chan struct{}),
+		stopOnce: new(sync.Once),
+	}
+	for _, opt := range opts {
+		opt(&p.opts)
+	}
+	if p.opts.indexLines {
+		p.index = &PositionIndex{}
+	}
+	p.resetProgress()
+
+	return p
+}
+
+// Reset makes the parser read r from the start, as if it was created by NewParser with the same options, while
+// reusing its buffers. It clears the failed state of the parser, and its stopped state.
+//
+// It must not be called while Parse is running.
+func (p *Parser) Reset(r io.Reader) {
+	p.setReader(r)
+	p.data, p.pos, p.inMemory = nil, 0, false
+	p.reset()
+}
+
+// ResetBytes is like Reset, making the parser read data like a parser created by NewBytesParser.
+func (p *Parser) ResetBytes(data []byte) {
+	if p.own != nil {
+		// Drop the previous reader.
+		p.own.Reset(nil)
+	}
+	p.br = nil
+	p.data, p.pos, p.inMemory = data, 0, true
+	p.reset()
+}
+
+func (p *Parser) reset() {
+	p.buf.Reset()
+
+	p.err = nil
+	p.setFailure(nil)
+	p.queue, p.head = p.queue[:0], 0
+	p.strs = p.strs[:0]
+	p.first, p.exhausted = true, false
+
+	p.offset, p.line, p.lineStart, p.prevLineStart, p.lastNewline = 0, 1, 0, 0, false
+	p.column, p.prevColumn = 0, 0
+	p.columns, p.prevColumns, p.lastColumnStart = columnCounter{}, columnCounter{}, false
+	p.last, p.unread = 0, false
+	p.raw, p.capturing = p.raw[:0], false
+	p.document, p.docLine, p.docColumn = 0, 1, 0
+	p.seq = 0
+	p.stats = Stats{}
+	p.resetProgress()
+	p.teed = p.teed[:0]
+
+	p.stack, p.counts, p.starts = p.stack[:0], p.counts[:0], p.starts[:0]
+	p.keys = p.keys[:0]
+	if p.index != nil {
+		// The previous index may still be in use.
+		p.index = &PositionIndex{}
+	}
+
+	p.path = pathTracker{frames: p.path.frames[:0]}
+	p.inValue = false
+	p.consumed = pathTracker{frames: p.consumed.frames[:0]}
+
+	p.disarm()
+	if atomic.LoadInt32(&p.stopped) != 0 {
+		// Wait for the stop, which may come from the timer of the Timeout, to return.
+		p.stopOnce.Do(func() {})
+		p.done = make(chan struct{})
+		p.stopped = 0
+		p.stopCause = nil
+		p.stopOnce = new(sync.Once)
+	}
+}
+
+// PositionIndex returns the index of the lines read so far, or nil if the parser was not created with IndexLines.
+//
+// It must not be used while Parse is running.
+func (p *Parser) PositionIndex() *PositionIndex {
+	return p.index
+}
+
+// ErrStopped is wrapped by the ParseError of a parser which was stopped. It is also returned by ParseFunc when its
+// callback stops it.
+var ErrStopped = errors.New("parser stopped")
+
+// ErrConsumerStalled is wrapped by the ParseError of a parser created with SendTimeout whose events were not
+// received in time.
+var ErrConsumerStalled = errors.New("consumer stalled")
+
+// ErrTimeout is wrapped by the ParseError of a parser created with Timeout which didn't finish in time.
+var ErrTimeout = errors.New("parse timeout exceeded")
+
+// ErrFailed is wrapped by the error emitted when Parse is called on a parser which already failed.
+var ErrFailed = errors.New("parser is in a failed state")
+
+// Stop makes Parse return as soon as possible, without emitting any more events, even if nothing reads them.
+// It must be called when the consumer of the events gives up before the end of the input, to release the
+// goroutine running Parse.
+//
+// Stop may be called concurrently with Parse, and more than once. Once stopped, a parser doesn't read its input
+// anymore and its error wraps ErrStopped: a later call to Parse returns immediately.
+func (p *Parser) Stop() {
+	p.stop(ErrStopped)
+}
+
+// stop stops the parser like Stop, its error wrapping cause.
+func (p *Parser) stop(cause error) {
+	p.stopOnce.Do(func() {
+		p.stopCause = cause
+		atomic.StoreInt32(&p.stopped, 1)
+		close(p.done)
+	})
+}
+
+// timedOut reports whether the parser was stopped by its Timeout.
+func (p *Parser) timedOut() bool {
+	return atomic.LoadInt32(&p.stopped) != 0 && p.stopCause == ErrTimeout
+}
+
+// disarm stops the timer of the Timeout, which is armed again by the next read.
+func (p *Parser) disarm() {
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+}
+
+// eof is returned by readByte when the input can't be read anymore. It is distinct from every byte value.
+const eof = -1
+
+// ErrUnexpectedEOF is wrapped by the ParseError emitted when the input ends in the middle of a document.
+//
+// It also matches io.ErrUnexpectedEOF with errors.Is.
+var ErrUnexpectedEOF error = unexpectedEOFError{}
+
+type unexpectedEOFError struct{}
+
+func (unexpectedEOFError) Error() string {
+	return "unexpected end of file"
+}
+
+func (unexpectedEOFError) Is(target error) bool {
+	return target == io.ErrUnexpectedEOF
+}
+
+// The following errors are wrapped by the ParseError emitted for malformed input, depending on what is wrong with
+// it, so that the category of an error can be checked with errors.Is.
+var (
+	// ErrInvalidCharacter is wrapped when a character can't appear where it is, outside of a string or a number.
+	ErrInvalidCharacter = errors.New("invalid character")
+	// ErrInvalidNumber is wrapped when a number is malformed.
+	ErrInvalidNumber = errors.New("invalid number")
+	// ErrInvalidString is wrapped when a string contains an invalid escape sequence, control character or UTF-8
+	// sequence.
+	ErrInvalidString = errors.New("invalid string")
+	// ErrDuplicateKey is wrapped when an object has the same key twice, with DisallowDuplicateKeys.
+	ErrDuplicateKey = errors.New("duplicate key")
+)
+
+// malformed tells if err is one of the errors wrapped by the ParseError of malformed input.
+func malformed(err error) bool {
+	switch err {
+	case ErrInvalidCharacter, ErrInvalidNumber, ErrInvalidString, ErrDuplicateKey:
+		return true
+	}
+	return false
+}
+
+// Parse starts parsing data from the input stream and emit events.
+//
+// This method parses data until the input stream is empty. Whitespace is allowed around documents, and input
+// holding no document at all is not an error unless the parser was created with RequireDocument.
+//
+// The last event emitted is always an EOFEvent, once: its Error is nil if the input was exhausted cleanly, after
+// the last document, and the ParseError otherwise.
+//
+// Parse may be called again once it returned, to parse what was added to the input since, like a file being
+// written to. After a failure though, the parser only emits an EOFEvent with an error wrapping ErrFailed.
+//
+// Parse returns nil once the input is exhausted cleanly, and the ParseError of the EOFEvent otherwise, or ErrStopped
+// if the parser is stopped. It is a loop over Next, sending the events on ch. The events of a parser created with
+// ByteStrings are sent as copies, see Event.Copy.
+func (p *Parser) Parse(ch chan Event) error {
+	return p.send(ch, p.ParseFunc)
+}
+
+// send runs parse, a loop like ParseFunc, sending the events on ch for Parse, until the parser is stopped. With
+// SendTimeout, it stops the parser and returns its ParseError if an event is not received in time, and with Timeout,
+// the ParseError of the parser which timed out.
+func (p *Parser) send(ch chan Event, parse func(fn func(ev Event) bool) error) error {
+	var timer *time.Timer
+	if p.opts.sendTimeout > 0 {
+		timer = time.NewTimer(p.opts.sendTimeout)
+		defer timer.Stop()
+	}
+
+	stalled := false
+	var final Event
+	stopped := func(ev Event) bool {
+		if ev.Type == EOFEvent {
+			final = ev
+		}
+		return false
+	}
+	err := parse(func(ev Event) bool {
+		select {
+		case <-p.done:
+			return stopped(ev)
+		default:
+		}
+
+		if timer == nil {
+			select {
+			case ch <- ev.Copy():
+				return true
+			case <-p.done:
+				return stopped(ev)
+			}
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(p.opts.sendTimeout)
+		select {
+		case ch <- ev.Copy():
+			return true
+		case <-p.done:
+			return stopped(ev)
+		case <-timer.C:
+			stalled = true
+			return false
+		}
+	})
+	switch {
+	case stalled:
+		ev, _ := p.cancel(ErrConsumerStalled)
+		return ev.Error
+	case err == ErrStopped && p.timedOut():
+		// Like ParseContext, the final EOFEvent is only sent if the consumer is ready to receive it.
+		if final.Type != EOFEvent {
+			final, _ = p.cancel(ErrTimeout)
+		}
+		select {
+		case ch <- final:
+		default:
+		}
+		return final.Error
+	}
+
+	return err
+}
+
+// ParseOne is like Parse, returning once the events of the next document were sent on ch, without an EOFEvent.
+// The input following the document is not read: Buffered, followed by the rest of the underlying reader, gives it,
+// and InputOffset locates it.
+//
+// ParseOne returns io.EOF, after sending the EOFEvent, if the input holds no other document. It returns the
+// ParseError of the EOFEvent if the document is not valid.
+func (p *Parser) ParseOne(ch chan Event) error {
+	return p.send(ch, p.parseOne)
+}
+
+// parseOne is the loop of ParseOne, calling fn with every event like ParseFunc.
+func (p *Parser) parseOne(fn func(ev Event) bool) error {
+	if p.exhausted {
+		p.first = true
+	}
+
+	for {
+		ev, err := p.Next()
+		if !fn(ev) {
+			return ErrStopped
+		}
+
+		switch {
+		case err != nil:
+			return err
+		case p.betweenDocuments():
+			return nil
+		}
+	}
+}
+
+// betweenDocuments reports whether the events returned end a document.
+func (p *Parser) betweenDocuments() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.stack) == 0 && p.head == len(p.queue)
+}
+
+// InputOffset returns the offset of the input following the last byte parsed, like json.Decoder.InputOffset.
+func (p *Parser) InputOffset() int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.offset
+}
+
+// ParseFunc is like Parse, calling fn synchronously with every event instead of sending it on a channel,
+// the EOFEvent included.
+//
+// It returns nil once the input is exhausted cleanly, and the ParseError of the EOFEvent otherwise. If fn returns
+// false, ParseFunc returns ErrStopped right away, and a later call to ParseFunc or Next goes on from the next event.
+func (p *Parser) ParseFunc(fn func(ev Event) bool) error {
+	if p.exhausted {
+		p.first = true
+	}
+
+	for {
+		ev, err := p.Next()
+		if !fn(ev) {
+			return ErrStopped
+		}
+
+		switch err {
+		case nil:
+		case io.EOF:
+			return nil
+		default:
+			return err
+		}
+	}
+}
+
+// ParseContext is like Parse, and stops when ctx is done, like Stop, with an error wrapping ctx.Err() instead of
+// ErrStopped. A read blocked on the underlying reader can't be interrupted though: the parser stops once it returns.
+//
+// The final EOFEvent holding the error of a canceled parse is only sent if the consumer is ready to receive it, so
+// that ParseContext returns even if nothing reads the events anymore.
+func (p *Parser) ParseContext(ctx context.Context, ch chan Event) error {
+	finished := make(chan struct{})
+	defer close(finished)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			p.stop(ctx.Err())
+		case <-finished:
+		}
+	}()
+
+	var final Event
+	err := p.ParseFunc(func(ev Event) bool {
+		if ctx.Err() == nil {
+			select {
+			case ch <- ev.Copy():
+				return true
+			case <-ctx.Done():
+			case <-p.done:
+			}
+		}
+
+		if ev.Type == EOFEvent {
+			final = ev
+		}
+		return false
+	})
+	cause := ctx.Err()
+	if cause == nil && p.timedOut() {
+		cause = ErrTimeout
+	}
+	if err != ErrStopped || cause == nil {
+		return err
+	}
+
+	if final.Type != EOFEvent {
+		final, _ = p.cancel(cause)
+	}
+
+	select {
+	case ch <- final:
+	default:
+	}
+
+	return final.Error
+}
+
+// NextContext is like Next, and stops the parser when ctx is done, like ParseContext. Once ctx is done, it returns
+// the error right away, even if events were already parsed. It watches ctx from another goroutine, which is started
+// for every call.
+func (p *Parser) NextContext(ctx context.Context) (Event, error) {
+	if err := ctx.Err(); err != nil {
+		return p.cancel(err)
+	}
+
+	finished := make(chan struct{})
+	defer close(finished)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			p.stop(ctx.Err())
+		case <-finished:
+		}
+	}()
+
+	return p.Next()
+}
+
+// cancel stops the parser with cause, and makes it fail right away. The events which were parsed but not returned
+// are dropped. It returns the EOFEvent holding the error.
+func (p *Parser) cancel(cause error) (Event, error) {
+	p.stop(cause)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.queue, p.head = p.queue[:0], 0
+	if p.failure == nil {
+		p.err = cause
+		p.serrEOF()
+	}
+
+	ev, err := p.peekEvent()
+	p.seq++
+	if p.head < len(p.queue) {
+		p.head++
+	}
+
+	return ev, err
+}
+
+// Next parses the input up to the next event and returns it.
+//
+// It returns io.EOF once the input is exhausted cleanly, along with an EOFEvent. The input may be read again by a
+// later call, like Parse. If the input is not valid, Next returns an EOFEvent holding the ParseError, and the
+// error itself: the parser then failed and every later call returns an error wrapping ErrFailed.
+// Recoverable errors, reported by ErrorEvent and SkippedEvent, are returned as events with a nil error.
+func (p *Parser) Next() (Event, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ev, err := p.peekEvent()
+	p.seq++
+	if p.head < len(p.queue) {
+		p.consumed.update(ev)
+		p.head++
+	}
+
+	return ev, err
+}
+
+// ErrNoValue is returned by SkipValue when the next event doesn't start a value.
+var ErrNoValue = errors.New("no value to skip")
+
+// SkipValue discards the value which starts with the next event, so that Next returns the event following it.
+//
+// The part of the value which was not parsed yet is scanned without being parsed: only its brackets and its
+// strings are checked, and SkipValue returns the ParseError of the first error found, which Next returns too.
+// The errors recovered from in the part which was already parsed are still returned by Next, as ErrorEvent.
+//
+// It returns ErrNoValue if the next event ends a container or is an ObjectKeyEvent or a key, and io.EOF once the
+// input is exhausted, like Next.
+func (p *Parser) SkipValue() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.failure == nil {
+		p.skipping, p.skipDepth, p.skipped = true, 0, false
+
+		// The value may have been parsed already, in part.
+		rest := p.queue[p.head:]
+		kept := rest[:0]
+		for i, ev := range rest {
+			if !p.skipping {
+				kept = append(kept, rest[i:]...)
+				break
+			}
+
+			if p.skipEvent(ev, ev.Type == StringEvent && p.consumed.key) {
+				kept = append(kept, ev)
+			} else {
+				p.consumed.update(ev)
+			}
+		}
+		p.queue = p.queue[:p.head+len(kept)]
+
+		for p.skipping && p.getError() == nil {
+			// The innermost containers belong to the value once it started.
+			if p.skipDepth > 0 && !p.opts.extendedSyntax() {
+				p.skipContainer()
+			} else if !p.advance() {
+				break
+			}
+		}
+		p.skipping = false
+
+		if p.skipped {
+			return nil
+		}
+	}
+
+	if _, err := p.peekEvent(); err != nil {
+		return err
+	}
+
+	return ErrNoValue
+}
+
+// skipContainer reads the input up to the closing bracket of the innermost container, which is discarded by
+// SkipValue, and closes it. The input is scanned by chunks, and only its brackets and strings are checked.
+func (p *Parser) skipContainer() bool {
+	closer := byte('}')
+	if top := p.stack[len(p.stack)-1]; top == arrayStart || top == arrayNext {
+		closer = ']'
+	}
+	limit := 0
+	if p.opts.maxDepth > 0 {
+		limit = p.opts.maxDepth - len(p.stack) + 1
+	}
+	p.skipper.reset(closer, limit)
+
+	for {
+		// The byte which was unread and the reads of a stopped parser go through readByte.
+		if p.unread || atomic.LoadInt32(&p.stopped) != 0 {
+			r := p.readByte()
+			if r == eof {
+				p.serrEOF()
+				return false
+			}
+			if b := [1]byte{byte(r)}; p.skipper.scan(b[:]) == 0 {
+				return p.endSkip(r)
+			}
+			continue
+		}
+
+		b := p.available()
+		if len(b) == 0 {
+			if r := p.readByte(); r != eof {
+				p.unreadByte()
+				continue
+			}
+			p.serrEOF()
+			return false
+		}
+
+		i := p.skipper.scan(b)
+		if i < 0 {
+			p.skipBytes(b)
+			continue
+		}
+		p.skipBytes(b[:i])
+
+		return p.endSkip(p.readByte())
+	}
+}
+
+// endSkip closes the container skipped by skipContainer if r, the last byte read, is its closing bracket.
+// Otherwise it fails with the error found by the scanner about r.
+func (p *Parser) endSkip(r int) bool {
+	closers := p.skipper.closers
+	switch {
+	case len(closers) == 0 && r == '}':
+		p.closeContainer(ObjectEndEvent)
+		return true
+	case len(closers) == 0:
+		p.closeContainer(ArrayEndEvent)
+		return true
+	case r == '{' || r == '[':
+		p.serrLimit(MaxDepthLimit, int64(p.opts.maxDepth))
+	case r == '}' || r == ']':
+		p.serr(ErrInvalidCharacter, "expected %c but got %s", closers[len(closers)-1], charString(r))
+	default:
+		p.serr(ErrInvalidString, "invalid control character %s in string", charString(r))
+	}
+
+	return false
+}
+
+// available returns the bytes of the input which can be read without blocking, reading more if there are none.
+func (p *Parser) available() []byte {
+	if p.inMemory {
+		return p.data[p.pos:]
+	}
+
+	if p.br.Buffered() == 0 {
+		p.br.Peek(1)
+	}
+	b, _ := p.br.Peek(p.br.Buffered())
+
+	return b
+}
+
+// skipBytes reads b, the next bytes of the input, like as many calls to readByte.
+func (p *Parser) skipBytes(b []byte) {
+	if len(b) == 0 {
+		return
+	}
+
+	for i, c := range b {
+		if p.columns.next(c) {
+			p.column++
+		}
+		if c == '\n' {
+			p.line++
+			p.prevLineStart = p.lineStart
+			p.lineStart = p.offset + int64(i) + 1
+			p.prevColumn = p.column
+			p.column = 0
+			if p.index != nil {
+				p.index.lineStarts = append(p.index.lineStarts, p.lineStart)
+			}
+		}
+	}
+	if p.capturing {
+		p.raw = append(p.raw, b...)
+	}
+	if p.opts.tee != nil {
+		p.teed = append(p.teed, b...)
+	}
+	p.offset += int64(len(b))
+	p.last = b[len(b)-1]
+	if p.offset >= p.nextProgress {
+		p.reportProgress()
+	}
+
+	if p.inMemory {
+		p.pos += len(b)
+	} else {
+		p.br.Discard(len(b))
+	}
+}
+
+// skipScanner finds the end of a container skipped by SkipValue in the chunks of the input following its start,
+// only following its brackets and strings.
+type skipScanner struct {
+	// closers are the closing brackets expected, the innermost last.
+	closers  []byte
+	limit    int
+	inString bool
+	escaped  bool
+}
+
+// reset makes s scan a container closed by closer, which may hold up to limit levels of nested containers
+// if limit is positive.
+func (s *skipScanner) reset(closer byte, limit int) {
+	s.closers = append(s.closers[:0], closer)
+	s.limit = limit
+	s.inString, s.escaped = false, false
+}
+
+// scan returns the index of the byte of b closing the container, or of the first invalid byte, and -1 if b holds
+// neither.
+func (s *skipScanner) scan(b []byte) int {
+	for i, c := range b {
+		if s.inString {
+			switch {
+			case c < ' ':
+				return i
+			case s.escaped:
+				s.escaped = false
+			case c == '\\':
+				s.escaped = true
+			case c == '"':
+				s.inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			s.inString = true
+		case '{', '[':
+			if s.limit > 0 && len(s.closers) >= s.limit {
+				return i
+			}
+			// '}' and ']' follow '{' and '[' by 2 in ASCII.
+			s.closers = append(s.closers, c+2)
+		case '}', ']':
+			if c != s.closers[len(s.closers)-1] {
+				return i
+			}
+			s.closers = s.closers[:len(s.closers)-1]
+			if len(s.closers) == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// skipEvent follows the value discarded by SkipValue with ev, the next event, which is a key if key is set.
+// It reports whether ev must be kept, which is the case of errors and of the events following the value.
+func (p *Parser) skipEvent(ev Event, key bool) bool {
+	switch ev.Type {
+	case ErrorEvent:
+		return true
+	case EOFEvent:
+		p.skipping = false
+		return true
+	case ObjectStartEvent, ArrayStartEvent:
+		p.skipDepth++
+		return false
+	case ObjectEndEvent, ArrayEndEvent:
+		if p.skipDepth == 0 {
+			p.skipping = false
+			return true
+		}
+		p.skipDepth--
+	case StringEvent, NumberEvent, BooleanEvent, NullEvent, SkippedEvent:
+		if key && p.skipDepth == 0 {
+			p.skipping = false
+			return true
+		}
+	default:
+		if p.skipDepth == 0 {
+			p.skipping = false
+			return true
+		}
+		return false
+	}
+
+	if p.skipDepth == 0 {
+		p.skipping, p.skipped = false, true
+	}
+
+	return false
+}
+
+// Path returns the JSON pointer of the value started by the last event returned by Next, or of the container
+// ended by it, like "/items/3/name". From the ObjectKeyEvent of a member on, it is the pointer of the object until
+// the key is read, and then the pointer of the member.
+//
+// Path is meant to be called by the consumer of Next or the callback of ParseFunc: the parser runs ahead of the
+// events sent by Parse.
+func (p *Parser) Path() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.consumed.key {
+		return p.consumed.containerPointer()
+	}
+
+	return p.consumed.pointer(false)
+}
+
+// ArrayIndex returns the index of the element of the innermost array enclosing the last event returned by Next:
+// the element is the value started by the event, or the container ended by it, or the value holding it.
+// Within the array of [{"a": 1}, [true]], the events of {"a": 1} are at index 0, and the start and end events of
+// [true] at index 1, while its element true is at index 0 of the nested array. It returns false if the event is not
+// in an array, like the events of the top-level array itself.
+//
+// Like Path, it is meant to be called by the consumer of Next or the callback of ParseFunc.
+func (p *Parser) ArrayIndex() (int, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.consumed.arrayIndex()
+}
+
+// Peek is like Next, without consuming the event: the next call to Peek or Next returns it again.
+func (p *Parser) Peek() (Event, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.peekEvent()
+}
+
+func (p *Parser) peekEvent() (Event, error) {
+	if p.head == len(p.queue) {
+		p.head = 0
+		p.queue = p.queue[:0]
+		p.strs = p.strs[:0]
+	}
+
+	if p.opts.timeout > 0 && p.timer == nil {
+		p.timer = time.AfterFunc(p.opts.timeout, func() { p.stop(ErrTimeout) })
+	}
+
+	for p.head == len(p.queue) || p.holding() {
+		if p.failure != nil {
+			err := p.parseError(p.offset, p.column+1, ErrFailed.Error(), ErrFailed)
+			ev := Event{Type: EOFEvent, Error: err}
+			if p.opts.positions {
+				p.setPosition(&ev)
+			}
+			return p.numbered(ev), err
+		}
+
+		if p.getError() == nil && p.advance() {
+			continue
+		}
+
+		err := p.getError()
+		switch {
+		case p.followed(err):
+			// The partial document is dropped.
+			p.queue = p.queue[:p.head]
+			p.exhausted = true
+			p.disarm()
+			return p.numbered(Event{Type: EOFEvent}), io.EOF
+		case err != nil:
+			p.setFailure(err)
+			p.disarm()
+			p.emitEvent(EOFEvent, nil, err)
+		case p.head == len(p.queue):
+			p.exhausted = true
+			p.disarm()
+			return p.numbered(Event{Type: EOFEvent}), io.EOF
+		}
+	}
+
+	ev := p.numbered(p.queue[p.head])
+	if ev.Type == EOFEvent {
+		return ev, ev.Error
+	}
+
+	return ev, nil
+}
+
+// numbered returns ev with the sequence number of the next event returned, with SequenceNumbers.
+func (p *Parser) numbered(ev Event) Event {
+	if p.opts.seq {
+		ev.Seq = p.seq
+	}
+	return ev
+}
+
+// More reports whether another event follows: an event parsed but not returned yet, the rest of the current
+// document, or another document. Between documents, it skips whitespace and reads the first byte of the next
+// document, without consuming it. The end of the input makes it return false without failing, while an error of
+// the reader makes it return true, so that the error is returned by Next.
+//
+// More may be called while Parse runs, typically once the end of a document was received.
+func (p *Parser) More() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch {
+	case p.head < len(p.queue) || len(p.stack) > 0:
+		return true
+	case p.failure != nil:
+		return false
+	}
+
+	if p.offset == 0 && len(p.opts.xssiPrefixes) > 0 {
+		p.skipXSSIPrefix()
+	}
+
+	if r := p.readIgnoreWS(); r != eof {
+		p.unreadByte()
+		return true
+	}
+
+	if p.err != io.EOF {
+		p.serrEOF()
+		return true
+	}
+
+	return false
+}
+
+// advance parses the input up to the next state, queueing the events read on the way. It returns false once the
+// input is exhausted or on error.
+func (p *Parser) advance() bool {
+	if len(p.stack) > 0 {
+		return p.step()
+	}
+
+	if p.offset == 0 && len(p.opts.xssiPrefixes) > 0 {
+		p.skipXSSIPrefix()
+	}
+
+	// EOF is valid here because we read either a full object or a full array
+	// and we need to allow parsing fixed-size data, which may also be empty
+	r := p.readIgnoreWS()
+	if r == eof {
+		if p.err != io.EOF || (p.first && p.opts.requireDocument) {
+			p.serrEOF()
+		}
+		return false
+	}
+
+	if !p.first && p.opts.singleDocument {
+		p.serr(ErrInvalidCharacter, "unexpected data after top-level value")
+		return false
+	}
+	p.unreadByte()
+	p.exhausted = false
+
+	if !p.first {
+		p.document++
+	}
+	p.first = false
+	p.startDocument()
+
+	return p.startValue()
+}
+
+// Buffered returns a reader over the input which was read from the underlying reader but not parsed yet, like
+// json.Decoder.Buffered. Once a document was returned by Next, followed by the rest of the underlying reader, it
+// gives the input following the document. More skips the whitespace following a document though.
+//
+// The reader is only valid until the parser reads its input again.
+func (p *Parser) Buffered() io.Reader {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// The bytes buffered by a reader given to NewParser are still in it.
+	var rest []byte
+	switch {
+	case p.inMemory:
+		rest = p.data[p.pos:]
+	case p.br == p.own:
+		rest, _ = p.own.Peek(p.own.Buffered())
+	default:
+		if b, ok := p.br.(*byteReaderInput); ok {
+			rest = b.ahead[b.head:]
+		}
+	}
+
+	if !p.unread {
+		return bytes.NewReader(rest)
+	}
+
+	// The last byte was read from the input, but not parsed.
+	return io.MultiReader(bytes.NewReader([]byte{p.last}), bytes.NewReader(rest))
+}
+
+// Err returns the error which made the parser fail, which is the error of its last EOFEvent. It returns nil
+// before the parser is used, while it runs and once it reached the end of its input cleanly.
+//
+// Err may be called concurrently with Parse.
+func (p *Parser) Err() error {
+	p.failureMu.Lock()
+	defer p.failureMu.Unlock()
+
+	return p.failure
+}
+
+func (p *Parser) setFailure(err error) {
+	p.failureMu.Lock()
+	p.failure = err
+	p.failureMu.Unlock()
+}
+
+// ParseAndClose is like Parse, and closes ch after the EOFEvent is emitted, so that the events can be read with
+// a range loop. Use Parse to keep ch open, for example to send the events of several parsers on it.
+func (p *Parser) ParseAndClose(ch chan Event) error {
+	defer close(ch)
+	return p.Parse(ch)
+}
+
+// skipXSSIPrefix skips the longest prefix configured with StripXSSIPrefix which starts the input,
+// and the whitespace following it.
+func (p *Parser) skipXSSIPrefix() {
+	var prefix string
+	for _, s := range p.opts.xssiPrefixes {
+		if len(s) <= len(prefix) {
+			continue
+		}
+
+		if string(p.peek(len(s))) == s {
+			prefix = s
+		}
+	}
+
+	if prefix == "" {
+		return
+	}
+
+	for i := 0; i < len(prefix); i++ {
+		p.readByte()
+	}
+
+	if r := p.readIgnoreWS(); r != eof {
+		p.unreadByte()
+	}
+}
+
+// startValue starts reading a document, which must be an object or an array unless the parser is strict.
+func (p *Parser) startValue() bool {
+	p.stack, p.counts, p.starts = p.stack[:0], p.counts[:0], p.starts[:0]
+	p.keys = p.keys[:0]
+
+	switch r := p.readByte(); {
+	case r == eof:
+		p.serrEOF()
+		return false
+	case p.opts.strict || p.opts.json5:
+		// RFC 8259 and JSON5 allow any value at the top level.
+		p.emitDocumentStart()
+		p.unreadByte()
+		if !p.readValue() {
+			return false
+		}
+		if len(p.stack) == 0 {
+			p.emitDocumentEnd()
+		}
+		return true
+	case r == '{':
+		p.emitDocumentStart()
+		return p.openContainer(objectStart, ObjectStartEvent)
+	case r == '[':
+		p.emitDocumentStart()
+		return p.openContainer(arrayStart, ArrayStartEvent)
+	default:
+		p.serr(ErrInvalidCharacter, "unexpected character %s", p.charString(r))
+		return false
+	}
+}
+
+// emitDocumentStart emits the DocumentStartEvent of the document whose first byte was just read, with
+// DocumentEvents.
+func (p *Parser) emitDocumentStart() {
+	if p.opts.documentEvents {
+		p.emit(Event{Type: DocumentStartEvent, Document: p.document, Line: p.line, Column: p.lastColumn(), Offset: p.offset - 1})
+	}
+}
+
+// emitDocumentEnd emits the DocumentEndEvent of the document whose last byte was just read, with DocumentEvents.
+// It writes the document to the writer given to Tee.
+func (p *Parser) emitDocumentEnd() {
+	if err := p.flushTee(); err != nil {
+		p.serr2At(p.offset, p.column+1, err)
+	}
+	if p.opts.documentEvents {
+		p.emit(Event{Type: DocumentEndEvent, Document: p.document, Line: p.line, Column: p.column + 1, Offset: p.offset})
+	}
+}
+
+// A parseState is what the parser expects next in an object or an array.
+type parseState int
+
+const (
+	// objectStart and arrayStart follow the opening bracket: the container may be empty.
+	objectStart parseState = iota
+	arrayStart
+	// objectKey follows a comma in an object, or its opening bracket once it is known not to be empty. It lasts
+	// until the value of the member starts.
+	objectKey
+	// objectNext and arrayNext follow a value: either a comma or the closing bracket is expected.
+	objectNext
+	arrayNext
+)
+
+// step parses the input up to the next state of the innermost container.
+//
+// Nested containers are pushed on the stack instead of being parsed recursively, so that the nesting of
+// the input is only bounded by MaxDepth.
+func (p *Parser) step() bool {
+	top := &p.stack[len(p.stack)-1]
+
+	switch *top {
+	case objectStart:
+		r := p.readIgnoreWS()
+		switch r {
+		case eof:
+			p.serrEOF()
+			return false
+		case '}':
+			p.closeContainer(ObjectEndEvent)
+			return true
+		}
+		p.unreadByte()
+
+		*top = objectKey
+
+		return true
+	case arrayStart:
+		r := p.readIgnoreWS()
+		switch r {
+		case eof:
+			p.serrEOF()
+			return false
+		case ']':
+			p.closeContainer(ArrayEndEvent)
+			return true
+		}
+		p.unreadByte()
+
+		*top = arrayNext
+		p.counts[len(p.counts)-1]++
+
+		return p.readValue()
+	case objectKey:
+		p.counts[len(p.counts)-1]++
+		key := len(p.queue)
+		p.emitEvent(ObjectKeyEvent, nil, nil)
+
+		if !p.checkKeyStart() {
+			return false
+		}
+
+		if p.opts.disallowDuplicateKeys {
+			if !p.readKey() {
+				return false
+			}
+		} else if !p.readString() {
+			return false
+		}
+		if p.opts.positions {
+			// The key is known to start at its quote, and to end after it, once it is read.
+			p.setPosition(&p.queue[key])
+		}
+
+		switch r := p.readIgnoreWS(); r {
+		case ':':
+		case eof:
+			p.serrEOFExpecting("':'")
+			return false
+		default:
+			p.serr(ErrInvalidCharacter, "expected : but got %s", p.charString(r))
+			return false
+		}
+
+		p.mark()
+		p.emitEvent(ObjectValueEvent, nil, nil)
+
+		*top = objectNext
+
+		return p.readValue()
+	case objectNext:
+		r := p.readIgnoreWS()
+		switch r {
+		case eof:
+			p.serrEOFExpecting("',' or '}'")
+			return false
+		case '}':
+			p.closeContainer(ObjectEndEvent)
+			return true
+		case ',':
+			closed, ok := p.trailingComma('}')
+			switch {
+			case !ok:
+				return false
+			case closed:
+				p.closeContainer(ObjectEndEvent)
+				return true
+			}
+			*top = objectKey
+			return true
+		default:
+			p.serr(ErrInvalidCharacter, "expected , but got %s", p.charString(r))
+			if !p.isQuote(r) || !p.recoverError() {
+				return false
+			}
+			// a missing comma
+			p.unreadByte()
+			*top = objectKey
+			return true
+		}
+	default:
+		r := p.readIgnoreWS()
+		switch r {
+		case eof:
+			p.serrEOFExpecting("',' or ']'")
+			return false
+		case ']':
+			p.closeContainer(ArrayEndEvent)
+			return true
+		case ',':
+			closed, ok := p.trailingComma(']')
+			switch {
+			case !ok:
+				return false
+			case closed:
+				p.closeContainer(ArrayEndEvent)
+				return true
+			}
+			p.counts[len(p.counts)-1]++
+			return p.readValue()
+		default:
+			p.serr(ErrInvalidCharacter, "expected , but got %s", p.charString(r))
+			if !startsValue(r) && !p.isQuote(r) || !p.recoverError() {
+				return false
+			}
+			// a missing comma
+			p.unreadByte()
+			p.counts[len(p.counts)-1]++
+			return p.readValue()
+		}
+	}
+}
+
+// startsValue reports whether b may be the first byte of a value.
+func startsValue(b int) bool {
+	switch b {
+	case '"', '{', '[', 't', 'f', 'n', '-':
+		return true
+	default:
+		return b != eof && isDigit(byte(b))
+	}
+}
+
+// trailingComma reads the closing bracket end if it follows the comma just read. It reports whether it did, and
+// whether parsing goes on: the comma is accepted with AllowTrailingCommas, and reported with an ErrorEvent if the
+// parser collects errors. Otherwise it is an error.
+func (p *Parser) trailingComma(end int) (closed, ok bool) {
+	switch r := p.readIgnoreWS(); {
+	case r == eof:
+		// The end of the input is reported when the next value is read, unless it ended in a comment.
+		_, failed := p.err.(ParseError)
+		return false, !failed
+	case r != end:
+		p.unreadByte()
+		return false, true
+	}
+
+	if p.opts.allowTrailingCommas {
+		return true, true
+	}
+	p.serr(ErrInvalidCharacter, "trailing comma before %c", end)
+
+	return true, p.recoverError()
+}
+
+// checkKeyStart fails with a dedicated message if the next value is not a string, while it must be a key.
+func (p *Parser) checkKeyStart() bool {
+	r := p.readIgnoreWS()
+
+	var kind string
+	switch {
+	case p.opts.unquotedKeys && isIdentifierStart(r):
+		p.unreadByte()
+		return true
+	case r == '-' || r >= '0' && r <= '9':
+		kind = "number"
+	case p.peekLiteral(r, "true") || p.peekLiteral(r, "false"):
+		kind = "boolean"
+	case p.peekLiteral(r, "null"):
+		kind = "null"
+	case r == '{':
+		kind = "object"
+	case r == '[':
+		kind = "array"
+	case isIdentifierStart(r):
+		kind = "unquoted key"
+	default:
+		if r != eof {
+			p.unreadByte()
+		}
+		return true
+	}
+
+	p.serr(ErrInvalidCharacter, "object keys must be strings, got %s", kind)
+
+	return false
+}
+
+// isIdentifierStart reports whether b may start an unquoted key, and isIdentifier whether it may be part of it.
+func isIdentifierStart(b int) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b == '_' || b == '$'
+}
+
+func isIdentifier(b int) bool {
+	return isIdentifierStart(b) || b >= '0' && b <= '9'
+}
+
+// peekLiteral reports whether r, the last byte read, and the next bytes of the input spell lit.
+func (p *Parser) peekLiteral(r int, lit string) bool {
+	if r != int(lit[0]) {
+		return false
+	}
+
+	return string(p.peek(len(lit)-1)) == lit[1:]
+}
+
+// openContainer emits typ and pushes state for the container whose opening bracket was just read.
+func (p *Parser) openContainer(state parseState, typ EventType) bool {
+	p.mark()
+	if p.opts.maxDepth > 0 && len(p.stack) >= p.opts.maxDepth {
+		p.serrLimit(MaxDepthLimit, int64(p.opts.maxDepth))
+		return false
+	}
+
+	p.emitEvent(typ, nil, nil)
+	p.stack = append(p.stack, state)
+	p.counts = append(p.counts, 0)
+	if p.opts.positions {
+		p.starts = append(p.starts, p.markOffset)
+	}
+	if state == objectStart && p.opts.disallowDuplicateKeys {
+		p.keys = append(p.keys, make(map[string]bool))
+	}
+
+	return true
+}
+
+// closeContainer emits typ and pops the innermost container, whose closing bracket was just read.
+func (p *Parser) closeContainer(typ EventType) {
+	p.mark()
+	count := p.counts[len(p.counts)-1]
+	p.stack, p.counts = p.stack[:len(p.stack)-1], p.counts[:len(p.counts)-1]
+	if typ == ObjectEndEvent && p.opts.disallowDuplicateKeys {
+		p.keys = p.keys[:len(p.keys)-1]
+	}
+	ev := Event{Type: typ, Count: count}
+	if p.opts.positions {
+		ev.StartOffset = p.starts[len(p.starts)-1]
+		p.starts = p.starts[:len(p.starts)-1]
+	}
+	p.emit(ev)
+	if len(p.stack) == 0 {
+		p.emitDocumentEnd()
+	}
+}
+
+func (p *Parser) getError() error {
+	if p.err == io.EOF {
+		return nil
+	}
+
+	return p.err
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+func (p *Parser) readValue() bool {
+	if !p.opts.skipInvalidValues || len(p.stack) == 0 {
+		return p.parseValue()
+	}
+
+	p.raw = p.raw[:0]
+	p.capturing = true
+	ok := p.parseValue()
+	p.capturing = false
+
+	return ok || p.skipInvalidValue()
+}
+
+// skipInvalidValue skips the rest of the value which failed to be parsed, up to the comma or the closing bracket
+// following it, and emits a SkippedEvent in place of it. It returns false if the error can't be recovered from,
+// like when the input ends before the value.
+func (p *Parser) skipInvalidValue() bool {
+	err, ok := p.err.(ParseError)
+	if !ok || !malformed(err.Err) && !errors.As(err.Err, new(*strconv.NumError)) {
+		return false
+	}
+	p.err = nil
+
+	var (
+		depth             int
+		inString, escaped bool
+	)
+	// end reports whether b is the comma or the closing bracket following the value.
+	end := func(b byte) bool {
+		switch {
+		case inString:
+			switch {
+			case escaped:
+				escaped = false
+			case b == '\\':
+				escaped = true
+			case b == '"':
+				inString = false
+			}
+		case b == '"':
+			inString = true
+		case b == '{' || b == '[':
+			depth++
+		case b == ',' && depth == 0:
+			return true
+		case b == '}' || b == ']':
+			if depth == 0 {
+				return true
+			}
+			depth--
+		}
+		return false
+	}
+
+	// The bytes of the value read so far. Only the last one, on which the value failed, may end it.
+	for i, b := range p.raw {
+		if end(b) {
+			p.unreadByte()
+			p.raw = p.raw[:i]
+			return p.emitSkipped(err)
+		}
+	}
+
+	p.capturing = true
+	defer func() { p.capturing = false }()
+
+	for {
+		r := p.readByte()
+		if r == eof {
+			if p.err == io.EOF {
+				p.err = err
+			} else {
+				p.serrEOF()
+			}
+			return false
+		}
+
+		if end(byte(r)) {
+			p.unreadByte()
+			return p.emitSkipped(err)
+		}
+	}
+}
+
+// emitSkipped emits the SkippedEvent of the bytes captured for the invalid value which failed with err.
+func (p *Parser) emitSkipped(err ParseError) bool {
+	p.emit(Event{Type: SkippedEvent, Value: strings.Trim(string(p.raw), " \t\r\n"), Error: err})
+	return true
+}
+
+// parseValue reads a value. It only reads the start of an object or an array, whose state is pushed instead.
+func (p *Parser) parseValue() bool {
+	p.inValue = true
+
+	r := p.readIgnoreWS()
+	if r == eof {
+		p.serrEOF()
+		return false
+	}
+	p.mark()
+
+	switch {
+	case p.isQuote(r):
+		p.unreadByte()
+
+		if p.opts.byteStrings {
+			return p.readBytes()
+		}
+
+		s, ok := p.scanString()
+		if !ok {
+			return false
+		}
+
+		if p.opts.expandDepth > 0 && p.expandString(s) {
+			return true
+		}
+
+		p.emitString(s)
+
+		return true
+	case r == 't':
+		p.unreadByte()
+		return p.readLiteral("true", trueEvent)
+	case r == 'f':
+		p.unreadByte()
+		return p.readLiteral("false", falseEvent)
+	case r == 'n':
+		p.unreadByte()
+		return p.readLiteral("null", nullEvent)
+	case r == 'T' || r == 'F' || r == 'N' && string(p.peek(1)) == "o":
+		return p.readPythonLiteral(r)
+	case p.opts.nanInf && r == 'N':
+		p.unreadByte()
+		return p.readNonFinite("NaN", math.NaN())
+	case p.opts.nanInf && r == 'I':
+		p.unreadByte()
+		return p.readNonFinite("Infinity", math.Inf(1))
+	case p.opts.nanInf && (r == '-' || r == '+' && p.opts.lenientNumbers) && string(p.peek(1)) == "I":
+		if r == '-' {
+			return p.readNonFinite("Infinity", math.Inf(-1))
+		}
+		return p.readNonFinite("Infinity", math.Inf(1))
+	case p.opts.nanInf && (r == '-' || r == '+' && p.opts.lenientNumbers) && string(p.peek(1)) == "N":
+		return p.readNonFinite("NaN", math.NaN())
+	case r == '-' || r == '+' || isDigit(byte(r)) || r == '.' && p.opts.lenientNumbers:
+		p.unreadByte()
+		return p.readNumber()
+	case r == '{':
+		return p.openContainer(objectStart, ObjectStartEvent)
+	case r == '[':
+		return p.openContainer(arrayStart, ArrayStartEvent)
+	case r == '\'':
+		p.serr(ErrInvalidCharacter, "unexpected character ', strings must be double-quoted")
+		return false
+	default:
+		p.serr(ErrInvalidCharacter, "unexpected character %s", p.charString(r))
+		return false
+	}
+}
+
+// isQuote reports whether r starts a string: a double quote, or a single quote with AllowSingleQuotedStrings.
+func (p *Parser) isQuote(r int) bool {
+	return r == '"' || r == '\'' && p.opts.singleQuotedStrings
+}
+
+// The events of the literals, whose values are only boxed once.
+var (
+	trueEvent  = Event{Type: BooleanEvent, Value: true, ValueKind: BoolValue, Bool: true}
+	falseEvent = Event{Type: BooleanEvent, Value: false, ValueKind: BoolValue}
+	nullEvent  = Event{Type: NullEvent, ValueKind: NullValue}
+)
+
+// readLiteral reads the bytes of lit and emits ev, one of the events of the literals.
+func (p *Parser) readLiteral(lit string, ev Event) bool {
+	if !p.scanLiteral(lit) {
+		return false
+	}
+
+	p.emit(ev)
+
+	return true
+}
+
+// readPythonLiteral reads True, False or None, whose first byte r was just read, with PythonLiterals. Otherwise it
+// fails, suggesting the JSON literal if the input holds the Python one.
+func (p *Parser) readPythonLiteral(r int) bool {
+	lit, json, ev := "True", "true", trueEvent
+	switch r {
+	case 'F':
+		lit, json, ev = "False", "false", falseEvent
+	case 'N':
+		lit, json, ev = "None", "null", nullEvent
+	}
+
+	if !p.opts.pythonLiterals {
+		if string(p.peek(len(lit)-1)) == lit[1:] {
+			p.serr(ErrInvalidCharacter, "unexpected character %s, did you mean %s?", p.charString(r), json)
+		} else {
+			p.serr(ErrInvalidCharacter, "unexpected character %s", p.charString(r))
+		}
+		return false
+	}
+
+	p.unreadByte()
+	return p.readLiteral(lit, ev)
+}
+
+// readNonFinite reads lit, one of the literals allowed by AllowNaNInf, and emits a NumberEvent holding f.
+func (p *Parser) readNonFinite(lit string, f float64) bool {
+	if !p.scanLiteral(lit) {
+		return false
+	}
+
+	if p.opts.validate || p.skipping {
+		p.emit(Event{Type: NumberEvent})
+		return true
+	}
+	p.emitFloat(f, false)
+
+	return true
+}
+
+// scanLiteral reads the bytes of lit, failing on the first one which differs.
+func (p *Parser) scanLiteral(lit string) bool {
+	for i := 0; i < len(lit); i++ {
+		r := p.readByte()
+		if r == eof {
+			p.serrEOF()
+			return false
+		}
+
+		if r != int(lit[i]) {
+			p.serr(ErrInvalidCharacter, "unexpected character %s in literal %s", p.charString(r), lit)
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p *Parser) readNumber() bool {
+	p.buf.Reset()
+
+	start, startColumn := p.offset, p.column+1
+	isFloat := false
+loop:
+	for {
+		var r int
+		switch r = p.readByte(); {
+		case r == eof:
+			if p.err != io.EOF {
+				p.serrEOF()
+				return false
+			}
+			// The number may be complete, in which case it is emitted before the end of the input is reported.
+			break loop
+		case r == '.' || r == 'e' || r == 'E':
+			isFloat = true
+		case r != '.' && r != 'e' && r != 'E' && r != '+' && r != '-' && !isDigit(byte(r)):
+			p.unreadByte()
+			break loop
+		}
+
+		p.buf.WriteByte(byte(r))
+	}
+
+	if p.opts.hexNumbers && (p.buf.String() == "0" || p.buf.String() == "-0" || p.buf.String() == "+0" && p.opts.lenientNumbers) {
+		switch r := p.readByte(); {
+		case r == 'x' || r == 'X':
+			return p.readHexNumber(start, startColumn)
+		case r != eof:
+			p.unreadByte()
+		}
+	}
+
+	if !p.opts.lenientNumbers {
+		if i, reason := checkNumber(p.buf.Bytes()); i >= 0 {
+			p.serrAt(start+int64(i), startColumn+i, ErrInvalidNumber, "invalid number: %s", reason)
+			return false
+		}
+	}
+
+	if p.opts.validate || p.skipping {
+		p.emit(Event{Type: NumberEvent, IsInt: !isFloat})
+		return true
+	}
+
+	if isFloat {
+		f, err := strconv.ParseFloat(p.buf.String(), 64)
+		if err != nil {
+			p.serr2At(start, startColumn, err)
+			return false
+		}
+
+		p.emitFloat(f, false)
+
+		return true
+	}
+
+	return p.emitInteger(p.buf.String(), 10, start, startColumn)
+}
+
+// readHexNumber reads the digits of a hexadecimal integer, with AllowHexNumbers, whose 0x prefix was just read,
+// following its sign which is in buf. The number starts at start and startColumn.
+func (p *Parser) readHexNumber(start int64, startColumn int) bool {
+	p.buf.WriteByte('x')
+	digits := p.buf.Len()
+	for {
+		r := p.readByte()
+		if r != eof && isHexDigit(byte(r)) {
+			p.buf.WriteByte(byte(r))
+			continue
+		}
+
+		switch {
+		case r == eof && p.err != io.EOF:
+			p.serrEOF()
+			return false
+		case r == '.':
+			p.serr(ErrInvalidNumber, "invalid number: hexadecimal numbers can't have a fraction")
+			return false
+		case r != eof:
+			p.unreadByte()
+		}
+		break
+	}
+
+	if p.buf.Len() == digits {
+		p.serrAt(p.offset, p.column+1, ErrInvalidNumber, "invalid number: expected hexadecimal digit")
+		return false
+	}
+
+	if p.opts.validate || p.skipping {
+		p.emit(Event{Type: NumberEvent, IsInt: true})
+		return true
+	}
+
+	return p.emitInteger(p.buf.String(), 0, start, startColumn)
+}
+
+// emitInteger emits the NumberEvent of the integer s, in base, which starts at start and startColumn. An integer
+// which doesn't fit in an int64 is converted as configured with IntegerOverflow.
+func (p *Parser) emitInteger(s string, base int, start int64, startColumn int) bool {
+	i, err := strconv.ParseInt(s, base, 64)
+	if err == nil {
+		ev := Event{Type: NumberEvent, ValueKind: IntValue, Int: i, IsInt: true}
+		if !p.opts.typedValues {
+			ev.Value = i
+		}
+		p.emit(ev)
+
+		return true
+	}
+
+	var value interface{}
+	if errors.Is(err, strconv.ErrRange) {
+		if base != 10 {
+			// overflowInt converts decimal integers.
+			n, _ := new(big.Int).SetString(s, base)
+			s = n.String()
+		}
+		value, err = p.overflowInt(s, err)
+	}
+
+	if err != nil {
+		p.serr2At(start, startColumn, err)
+		return false
+	}
+
+	if f, ok := value.(float64); ok {
+		p.emitFloat(f, true)
+		return true
+	}
+
+	p.emit(Event{Type: NumberEvent, Value: value, IsInt: true})
+
+	return true
+}
+
+// overflowInt converts the integer s which doesn't fit in an int64, as configured with IntegerOverflow.
+func (p *Parser) overflowInt(s string, err error) (interface{}, error) {
+	switch p.opts.overflow {
+	case OverflowFloat:
+		return strconv.ParseFloat(s, 64)
+	case OverflowError:
+		return nil, err
+	}
+
+	if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return u, nil
+	}
+
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, err
+	}
+
+	return n, nil
+}
+
+// checkNumber validates b against the number grammar of RFC 8259. If b is not a valid number, it returns the
+// index of the offending byte, which is len(b) if b is incomplete, and the reason. Otherwise it returns -1.
+func checkNumber(b []byte) (int, string) {
+	i := 0
+	digits := func() int {
+		n := 0
+		for ; i < len(b) && isDigit(b[i]); i++ {
+			n++
+		}
+		return n
+	}
+
+	if i < len(b) && b[i] == '-' {
+		i++
+	}
+
+	switch {
+	case i == 0 && len(b) > 0 && b[0] == '+':
+		return 0, "leading +"
+	case i < len(b) && b[i] == '0':
+		i++
+		if i < len(b) && isDigit(b[i]) {
+			return i, "leading zero"
+		}
+	case digits() == 0:
+		return i, "expected digit"
+	}
+
+	if i < len(b) && b[i] == '.' {
+		i++
+		if digits() == 0 {
+			return i, "expected digit after decimal point"
+		}
+	}
+
+	if i < len(b) && (b[i] == 'e' || b[i] == 'E') {
+		i++
+		if i < len(b) && (b[i] == '+' || b[i] == '-') {
+			i++
+		}
+		if digits() == 0 {
+			return i, "expected digit in exponent"
+		}
+	}
+
+	if i < len(b) {
+		return i, "unexpected character " + charString(int(b[i]))
+	}
+
+	return -1, ""
+}
+
+func (p *Parser) readString() bool {
+	if p.opts.byteStrings {
+		return p.readBytes()
+	}
+
+	s, ok := p.scanString()
+	if !ok {
+		return false
+	}
+
+	if p.skipDecoding() {
+		p.emitEvent(StringEvent, nil, nil)
+		return true
+	}
+
+	p.emitString(s)
+
+	return true
+}
+
+// readKey is like readString for the key of an object, failing if the object already has the key.
+func (p *Parser) readKey() bool {
+	if r := p.readIgnoreWS(); r != eof {
+		p.unreadByte()
+	}
+	offset, column := p.offset, p.column+1
+
+	b, input, ok := p.scanBytes()
+	if !ok {
+		return false
+	}
+
+	keys := p.keys[len(p.keys)-1]
+	if keys[string(b)] {
+		p.serrAt(offset, column, ErrDuplicateKey, "duplicate key %q", b)
+		if !p.recoverError() {
+			return false
+		}
+	}
+
+	s := string(b)
+	keys[s] = true
+
+	if p.opts.byteStrings {
+		p.emitBytes(b, input)
+	} else {
+		p.emitString(s)
+	}
+
+	return true
+}
+
+// readBytes reads a string like readString, or the value of parseValue, for a parser created with ByteStrings.
+func (p *Parser) readBytes() bool {
+	b, input, ok := p.scanBytes()
+	if !ok {
+		return false
+	}
+
+	if p.opts.expandDepth > 0 && !p.path.key && p.expandString(string(b)) {
+		return true
+	}
+
+	if p.skipDecoding() {
+		p.emitEvent(StringEvent, nil, nil)
+		return true
+	}
+
+	p.emitBytes(b, input)
+
+	return true
+}
+
+func (p *Parser) scanString() (string, bool) {
+	b, _, ok := p.scanBytes()
+	return string(b), ok
+}
+
+// scanBytes reads a string and returns its decoded bytes, which are only valid until the next string is read.
+// input is true if they are a slice of the input of a parser reading a byte slice, in which case they stay valid.
+func (p *Parser) scanBytes() (b []byte, input bool, ok bool) {
+	p.buf.Reset()
+
+	r := p.readIgnoreWS()
+	if r == eof {
+		p.serrEOF()
+		return nil, false, false
+	}
+
+	switch {
+	case r == '\'' && !p.opts.singleQuotedStrings:
+		p.serr(ErrInvalidCharacter, "expected \" but got ', strings must be double-quoted")
+		return nil, false, false
+	case p.path.key && p.opts.unquotedKeys && isIdentifierStart(r):
+		p.mark()
+		b, ok := p.scanIdentifier(r)
+		return b, false, ok
+	case p.path.key && p.opts.json5 && (r >= utf8.RuneSelf || r == '\\'):
+		p.serr(ErrInvalidCharacter, "unsupported JSON5 unquoted key: only ASCII identifiers are supported")
+		return nil, false, false
+	case !p.isQuote(r):
+		p.serr(ErrInvalidCharacter, "expected \" but got %s", p.charString(r))
+		return nil, false, false
+	}
+	quote := r
+	p.mark()
+
+	start, startColumn := p.offset, p.column+1
+	begin := p.pos
+
+	// cr is set after the carriage return of a line continuation, which may be followed by a line feed.
+	escaped, cr := false, false
+	for {
+		r = p.readByte()
+		if r == eof {
+			p.serrEOF()
+			return nil, false, false
+		}
+
+		continuation := p.opts.lineContinuations && (escaped && r == '\r' || (escaped || cr) && r == '\n')
+		if r < ' ' && !continuation {
+			p.serr(ErrInvalidString, "invalid control character %s in string", charString(r))
+			return nil, false, false
+		}
+		cr = continuation && r == '\r'
+
+		if r == quote && !escaped {
+			break
+		}
+		escaped = r == '\\' && !escaped
+
+		p.buf.WriteByte(byte(r))
+	}
+
+	for removed := 0; ; removed++ {
+		i, msg := checkEscapes(p.buf.Bytes(), &p.opts)
+		if i < 0 {
+			break
+		}
+
+		p.serrInString(start, startColumn, i, removed, ErrInvalidString, "%s", msg)
+		if !p.recoverError() {
+			return nil, false, false
+		}
+
+		// Drop the backslash, so that the characters of the escape are kept as they are.
+		b := p.buf.Bytes()
+		copy(b[i:], b[i+1:])
+		p.buf.Truncate(len(b) - 1)
+	}
+
+	if p.opts.disallowInvalidUTF8 {
+		if i := findInvalidUTF8(p.buf.Bytes()); i >= 0 {
+			p.serrInString(start, startColumn, i, 0, ErrInvalidString, "invalid UTF-8 byte %s in string", charString(int(p.buf.Bytes()[i])))
+			return nil, false, false
+		}
+	}
+
+	if p.opts.disallowNul {
+		if i := findEscapedNul(p.buf.Bytes()); i >= 0 {
+			if p.path.key {
+				p.serrInString(start, startColumn, i, 0, ErrInvalidString, "\\u0000 in a key of the object at %q", p.path.containerPointer())
+			} else {
+				p.serrInString(start, startColumn, i, 0, ErrInvalidString, "\\u0000 in the string at %q", p.path.nextPointer())
+			}
+			return nil, false, false
+		}
+	}
+
+	if p.opts.rejectLoneSurrogates {
+		if i := findLoneSurrogate(p.buf.Bytes()); i >= 0 {
+			p.serrInString(start, startColumn, i, 0, ErrInvalidString, "lone surrogate %s in string", p.buf.Bytes()[i:i+6])
+			return nil, false, false
+		}
+	}
+
+	if p.skipDecoding() {
+		return nil, false, true
+	}
+
+	raw := p.buf.Bytes()
+	if quote == '\'' {
+		raw = escapeQuotes(raw)
+	}
+	if p.opts.lineContinuations {
+		raw = dropLineContinuations(raw)
+	}
+	decoded, ok := decodeToUTF8(raw)
+	if !ok {
+		p.serrAt(start-1, startColumn-1, ErrInvalidString, "unable to decode string into a valid UTF-8 string")
+		return nil, false, false
+	}
+
+	// The input can be used as is if the string had nothing to decode and no escape was dropped on an error.
+	if p.inMemory && len(raw) == p.pos-1-begin && (len(raw) == 0 || &decoded[0] == &raw[0]) {
+		return p.data[begin : p.pos-1 : p.pos-1], true, true
+	}
+
+	return decoded, false, true
+}
+
+// serrInString records an error about the byte at index i of the string just read in p.buf, which starts at offset
+// start and column startColumn. removed is the number of bytes before i already dropped from p.buf.
+//
+// Strings only span several lines with AllowLineContinuations, in which case p.buf keeps their line feeds.
+func (p *Parser) serrInString(start int64, startColumn, i, removed int, err error, format string, args ...interface{}) {
+	b := p.buf.Bytes()
+	offset := start + int64(i+removed)
+
+	n := bytes.LastIndexByte(b[:i], '\n')
+	if n < 0 {
+		p.serrAt(offset, startColumn+countColumns(b[:i])+removed, err, format, args...)
+		return
+	}
+
+	// The string ends on the current line: count back the lines after i.
+	line := p.line - bytes.Count(b[i:], []byte{'\n'})
+	lineStart := offset - int64(i-n-1)
+	p.err = p.parseErrorIn(line, lineStart, offset, countColumns(b[n+1:i])+1, fmt.Sprintf(format, args...), err)
+}
+
+// scanIdentifier reads the unquoted key whose first byte, r, was just read, and returns it like scanBytes.
+func (p *Parser) scanIdentifier(r int) ([]byte, bool) {
+	for ; isIdentifier(r); r = p.readByte() {
+		p.buf.WriteByte(byte(r))
+	}
+	// JSON5 allows the escapes and the Unicode letters, which are rejected, and the Unicode whitespace, which isn't.
+	unsupported := r == '\\'
+	if r >= utf8.RuneSelf {
+		rr, _ := p.peekRune(r)
+		unsupported = !isUnicodeSpace(rr)
+	}
+	if p.opts.json5 && unsupported {
+		p.serr(ErrInvalidCharacter, "unsupported JSON5 unquoted key: only ASCII identifiers are supported")
+		return nil, false
+	}
+	if r != eof {
+		p.unreadByte()
+	}
+
+	return p.buf.Bytes(), true
+}
+
+// dropLineContinuations removes the line continuations of the raw string s, read with AllowLineContinuations: the
+// backslashes followed by a line terminator, and the terminators.
+func dropLineContinuations(s []byte) []byte {
+	var kept []byte
+	last := 0
+	for i := 0; i < len(s)-1; i++ {
+		if s[i] != '\\' {
+			continue
+		}
+
+		n := lineTerminator(s[i+1:])
+		if n > 0 {
+			kept = append(kept, s[last:i]...)
+			last = i + 1 + n
+		}
+		// skip the escaped byte
+		if n < 1 {
+			n = 1
+		}
+		i += n
+	}
+	if kept == nil && last == 0 {
+		return s
+	}
+
+	return append(kept, s[last:]...)
+}
+
+// lineTerminator returns the length of the line terminator s starts with, as defined by JSON5, or 0.
+func lineTerminator(s []byte) int {
+	switch {
+	case bytes.HasPrefix(s, []byte("\r\n")):
+		return 2
+	case len(s) > 0 && (s[0] == '\n' || s[0] == '\r'):
+		return 1
+	case bytes.HasPrefix(s, []byte("\u2028")) || bytes.HasPrefix(s, []byte("\u2029")):
+		return 3
+	}
+
+	return 0
+}
+
+// escapeQuotes escapes the double quotes of the raw single-quoted string s, so that it is decoded like a
+// double-quoted string.
+func escapeQuotes(s []byte) []byte {
+	if bytes.IndexByte(s, '"') < 0 {
+		return s
+	}
+
+	escaped := make([]byte, 0, len(s)+4)
+	backslash := false
+	for _, c := range s {
+		if c == '"' && !backslash {
+			escaped = append(escaped, '\\')
+		}
+		backslash = c == '\\' && !backslash
+		escaped = append(escaped, c)
+	}
+
+	return escaped
+}
+
+// skipDecoding reports whether the string being read is only validated, by Valid or SkipValue. Valid still decodes
+// the keys for the path of errors, unless the parser doesn't validate them, and both decode them to find duplicates.
+func (p *Parser) skipDecoding() bool {
+	if p.path.key {
+		return p.opts.validate && !p.opts.validateKeys || p.skipping && !p.opts.disallowDuplicateKeys
+	}
+	return p.opts.validate || p.skipping
+}
+
+// checkEscapes validates the escape sequences of the raw string s, read with o. If one is invalid, it returns its
+// index and the reason. Otherwise it returns -1.
+//
+// \' is only valid without StrictMode, since RFC 8259 doesn't allow it, and line continuations with
+// AllowLineContinuations.
+func checkEscapes(s []byte, o *options) (int, string) {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			continue
+		}
+
+		if i+1 == len(s) {
+			return i, "unterminated escape in string"
+		}
+		if n := lineTerminator(s[i+1:]); n > 0 && o.lineContinuations {
+			i += n
+			continue
+		}
+
+		switch s[i+1] {
+		case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+			i++
+		case 'u':
+			end := i + 6
+			if end > len(s) {
+				end = len(s)
+			}
+
+			for j := i + 2; j < i+6; j++ {
+				if j == len(s) || !isHexDigit(s[j]) {
+					return i, fmt.Sprintf("invalid \\u escape \"%s\" (expected 4 hex digits)", s[i:end])
+				}
+			}
+			i += 5
+		default:
+			if s[i+1] == '\'' && !o.strict {
+				i++
+				continue
+			}
+			if o.json5 && (s[i+1] < '1' || s[i+1] > '9') {
+				return i, fmt.Sprintf("unsupported JSON5 escape \"\\%s\" in string", charString(int(s[i+1])))
+			}
+			return i, fmt.Sprintf("invalid escape \"\\%s\" in string", charString(int(s[i+1])))
+		}
+	}
+
+	return -1, ""
+}
+
+func isHexDigit(b byte) bool {
+	return isDigit(b) || b >= 'a' && b <= 'f' || b >= 'A' && b <= 'F'
+}
+
+// findInvalidUTF8 returns the index of the first byte of s which doesn't start a valid UTF-8 sequence, or -1.
+func findInvalidUTF8(s []byte) int {
+	for i := 0; i < len(s); {
+		rr, size := utf8.DecodeRune(s[i:])
+		if rr == utf8.RuneError && size == 1 {
+			return i
+		}
+		i += size
+	}
+
+	return -1
+}
+
+// findEscapedNul returns the index of the first \u0000 escape of the raw string s, or -1.
+func findEscapedNul(s []byte) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			continue
+		}
+
+		if getu4(s[i:]) == 0 {
+			return i
+		}
+		// skip the escaped byte
+		i++
+	}
+
+	return -1
+}
+
+// findLoneSurrogate returns the index of the first \u escape of a surrogate in s which is not part of a valid
+// surrogate pair, or -1.
+func findLoneSurrogate(s []byte) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			continue
+		}
+
+		rr := getu4(s[i:])
+		if rr < 0 {
+			// another escape: skip the escaped byte
+			i++
+			continue
+		}
+
+		if utf16.IsSurrogate(rr) {
+			if rr >= 0xdc00 || utf16.DecodeRune(rr, getu4(s[i+6:])) == unicode.ReplacementChar {
+				return i
+			}
+			i += 6
+		}
+		i += 5
+	}
+
+	return -1
+}
+
+// expandString emits the events of the document serialized in s instead of a StringEvent.
+//
+// It returns false without emitting anything if s does not hold exactly one valid document.
+func (p *Parser) expandString(s string) bool {
+	trimmed := strings.TrimLeftFunc(s, unicode.IsSpace)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return false
+	}
+
+	sub := NewParser(strings.NewReader(s))
+	sub.opts = p.opts
+	sub.opts.expandDepth--
+	// The events are compacted when they are emitted by p, which emits the events of its own documents, reports
+	// its progress and writes its input to the writer given to Tee. The end of s is the end of its document, which
+	// is neither followed nor timed.
+	sub.opts.compactEvents = false
+	sub.opts.documentEvents = false
+	sub.opts.progress = nil
+	sub.opts.tee = nil
+	sub.opts.followCtx = nil
+	sub.opts.followInterval = 0
+	sub.opts.timeout = 0
+
+	var (
+		events []Event
+		depth  int
+	)
+	for {
+		ev, err := sub.Next()
+		if err == io.EOF {
+			break
+		}
+
+		switch {
+		case err != nil || ev.Error != nil:
+			return false
+		case len(events) > 0 && depth == 0:
+			// a second document
+			return false
+		case ev.Type == ObjectStartEvent || ev.Type == ArrayStartEvent:
+			depth++
+		case ev.Type == ObjectEndEvent || ev.Type == ArrayEndEvent:
+			depth--
+		}
+
+		// The strings of sub don't outlive its next event.
+		events = append(events, ev.Copy())
+	}
 
-// NewParser creates a new parser that reads from r.
-func NewParser(r io.Reader) *Parser {
-	return &Parser{
-		br:   bufio.NewReader(r),
-		line: 1,
+	for _, ev := range events {
+		p.emit(ev)
 	}
+
+	return true
 }
 
-var (
-	eof = byte(0)
+// isSpace reports whether b is whitespace as defined by RFC 8259.
+func isSpace(b int) bool {
+	switch b {
+	case '\t', '\n', '\r', ' ':
+		return true
+	default:
+		return false
+	}
+}
 
-	errUnexpectedEOF = errors.New("unexpected end of file")
-)
+// isLenientSpace reports whether b is one of the additional whitespace bytes accepted with LenientWhitespace.
+func isLenientSpace(b int) bool {
+	switch b {
+	case '\v', '\f', 0x85, 0xA0:
+		return true
+	default:
+		return false
+	}
+}
 
-// Parse starts parsing data from the input stream and emit events.
-//
-// This method parses data until the input stream is empty.
-func (p *Parser) Parse(ch chan Event) {
-	p.ch = ch
-loop:
+func (p *Parser) readIgnoreWS() int {
 	for {
-		switch r := p.readByte(); r {
-		case eof:
-			p.serr2(errUnexpectedEOF)
-			break loop
-		case '{':
-			p.unreadByte()
-			if !p.readObject() {
-				break loop
-			}
-		case '[':
-			p.unreadByte()
-			if !p.readArray() {
-				break loop
+		r := p.readByte()
+		switch {
+		case r == eof:
+			return eof
+		case isSpace(r), p.opts.lenientWhitespace && isLenientSpace(r):
+		case p.startsComment(r):
+			if !p.skipComment(r) {
+				return eof
 			}
+		case p.opts.unicodeWhitespace && p.skipUnicodeSpace(r):
 		default:
-			p.serr("unexpected character %c", r)
-			break loop
+			return r
 		}
-
-		// EOF is valid here because we read either a full object or a full array
-		// and we need to allow parsing fixed-size data
-		r := p.readIgnoreWS()
-		if r == eof {
-			break
-		}
-		p.unreadByte()
-
-		p.resetState()
-	}
-
-	if err := p.getError(); err != nil {
-		p.emitEvent(EOFEvent, nil, err)
 	}
 }
 
-func (p *Parser) readObject() bool {
-	r := p.readIgnoreWS()
-	if r == eof {
-		p.serr2(errUnexpectedEOF)
-		return false
+// skipUnicodeSpace reports whether r, the last byte read, starts one of the whitespace characters allowed by
+// UnicodeWhitespace, whose other bytes it reads.
+func (p *Parser) skipUnicodeSpace(r int) bool {
+	if r < utf8.RuneSelf {
+		return r == '\v' || r == '\f'
 	}
 
-	if r != '{' {
-		p.serr("expected { but got %c", r)
+	rr, size := p.peekRune(r)
+	if !isUnicodeSpace(rr) {
 		return false
 	}
+	for i := 1; i < size; i++ {
+		p.readByte()
+	}
 
-	p.emitEvent(ObjectStartEvent, nil, nil)
+	return true
+}
 
-	r = p.readIgnoreWS()
-	if r == eof {
-		p.serr2(errUnexpectedEOF)
+// peekRune decodes the UTF-8 character starting with r, the last byte read, without reading its other bytes.
+func (p *Parser) peekRune(r int) (rune, int) {
+	return utf8.DecodeRune(append([]byte{byte(r)}, p.peek(utf8.UTFMax-1)...))
+}
+
+// isUnicodeSpace reports whether r is one of the non-ASCII whitespace characters allowed by UnicodeWhitespace.
+func isUnicodeSpace(r rune) bool {
+	return unicode.Is(unicode.Zs, r) || r == '\u2028' || r == '\u2029' || r == '\uFEFF'
+}
+
+// startsComment reports whether r, the last byte read, starts a comment, with AllowComments or AllowHashComments.
+func (p *Parser) startsComment(r int) bool {
+	if r == '#' {
+		return p.opts.allowHashComments
+	}
+	if r != '/' || !p.opts.allowComments {
 		return false
 	}
 
-	if r == '}' {
-		p.emitEvent(ObjectEndEvent, nil, nil)
+	next := p.peek(1)
+	return len(next) == 1 && (next[0] == '/' || next[0] == '*')
+}
+
+// skipComment reads the rest of the comment whose first byte, r, was just read. It returns false if the input ends
+// inside a /* comment, recording the error at its start.
+func (p *Parser) skipComment(r int) bool {
+	line, lineStart, start, startColumn := p.line, p.lineStart, p.offset-1, p.lastColumn()
+
+	if r == '#' || p.readByte() == '/' {
+		for r := p.readByte(); r != eof && r != '\n'; r = p.readByte() {
+		}
 		return true
 	}
-	p.unreadByte()
 
+	star := false
 	for {
-		p.emitEvent(ObjectKeyEvent, nil, nil)
-
-		ok := p.readString()
-		if !ok {
+		r := p.readByte()
+		switch {
+		case r == eof:
+			if p.err == io.EOF {
+				p.err = p.parseErrorIn(line, lineStart, start, startColumn, "unterminated comment", ErrUnexpectedEOF)
+			}
 			return false
+		case star && r == '/':
+			return true
 		}
+		star = r == '*'
+	}
+}
 
-		r := p.readIgnoreWS()
-		if r != ':' {
-			p.serr("expected : but got %c", r)
-			return false
+// unreadByte makes the next call to readByte return the last byte read again. It must only be called after a
+// successful call to readByte: only a single byte can be unread.
+func (p *Parser) unreadByte() {
+	p.unread = true
+	if p.capturing {
+		p.raw = p.raw[:len(p.raw)-1]
+	}
+	if p.opts.tee != nil {
+		p.teed = p.teed[:len(p.teed)-1]
+	}
+	p.offset--
+	p.columns = p.prevColumns
+	if p.lastColumnStart {
+		p.column--
+	}
+	if p.lastNewline {
+		p.line--
+		p.lineStart = p.prevLineStart
+		p.column = p.prevColumn - 1
+		p.lastNewline = false
+		if p.index != nil {
+			p.index.lineStarts = p.index.lineStarts[:len(p.index.lineStarts)-1]
 		}
+	}
+}
 
-		p.emitEvent(ObjectValueEvent, nil, nil)
+func (p *Parser) readByte() int {
+	if atomic.LoadInt32(&p.stopped) != 0 {
+		p.err = p.stopCause
+		return eof
+	}
 
-		ok = p.readValue()
-		if !ok {
-			return false
+	var r byte
+	if p.unread {
+		p.unread = false
+		r = p.last
+	} else {
+		var err error
+		r, err = p.nextByte()
+		if err == io.EOF && p.opts.followCtx != nil {
+			r, err = p.follow()
 		}
-
-		r = p.readIgnoreWS()
-		if r == eof {
-			p.serr2(errUnexpectedEOF)
-			return false
-		} else if r == '}' {
-			break
-		} else if r != ',' {
-			p.serr("expected , but got %c", r)
-			return false
+		if err != nil {
+			p.err = err
+			p.endProgress()
+			if err := p.flushTee(); err != nil {
+				p.err = err
+			}
+			return eof
 		}
+		p.last = r
+	}
+	if p.capturing {
+		p.raw = append(p.raw, r)
+	}
+	if p.opts.tee != nil {
+		p.teed = append(p.teed, r)
 	}
 
-	p.emitEvent(ObjectEndEvent, nil, nil)
+	p.offset++
+	if p.offset >= p.nextProgress {
+		p.reportProgress()
+	}
+	p.prevColumns = p.columns
+	p.lastColumnStart = p.columns.next(r)
+	if p.lastColumnStart {
+		p.column++
+	}
+	p.lastNewline = r == '\n'
+	if p.lastNewline {
+		p.line++
+		p.prevLineStart = p.lineStart
+		p.lineStart = p.offset
+		p.prevColumn = p.column
+		p.column = 0
+		if p.index != nil {
+			p.index.lineStarts = append(p.index.lineStarts, p.offset)
+		}
+	}
 
-	return true
+	return int(r)
 }
 
-func (p *Parser) readArray() bool {
-	r := p.readIgnoreWS()
-	if r == eof {
-		p.serr2(errUnexpectedEOF)
-		return false
+// nextByte returns the next byte of the input.
+func (p *Parser) nextByte() (byte, error) {
+	if !p.inMemory {
+		return p.br.ReadByte()
 	}
 
-	if r != '[' {
-		p.serr("expected [ but got %c", r)
-		return false
+	if p.pos == len(p.data) {
+		return 0, io.EOF
 	}
+	p.pos++
 
-	p.emitEvent(ArrayStartEvent, nil, nil)
-
-	r = p.readIgnoreWS()
-	if r == eof {
-		p.serr2(errUnexpectedEOF)
-		return false
-	}
+	return p.data[p.pos-1], nil
+}
 
-	if r == ']' {
-		p.emitEvent(ArrayEndEvent, nil, nil)
-		return true
+// peek returns up to n of the next bytes of the input, without reading them.
+func (p *Parser) peek(n int) []byte {
+	if !p.inMemory {
+		b, _ := p.br.Peek(n)
+		return b
 	}
-	p.unreadByte()
-
-	for {
-		ok := p.readValue()
-		if !ok {
-			return false
-		}
 
-		r := p.readIgnoreWS()
-		if r == eof {
-			p.serr2(errUnexpectedEOF)
-			return false
-		} else if r == ']' {
-			break
-		} else if r != ',' {
-			p.serr("expected , but got %c", r)
-			return false
-		}
+	if n > len(p.data)-p.pos {
+		n = len(p.data) - p.pos
 	}
 
-	p.emitEvent(ArrayEndEvent, nil, nil)
+	return p.data[p.pos : p.pos+n]
+}
 
-	return true
+// position returns the position of the last byte read in the current line, or 0 at the start of a line.
+func (p *Parser) position() int {
+	return int(p.offset - p.lineStart)
 }
 
-func (p *Parser) getError() error {
-	if p.err == io.EOF {
-		return nil
+// lastColumn returns the column of the last byte read, which may be the newline ending the previous line.
+func (p *Parser) lastColumn() int {
+	if p.lastNewline {
+		return p.prevColumn
 	}
-
-	return p.err
+	return p.column
 }
 
-func isDigit(b byte) bool {
-	return b >= '0' && b <= '9'
+// A columnCounter tells the bytes starting a new column apart from the continuation bytes of UTF-8 sequences.
+// Bytes which aren't part of a valid sequence count as a column each.
+type columnCounter struct {
+	// cont is the number of continuation bytes expected after the last leading byte.
+	cont int
 }
 
-func (p *Parser) readValue() bool {
-	r := p.readIgnoreWS()
-	if r == eof {
-		p.serr2(errUnexpectedEOF)
+// next reports whether b, following the bytes already counted, starts a new column.
+func (c *columnCounter) next(b byte) bool {
+	if c.cont > 0 && b&0xC0 == 0x80 {
+		c.cont--
 		return false
 	}
 
 	switch {
-	case r == '"':
-		p.unreadByte()
-		return p.readString()
-	case r == '\'':
-		r := p.readByte()
-		if r == eof {
-			return false
-		}
-
-		return true
-	case r == 'f' || r == 't':
-		p.unreadByte()
-		return p.readBoolean()
-	case r == '-' || r == '+' || isDigit(r):
-		p.unreadByte()
-		return p.readNumber()
-	case r == '{':
-		p.unreadByte()
-		return p.readObject()
-	case r == '[':
-		p.unreadByte()
-		return p.readArray()
+	case b&0xE0 == 0xC0:
+		c.cont = 1
+	case b&0xF0 == 0xE0:
+		c.cont = 2
+	case b&0xF8 == 0xF0:
+		c.cont = 3
 	default:
-		p.serr("unexpected character %c", r)
-		return false
+		c.cont = 0
 	}
-}
 
-func (p *Parser) readBoolean() bool {
-	var buf bytes.Buffer
+	return true
+}
 
-	for i := 0; i < 4; i++ {
-		r := p.readByte()
-		if r == eof {
-			p.serr2(errUnexpectedEOF)
-			return false
+// countColumns returns the number of columns of b.
+func countColumns(b []byte) int {
+	var (
+		c columnCounter
+		n int
+	)
+	for _, x := range b {
+		if c.next(x) {
+			n++
 		}
-
-		buf.WriteByte(r)
 	}
 
-	if buf.String() == "true" {
-		p.emitEvent(BooleanEvent, true, nil)
-		return true
-	}
+	return n
+}
 
-	r := p.readByte()
-	if r == eof {
-		p.serr2(errUnexpectedEOF)
-		return false
+// charString formats a byte read from the input for an error message.
+func charString(c int) string {
+	if c < ' ' || c >= 0x7f {
+		return fmt.Sprintf("0x%02x", c)
 	}
+	return string(rune(c))
+}
 
-	if r != 'e' {
-		p.serr("expected e but got %c", r)
-		return false
+// charString formats the character starting with r, the last byte read, for an error message.
+//
+// The rest of a multi-byte UTF-8 character is peeked from the input so that the whole character is shown.
+// Non-printable characters are shown as U+XXXX, and bytes which don't start a valid character in hex.
+func (p *Parser) charString(r int) string {
+	if r < utf8.RuneSelf {
+		return charString(r)
 	}
 
-	p.emitEvent(BooleanEvent, false, nil)
-
-	return true
-}
-
-func (p *Parser) readNumber() bool {
-	buf.Reset()
+	next := p.peek(utf8.UTFMax - 1)
 
-	isFloat := false
-loop:
-	for {
-		var r byte
-		switch r = p.readByte(); {
-		case r == eof:
-			p.serr2(errUnexpectedEOF)
-			return false
-		case r == '.' || r == 'e' || r == 'E':
-			isFloat = true
-		case r != '.' && r != 'e' && r != 'E' && r != '+' && r != '-' && !isDigit(r):
-			p.unreadByte()
-			break loop
-		}
+	buf := make([]byte, 0, utf8.UTFMax)
+	buf = append(buf, byte(r))
+	buf = append(buf, next...)
 
-		buf.WriteByte(r)
+	rr, size := utf8.DecodeRune(buf)
+	switch {
+	case rr == utf8.RuneError && size == 1:
+		return charString(r)
+	case !unicode.IsPrint(rr):
+		return fmt.Sprintf("%U", rr)
 	}
 
-	if isFloat {
-		f, err := strconv.ParseFloat(buf.String(), 64)
-		if err != nil {
-			p.serr2(err)
-			return false
-		}
+	return string(rr)
+}
 
-		p.emitEvent(NumberEvent, f, nil)
+func (p *Parser) emitEvent(typ EventType, value interface{}, err error) {
+	p.emit(Event{Type: typ, Value: value, Error: err})
+}
 
-		return true
+// emitString emits a StringEvent for s, which is boxed into its Value unless the parser was created with
+// TypedValues.
+func (p *Parser) emitString(s string) {
+	ev := Event{Type: StringEvent, ValueKind: StringValue, Str: s}
+	if !p.opts.typedValues {
+		ev.Value = s
 	}
+	p.emit(ev)
+}
 
-	i, err := strconv.ParseInt(buf.String(), 10, 64)
-	if err != nil {
-		p.serr2(err)
-		return false
+// emitBytes emits a StringEvent for b, for a parser created with ByteStrings. Unless b is a slice of the input,
+// it is copied to strs, which holds the strings of the queued events.
+func (p *Parser) emitBytes(b []byte, input bool) {
+	if !input {
+		n := len(p.strs)
+		p.strs = append(p.strs, b...)
+		b = p.strs[n:len(p.strs):len(p.strs)]
 	}
+	p.emit(Event{Type: StringEvent, ValueKind: BytesValue, Bytes: b})
+}
 
-	p.emitEvent(NumberEvent, i, nil)
-
-	return true
+// emitFloat is like emitString for a NumberEvent holding f.
+func (p *Parser) emitFloat(f float64, isInt bool) {
+	ev := Event{Type: NumberEvent, ValueKind: FloatValue, Float: f, IsInt: isInt}
+	if !p.opts.typedValues {
+		ev.Value = f
+	}
+	p.emit(ev)
 }
 
-var buf bytes.Buffer
+func (p *Parser) emit(ev Event) {
+	if p.opts.positions {
+		p.setPosition(&ev)
+	}
+	if ev.Type != EOFEvent {
+		// The depth of an expanded event is relative to the string.
+		ev.Depth += len(p.stack)
+	}
 
-func (p *Parser) readString() bool {
-	buf.Reset()
+	key := ev.Type == StringEvent && p.path.key
+	p.path.update(ev)
+	p.inValue = false
 
-	r := p.readIgnoreWS()
-	if r == eof {
-		p.serr2(errUnexpectedEOF)
-		return false
+	if p.opts.compactEvents {
+		// The markers are still tracked, the position of the parser being the same in both modes.
+		switch {
+		case ev.Type == ObjectKeyEvent || ev.Type == ObjectValueEvent:
+			return
+		case key:
+			ev.Type = ObjectKeyEvent
+		}
 	}
 
-	if r != '"' {
-		p.serr("expected \" but got %c", r)
-		return false
+	if p.skipping && !p.skipEvent(ev, key) {
+		p.consumed.update(ev)
+		return
 	}
 
-	for {
-		r = p.readByte()
-		if r == eof {
-			p.serr2(errUnexpectedEOF)
-			return false
-		}
+	if !p.opts.disableStats {
+		p.count(ev)
+	}
+	p.queue = append(p.queue, ev)
+}
 
-		if r == '"' {
-			break
-		}
+// mark records the last byte read as the start of the next token, for EventPositions.
+func (p *Parser) mark() {
+	if p.opts.positions {
+		p.markLine, p.markColumn, p.markOffset = p.line, p.lastColumn(), p.offset-1
+	}
+}
 
-		buf.WriteByte(r)
+// setPosition sets the position of ev to the start of the last token, or to the position of its error, and its
+// span up to the last byte read. The events of a document carry their own position, and an end event the start
+// of its container.
+func (p *Parser) setPosition(ev *Event) {
+	if ev.Type == DocumentStartEvent || ev.Type == DocumentEndEvent {
+		ev.StartOffset, ev.EndOffset = ev.Offset, ev.Offset
+		return
+	}
+	if err, ok := ev.Error.(ParseError); ok && (ev.Type == ErrorEvent || ev.Type == EOFEvent) {
+		ev.Line, ev.Column, ev.Offset = err.Line, err.Column, err.Offset
+		ev.StartOffset, ev.EndOffset = err.Offset, err.Offset
+		return
 	}
 
-	decoded, ok := decodeToUTF8(buf.Bytes())
-	if !ok {
-		p.serr("unable to decode string into a valid UTF-8 string")
+	ev.Line, ev.Column, ev.Offset = p.markLine, p.markColumn, p.markOffset
+	if !ev.IsContainerEnd() {
+		ev.StartOffset = ev.Offset
+	}
+	ev.EndOffset = p.offset
+}
+
+// recoverError emits the error recorded by the last call to serr or serrAt as an ErrorEvent, so that parsing goes on,
+// if the parser collects errors. It returns whether it did.
+func (p *Parser) recoverError() bool {
+	if !p.opts.collectErrors {
 		return false
 	}
 
-	p.emitEvent(StringEvent, string(decoded), nil)
+	err := p.err
+	p.err = nil
+	p.emitEvent(ErrorEvent, nil, err)
 
 	return true
 }
 
-func isSpace(b byte) bool {
-	switch b {
-	case '\t', '\n', '\v', '\f', '\r', ' ', 0x85, 0xA0:
-		return true
-	default:
-		return false
-	}
+// serr records an error about the last byte read, wrapping the sentinel err of its category.
+func (p *Parser) serr(err error, format string, args ...interface{}) {
+	p.serrAt(p.offset-1, p.lastColumn(), err, format, args...)
+}
+
+// serrAt is like serr for an error about the byte at offset and column, which must be in the current line or
+// the previous one.
+func (p *Parser) serrAt(offset int64, column int, err error, format string, args ...interface{}) {
+	p.err = p.parseError(offset, column, fmt.Sprintf(format, args...), err)
 }
 
-func (p *Parser) readIgnoreWS() byte {
-	r := p.readByte()
-	for r != eof && isSpace(r) {
-		// eat whitespaces
+// serr2 records err as the error about the last byte read.
+func (p *Parser) serr2(err error) {
+	p.serr2At(p.offset-1, p.lastColumn(), err)
+}
 
-		r = p.readByte()
-	}
-	return r
+// serr2At is like serr2 for an error about the byte at offset and column.
+func (p *Parser) serr2At(offset int64, column int, err error) {
+	p.err = p.parseError(offset, column, err.Error(), err)
 }
 
-func (p *Parser) unreadByte() {
-	p.position--
-	if p.unreadChangesLine {
-		p.line--
-		p.position = 0
+// serrLimit records the error for the input exceeding limit, which is configured to value.
+func (p *Parser) serrLimit(limit Limit, value int64) {
+	p.serr2(LimitError{Limit: limit, Value: value, Line: p.line, Position: p.position()})
+}
+
+// serrEOF records the error for the input ending early, which is the error of the reader if it failed.
+func (p *Parser) serrEOF() {
+	if _, ok := p.err.(ParseError); ok {
+		// The input ended in a comment.
+		return
+	}
+	if p.err == nil || p.err == io.EOF {
+		p.serr2At(p.offset, p.column+1, ErrUnexpectedEOF)
+		return
 	}
-	p.br.UnreadByte()
+
+	p.serr2At(p.offset, p.column+1, p.err)
 }
 
-func (p *Parser) readByte() byte {
-	r, err := p.br.ReadByte()
-	if err != nil {
-		p.err = err
-		return eof
+// serrEOFExpecting is like serrEOF, with a message telling what was expected instead of the end of the input.
+func (p *Parser) serrEOFExpecting(expected string) {
+	if _, ok := p.err.(ParseError); ok {
+		return
+	}
+	if p.err == nil || p.err == io.EOF {
+		p.err = p.parseError(p.offset, p.column+1, "unexpected end of file while expecting "+expected, ErrUnexpectedEOF)
+		return
 	}
 
-	p.position++
-	if r == '\n' {
-		p.line++
-		p.position = 0
-		p.unreadChangesLine = true
-	} else {
-		p.unreadChangesLine = false
+	p.serr2At(p.offset, p.column+1, p.err)
+}
+
+// parseError returns the error about the byte at offset and column, which must be in the current line or the
+// previous one.
+func (p *Parser) parseError(offset int64, column int, message string, err error) ParseError {
+	line, lineStart := p.line, p.lineStart
+	if offset < lineStart {
+		line, lineStart = line-1, p.prevLineStart
 	}
 
-	return r
+	return p.parseErrorIn(line, lineStart, offset, column, message, err)
 }
 
-func (p *Parser) emitEvent(typ EventType, value interface{}, err error) {
-	p.ch <- Event{typ, value, err}
+// parseErrorIn is like parseError for a byte of any line, which starts at lineStart.
+func (p *Parser) parseErrorIn(line int, lineStart, offset int64, column int, message string, err error) ParseError {
+	position := int(offset-lineStart) + 1
+
+	e := ParseError{
+		Message:          message,
+		Line:             line,
+		Position:         position,
+		Offset:           offset,
+		Column:           column,
+		Document:         p.document,
+		DocumentLine:     line - p.docLine + 1,
+		DocumentPosition: position,
+		Err:              err,
+	}
+	if line == p.docLine {
+		e.DocumentPosition -= p.docColumn
+	}
+	e.Path, e.Context = p.errorContext()
+
+	return e
 }
 
-func (p *Parser) serr(format string, args ...interface{}) {
-	p.err = ParseError{
-		Message:  fmt.Sprintf(format, args...),
-		Line:     p.line,
-		Position: p.position,
+// errorContext returns the Path and the Context of a ParseError about the current position.
+func (p *Parser) errorContext() (string, string) {
+	if len(p.stack) == 0 {
+		return "", ""
+	}
+
+	container := p.path.containerPointer()
+
+	switch p.stack[len(p.stack)-1] {
+	case objectStart:
+		return container, "in " + describeContainer("object", container)
+	case arrayStart:
+		return container, "in " + describeContainer("array", container)
+	case objectKey:
+		if p.path.key {
+			return container, "in a key of " + describeContainer("object", container)
+		}
+		member := p.path.childPointer()
+		return member, fmt.Sprintf("after object key %q", member)
+	case objectNext:
+		member := p.path.childPointer()
+		if p.inValue {
+			return member, fmt.Sprintf("in object member %q", member)
+		}
+		return member, fmt.Sprintf("after object member %q", member)
+	default:
+		if p.inValue {
+			element := p.path.nextPointer()
+			return element, fmt.Sprintf("in array element %q", element)
+		}
+		element := p.path.childPointer()
+		return element, fmt.Sprintf("after array element %q", element)
 	}
 }
 
-func (p *Parser) serr2(err error) {
-	p.err = ParseError{
-		Message:  err.Error(),
-		Line:     p.line,
-		Position: p.position,
+// describeContainer describes the object or array located by ptr.
+func describeContainer(kind, ptr string) string {
+	if ptr == "" {
+		return "the top-level " + kind
 	}
+
+	return fmt.Sprintf("%s %q", kind, ptr)
 }
 
-func (p *Parser) resetState() {
-	p.line = 1
-	p.position = 0
+// startDocument records where the next document starts.
+func (p *Parser) startDocument() {
+	p.docLine = p.line
+	p.docColumn = p.position()
+	if !p.opts.disableStats {
+		p.stats.Documents++
+	}
 }
 
 // this is taken from the Golang distribution.
@@ -1,15 +1,27 @@
 package bari_test
 
 import (
+	"bufio"
+	"bytes"
 	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"math"
+	"math/big"
 	"os"
+	"strconv"
 	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/vrischmann/bari"
+	"github.com/vrischmann/bari/baritest"
 )
 
 func ck(t testing.TB, evt bari.Event, typ bari.EventType, value interface{}, err error) {
@@ -205,20 +217,58 @@ var testCases = []testCase{
 		},
 	},
 
-	// Invalid test cases
+	// Escaped quotes and backslashes
 
 	{
-		``,
+		`{"foo": "a\"b"}`,
+		[]expectedEvent{
+			{bari.ObjectStartEvent, nil, nil},
+			{bari.ObjectKeyEvent, nil, nil},
+			{bari.StringEvent, "foo", nil},
+			{bari.ObjectValueEvent, nil, nil},
+			{bari.StringEvent, `a"b`, nil},
+			{bari.ObjectEndEvent, nil, nil},
+		},
+	},
+	{
+		`["a\\", "\\\\", "\\\"\\"]`,
+		[]expectedEvent{
+			{bari.ArrayStartEvent, nil, nil},
+			{bari.StringEvent, `a\`, nil},
+			{bari.StringEvent, `\\`, nil},
+			{bari.StringEvent, `\"\`, nil},
+			{bari.ArrayEndEvent, nil, nil},
+		},
+	},
+	{
+		`["\"a\" and \"b\"", "x\""]`,
 		[]expectedEvent{
-			{bari.EOFEvent, nil, bari.ParseError{"unexpected end of file", 1, 0}},
+			{bari.ArrayStartEvent, nil, nil},
+			{bari.StringEvent, `"a" and "b"`, nil},
+			{bari.StringEvent, `x"`, nil},
+			{bari.ArrayEndEvent, nil, nil},
+		},
+	},
+	{
+		`{"a\"": "\""}`,
+		[]expectedEvent{
+			{bari.ObjectStartEvent, nil, nil},
+			{bari.ObjectKeyEvent, nil, nil},
+			{bari.StringEvent, `a"`, nil},
+			{bari.ObjectValueEvent, nil, nil},
+			{bari.StringEvent, `"`, nil},
+			{bari.ObjectEndEvent, nil, nil},
 		},
 	},
+
+	// Invalid test cases
+
 	{
 		`{f}`,
 		[]expectedEvent{
 			{bari.ObjectStartEvent, nil, nil},
 			{bari.ObjectKeyEvent, nil, nil},
-			{bari.EOFEvent, nil, bari.ParseError{"expected \" but got f", 1, 2}},
+			{bari.EOFEvent, nil, bari.ParseError{Message: "object keys must be strings, got unquoted key", Line: 1, Position: 2, Offset: 1, Column: 2, DocumentLine: 1, DocumentPosition: 2, Path: "", Context: "in a key of the top-level object", Err: bari.ErrInvalidCharacter}},
 		},
 	},
 	{
@@ -226,20 +276,20 @@ var testCases = []testCase{
 		[]expectedEvent{
 			{bari.ObjectStartEvent, nil, nil},
 			{bari.ObjectKeyEvent, nil, nil},
-			{bari.EOFEvent, nil, bari.ParseError{"unexpected end of file", 1, 2}},
+			{bari.EOFEvent, nil, bari.ParseError{Message: "unexpected end of file", Line: 1, Position: 3, Offset: 2, Column: 3, DocumentLine: 1, DocumentPosition: 3, Path: "", Context: "in a key of the top-level object", Err: bari.ErrUnexpectedEOF}},
 		},
 	},
 	{
 		`a`,
 		[]expectedEvent{
-			{bari.EOFEvent, nil, bari.ParseError{"unexpected character a", 1, 1}},
+			{bari.EOFEvent, nil, bari.ParseError{Message: "unexpected character a", Line: 1, Position: 1, Offset: 0, Column: 1, DocumentLine: 1, DocumentPosition: 1, Err: bari.ErrInvalidCharacter}},
 		},
 	},
 	{
 		`[`,
 		[]expectedEvent{
 			{bari.ArrayStartEvent, nil, nil},
-			{bari.EOFEvent, nil, bari.ParseError{"unexpected end of file", 1, 1}},
+			{bari.EOFEvent, nil, bari.ParseError{Message: "unexpected end of file", Line: 1, Position: 2, Offset: 1, Column: 2, DocumentLine: 1, DocumentPosition: 2, Path: "", Context: "in the top-level array", Err: bari.ErrUnexpectedEOF}},
 		},
 	},
 	{
@@ -247,7 +297,7 @@ var testCases = []testCase{
 		[]expectedEvent{
 			{bari.ArrayStartEvent, nil, nil},
 			{bari.StringEvent, "a", nil},
-			{bari.EOFEvent, nil, bari.ParseError{"unexpected end of file", 1, 4}},
+			{bari.EOFEvent, nil, bari.ParseError{Message: "unexpected end of file while expecting ',' or ']'", Line: 1, Position: 5, Offset: 4, Column: 5, DocumentLine: 1, DocumentPosition: 5, Path: "/0", Context: "after array element \"/0\"", Err: bari.ErrUnexpectedEOF}},
 		},
 	},
 	{
@@ -255,7 +305,73 @@ var testCases = []testCase{
 		[]expectedEvent{
 			{bari.ArrayStartEvent, nil, nil},
 			{bari.StringEvent, "a", nil},
-			{bari.EOFEvent, nil, bari.ParseError{"unexpected end of file", 1, 6}},
+			{bari.EOFEvent, nil, bari.ParseError{Message: "unexpected end of file", Line: 1, Position: 7, Offset: 6, Column: 7, DocumentLine: 1, DocumentPosition: 7, Path: "/1", Context: "in array element \"/1\"", Err: bari.ErrUnexpectedEOF}},
+		},
+	},
+
+	{
+		`["a\"]`,
+		[]expectedEvent{
+			{bari.ArrayStartEvent, nil, nil},
+			{bari.EOFEvent, nil, bari.ParseError{Message: "unexpected end of file", Line: 1, Position: 7, Offset: 6, Column: 7, DocumentLine: 1, DocumentPosition: 7, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrUnexpectedEOF}},
+		},
+	},
+
+	{
+		`{"a": 'x'}`,
+		[]expectedEvent{
+			{bari.ObjectStartEvent, nil, nil},
+			{bari.ObjectKeyEvent, nil, nil},
+			{bari.StringEvent, "a", nil},
+			{bari.ObjectValueEvent, nil, nil},
+			{bari.EOFEvent, nil, bari.ParseError{Message: "unexpected character ', strings must be double-quoted", Line: 1, Position: 7, Offset: 6, Column: 7, DocumentLine: 1, DocumentPosition: 7, Path: "/a", Context: "in object member \"/a\"", Err: bari.ErrInvalidCharacter}},
+		},
+	},
+	{
+		`['a']`,
+		[]expectedEvent{
+			{bari.ArrayStartEvent, nil, nil},
+			{bari.EOFEvent, nil, bari.ParseError{Message: "unexpected character ', strings must be double-quoted", Line: 1, Position: 2, Offset: 1, Column: 2, DocumentLine: 1, DocumentPosition: 2, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidCharacter}},
+		},
+	},
+
+	// Raw NUL bytes
+
+	{
+		"\x00{}",
+		[]expectedEvent{
+			{bari.EOFEvent, nil, bari.ParseError{Message: "unexpected character 0x00", Line: 1, Position: 1, Offset: 0, Column: 1, DocumentLine: 1, DocumentPosition: 1, Err: bari.ErrInvalidCharacter}},
+		},
+	},
+	{
+		"{ \x00 }",
+		[]expectedEvent{
+			{bari.ObjectStartEvent, nil, nil},
+			{bari.ObjectKeyEvent, nil, nil},
+			{bari.EOFEvent, nil, bari.ParseError{Message: "expected \" but got 0x00", Line: 1, Position: 3, Offset: 2, Column: 3, DocumentLine: 1, DocumentPosition: 3, Path: "", Context: "in a key of the top-level object", Err: bari.ErrInvalidCharacter}},
+		},
+	},
+	{
+		"[1, \x00]",
+		[]expectedEvent{
+			{bari.ArrayStartEvent, nil, nil},
+			{bari.NumberEvent, int64(1), nil},
+			{bari.EOFEvent, nil, bari.ParseError{Message: "unexpected character 0x00", Line: 1, Position: 5, Offset: 4, Column: 5, DocumentLine: 1, DocumentPosition: 5, Path: "/1", Context: "in array element \"/1\"", Err: bari.ErrInvalidCharacter}},
+		},
+	},
+	{
+		"[]\n\x00",
+		[]expectedEvent{
+			{bari.ArrayStartEvent, nil, nil},
+			{bari.ArrayEndEvent, nil, nil},
+			{bari.EOFEvent, nil, bari.ParseError{Message: "unexpected character 0x00", Line: 2, Position: 1, Offset: 3, Column: 1, Document: 1, DocumentLine: 1, DocumentPosition: 1, Err: bari.ErrInvalidCharacter}},
+		},
+	},
+	{
+		"[\"a\x00b\"]",
+		[]expectedEvent{
+			{bari.ArrayStartEvent, nil, nil},
+			{bari.EOFEvent, nil, bari.ParseError{Message: "invalid control character 0x00 in string", Line: 1, Position: 4, Offset: 3, Column: 4, DocumentLine: 1, DocumentPosition: 4, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidString}},
 		},
 	},
 
@@ -281,41 +397,2477 @@ var testCases = []testCase{
 	},
 }
 
-func TestParse(t *testing.T) {
-	for i, c := range testCases {
-		parser := bari.NewParser(strings.NewReader(c.data))
+func TestParse(t *testing.T) {
+	for i, c := range testCases {
+		parser := bari.NewParser(strings.NewReader(c.data))
+		ch := make(chan bari.Event)
+
+		go parser.ParseAndClose(ch)
+
+		for _, evt := range c.events {
+			ev := <-ch
+			fmt.Printf("case %d: `%s` %+v\n", i, c.data, ev)
+			ck(t, ev, evt.typ, evt.value, evt.err)
+		}
+	}
+}
+
+func TestParseOneByteReader(t *testing.T) {
+	for _, c := range testCases {
+		expected := parseAll(c.data)
+
+		parser := bari.NewParser(iotest.OneByteReader(strings.NewReader(c.data)))
+		require.Equal(t, expected, parseAllWith(parser), c.data)
+
+		parser = bari.NewParser(iotest.DataErrReader(iotest.HalfReader(strings.NewReader(c.data))))
+		require.Equal(t, expected, parseAllWith(parser), c.data)
+	}
+
+	const data = "{}\n  {\"a\": [1, 2 ,3]} \n\n\t[true, \"\u00e9\", -1.5e3]\n[\n  nul]"
+
+	events := parseAllWith(bari.NewParser(iotest.OneByteReader(strings.NewReader(data))))
+	require.Equal(t, parseAll(data), events)
+	require.Equal(t, bari.ParseError{Message: "unexpected character ] in literal null", Line: 6, Position: 6, Offset: 53, Column: 6, Document: 3, DocumentLine: 2, DocumentPosition: 6, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidCharacter}, events[len(events)-1].Error)
+}
+
+func TestNewParserSize(t *testing.T) {
+	long := strings.Repeat("abcdé", 100)
+	data := fmt.Sprintf(`{"%s": ["%s", %s, "\u00e9%s"]}`, long, long, strings.Repeat("1", 200)+".5e10", long)
+
+	for _, size := range []int{-1, 0, 1, 16, 17, 64, 1 << 16} {
+		for _, c := range testCases {
+			require.Equal(t, parseAll(c.data), parseAllWith(bari.NewParserSize(strings.NewReader(c.data), size)), "size: %d, data: %q", size, c.data)
+		}
+
+		// Tokens larger than the buffer.
+		require.Equal(t, parseAll(data), parseAllWith(bari.NewParserSize(strings.NewReader(data), size)), "size: %d", size)
+		require.Equal(t, parseAll(data), parseAllWith(bari.NewParserSize(iotest.OneByteReader(strings.NewReader(data)), size)), "size: %d", size)
+
+		// The buffer holds the longest XSSI prefix.
+		prefix := strings.Repeat("while(1);", 4)
+		opts := []bari.Option{bari.StripXSSIPrefix(prefix)}
+		require.Equal(t, parseAll("[1]"), parseAllWith(bari.NewParserSize(strings.NewReader(prefix+"[1]"), size, opts...)), "size: %d", size)
+	}
+}
+
+// byteScanner is a minimal io.ByteScanner.
+type byteScanner struct {
+	data string
+	pos  int
+}
+
+func (s *byteScanner) Read(b []byte) (int, error) {
+	panic("Read called on a byte scanner")
+}
+
+func (s *byteScanner) ReadByte() (byte, error) {
+	if s.pos == len(s.data) {
+		return 0, io.EOF
+	}
+	s.pos++
+	return s.data[s.pos-1], nil
+}
+
+func (s *byteScanner) UnreadByte() error {
+	s.pos--
+	return nil
+}
+
+func TestNewParserBufferedReader(t *testing.T) {
+	for _, c := range testCases {
+		require.Equal(t, parseAll(c.data), parseAllWith(bari.NewParser(bufio.NewReader(strings.NewReader(c.data)))), "data: %q", c.data)
+		require.Equal(t, parseAll(c.data), parseAllWith(bari.NewParser(&byteScanner{data: c.data})), "data: %q", c.data)
+	}
+
+	opts := []bari.Option{bari.StripXSSIPrefix()}
+	require.Equal(t, parseAll("[1]"), parseAllWith(bari.NewParser(&byteScanner{data: ")]}'\n[1]"}, opts...)))
+	require.Equal(t, parseAll(`["é", é]`), parseAllWith(bari.NewParser(&byteScanner{data: `["é", é]`})))
+
+	// The input following a document is left in the reader, except for the bytes read by the parser.
+	br := bufio.NewReader(strings.NewReader(`[1] [2]`))
+	parser := bari.NewParser(br)
+	for i := 0; i < 3; i++ {
+		_, err := parser.Next()
+		require.NoError(t, err)
+	}
+	rest, err := ioutil.ReadAll(io.MultiReader(parser.Buffered(), br))
+	require.NoError(t, err)
+	require.Equal(t, " [2]", string(rest))
+
+	scanner := &byteScanner{data: `[1] [2]`}
+	parser = bari.NewParser(scanner)
+	for i := 0; i < 3; i++ {
+		_, err := parser.Next()
+		require.NoError(t, err)
+	}
+	rest, err = ioutil.ReadAll(io.MultiReader(parser.Buffered(), iotest.OneByteReader(strings.NewReader(scanner.data[scanner.pos:]))))
+	require.NoError(t, err)
+	require.Equal(t, " [2]", string(rest))
+
+	// Resetting the parser leaves the reader given to it alone.
+	br = bufio.NewReader(strings.NewReader(`[1] [2]`))
+	parser = bari.NewParser(br)
+	_, err = parser.Next()
+	require.NoError(t, err)
+	parser.Reset(strings.NewReader(`[3]`))
+	require.Equal(t, parseAll(`[3]`), parseAllWith(parser))
+	parser.ResetBytes([]byte(`[4]`))
+	require.Equal(t, parseAll(`[4]`), parseAllWith(parser))
+	rest, err = ioutil.ReadAll(br)
+	require.NoError(t, err)
+	require.Equal(t, "1] [2]", string(rest))
+}
+
+func TestNewBytesParser(t *testing.T) {
+	for _, c := range testCases {
+		require.Equal(t, parseAll(c.data), parseAllWith(bari.NewBytesParser([]byte(c.data))), "data: %q", c.data)
+	}
+
+	for _, data := range []string{
+		"",
+		")]}'\n[1]",
+		"[1, 2] {\"a\": tru",
+		"[\"\u00e9\", \u00e9]",
+		"[\"a\" \xe2\x82]",
+		"{null: 1}",
+		"{nul",
+	} {
+		opts := []bari.Option{bari.StripXSSIPrefix()}
+		require.Equal(t, parseAll(data, opts...), parseAllWith(bari.NewBytesParser([]byte(data), opts...)), "data: %q", data)
+	}
+
+	ch := make(chan bari.Event, 3)
+	bari.ParseBytes([]byte(`[]`), ch)
+	ck(t, <-ch, bari.ArrayStartEvent, nil, nil)
+	ck(t, <-ch, bari.ArrayEndEvent, nil, nil)
+	ck(t, <-ch, bari.EOFEvent, nil, nil)
+
+	// A parser may switch between readers and byte slices.
+	parser := bari.NewBytesParser([]byte(`{`))
+	require.Equal(t, parseAll(`{`), parseAllWith(parser))
+	parser.Reset(strings.NewReader(`[1]`))
+	require.Equal(t, parseAll(`[1]`), parseAllWith(parser))
+	parser.ResetBytes([]byte(`[2]`))
+	require.Equal(t, parseAll(`[2]`), parseAllWith(parser))
+}
+
+func TestEmptyInput(t *testing.T) {
+	for _, data := range []string{"", "  ", "\n", " \r\n\t\n"} {
+		require.Empty(t, parseAll(data), "data: %q", data)
+	}
+
+	checkEvents(t, parseAll("\n  {\"a\": 1}\n\n  "), []expectedEvent{
+		{bari.ObjectStartEvent, nil, nil},
+		{bari.ObjectKeyEvent, nil, nil},
+		{bari.StringEvent, "a", nil},
+		{bari.ObjectValueEvent, nil, nil},
+		{bari.NumberEvent, int64(1), nil},
+		{bari.ObjectEndEvent, nil, nil},
+	})
+
+	events := parseAll("", bari.RequireDocument())
+	require.Equal(t, 1, len(events))
+	require.Equal(t, bari.ParseError{Message: "unexpected end of file", Line: 1, Position: 1, Offset: 0, Column: 1, DocumentLine: 1, DocumentPosition: 1, Err: bari.ErrUnexpectedEOF}, events[0].Error)
+
+	events = parseAll(" \n ", bari.RequireDocument())
+	require.Equal(t, 1, len(events))
+	require.Equal(t, bari.ParseError{Message: "unexpected end of file", Line: 2, Position: 2, Offset: 3, Column: 2, DocumentLine: 2, DocumentPosition: 2, Err: bari.ErrUnexpectedEOF}, events[0].Error)
+
+	events = parseAll("[] \n", bari.RequireDocument())
+	require.Equal(t, 2, len(events))
+}
+
+func TestLiterals(t *testing.T) {
+	testCases := []testCase{
+		{
+			`[true,false,null]`,
+			[]expectedEvent{
+				{bari.ArrayStartEvent, nil, nil},
+				{bari.BooleanEvent, true, nil},
+				{bari.BooleanEvent, false, nil},
+				{bari.NullEvent, nil, nil},
+				{bari.ArrayEndEvent, nil, nil},
+			},
+		},
+		{
+			`{"a":null,"b":false}`,
+			[]expectedEvent{
+				{bari.ObjectStartEvent, nil, nil},
+				{bari.ObjectKeyEvent, nil, nil},
+				{bari.StringEvent, "a", nil},
+				{bari.ObjectValueEvent, nil, nil},
+				{bari.NullEvent, nil, nil},
+				{bari.ObjectKeyEvent, nil, nil},
+				{bari.StringEvent, "b", nil},
+				{bari.ObjectValueEvent, nil, nil},
+				{bari.BooleanEvent, false, nil},
+				{bari.ObjectEndEvent, nil, nil},
+			},
+		},
+		{
+			`{"a": falxe}`,
+			[]expectedEvent{
+				{bari.ObjectStartEvent, nil, nil},
+				{bari.ObjectKeyEvent, nil, nil},
+				{bari.StringEvent, "a", nil},
+				{bari.ObjectValueEvent, nil, nil},
+				{bari.EOFEvent, nil, bari.ParseError{Message: "unexpected character x in literal false", Line: 1, Position: 10, Offset: 9, Column: 10, DocumentLine: 1, DocumentPosition: 10, Path: "/a", Context: "in object member \"/a\"", Err: bari.ErrInvalidCharacter}},
+			},
+		},
+		{
+			`{"a": txyze}`,
+			[]expectedEvent{
+				{bari.ObjectStartEvent, nil, nil},
+				{bari.ObjectKeyEvent, nil, nil},
+				{bari.StringEvent, "a", nil},
+				{bari.ObjectValueEvent, nil, nil},
+				{bari.EOFEvent, nil, bari.ParseError{Message: "unexpected character x in literal true", Line: 1, Position: 8, Offset: 7, Column: 8, DocumentLine: 1, DocumentPosition: 8, Path: "/a", Context: "in object member \"/a\"", Err: bari.ErrInvalidCharacter}},
+			},
+		},
+		{
+			`[tru`,
+			[]expectedEvent{
+				{bari.ArrayStartEvent, nil, nil},
+				{bari.EOFEvent, nil, bari.ParseError{Message: "unexpected end of file", Line: 1, Position: 5, Offset: 4, Column: 5, DocumentLine: 1, DocumentPosition: 5, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrUnexpectedEOF}},
+			},
+		},
+		{
+			`[fals`,
+			[]expectedEvent{
+				{bari.ArrayStartEvent, nil, nil},
+				{bari.EOFEvent, nil, bari.ParseError{Message: "unexpected end of file", Line: 1, Position: 6, Offset: 5, Column: 6, DocumentLine: 1, DocumentPosition: 6, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrUnexpectedEOF}},
+			},
+		},
+		{
+			`[nul]`,
+			[]expectedEvent{
+				{bari.ArrayStartEvent, nil, nil},
+				{bari.EOFEvent, nil, bari.ParseError{Message: "unexpected character ] in literal null", Line: 1, Position: 5, Offset: 4, Column: 5, DocumentLine: 1, DocumentPosition: 5, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidCharacter}},
+			},
+		},
+		{
+			`[True]`,
+			[]expectedEvent{
+				{bari.ArrayStartEvent, nil, nil},
+				{bari.EOFEvent, nil, bari.ParseError{Message: "unexpected character T, did you mean true?", Line: 1, Position: 2, Offset: 1, Column: 2, DocumentLine: 1, DocumentPosition: 2, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidCharacter}},
+			},
+		},
+		{
+			`[truex]`,
+			[]expectedEvent{
+				{bari.ArrayStartEvent, nil, nil},
+				{bari.BooleanEvent, true, nil},
+				{bari.EOFEvent, nil, bari.ParseError{Message: "expected , but got x", Line: 1, Position: 6, Offset: 5, Column: 6, DocumentLine: 1, DocumentPosition: 6, Path: "/0", Context: "after array element \"/0\"", Err: bari.ErrInvalidCharacter}},
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		checkEvents(t, parseAll(c.data), c.events)
+	}
+}
+
+func TestWhitespace(t *testing.T) {
+	checkEvents(t, parseAll("{\r\n\"a\" :\t[ ]\n}\r\n[]"), []expectedEvent{
+		{bari.ObjectStartEvent, nil, nil},
+		{bari.ObjectKeyEvent, nil, nil},
+		{bari.StringEvent, "a", nil},
+		{bari.ObjectValueEvent, nil, nil},
+		{bari.ArrayStartEvent, nil, nil},
+		{bari.ArrayEndEvent, nil, nil},
+		{bari.ObjectEndEvent, nil, nil},
+		{bari.ArrayStartEvent, nil, nil},
+		{bari.ArrayEndEvent, nil, nil},
+	})
+
+	testCases := []struct {
+		data string
+		err  bari.ParseError
+	}{
+		{"{\x85\"a\": 1}", bari.ParseError{Message: "expected \" but got 0x85", Line: 1, Position: 2, Offset: 1, Column: 2, DocumentLine: 1, DocumentPosition: 2, Path: "", Context: "in a key of the top-level object", Err: bari.ErrInvalidCharacter}},
+		{"{\xa0\"a\": 1}", bari.ParseError{Message: "expected \" but got 0xa0", Line: 1, Position: 2, Offset: 1, Column: 2, DocumentLine: 1, DocumentPosition: 2, Path: "", Context: "in a key of the top-level object", Err: bari.ErrInvalidCharacter}},
+		{"[1,\f2]", bari.ParseError{Message: "unexpected character 0x0c", Line: 1, Position: 4, Offset: 3, Column: 4, DocumentLine: 1, DocumentPosition: 4, Path: "/1", Context: "in array element \"/1\"", Err: bari.ErrInvalidCharacter}},
+		{"[]\x85[]", bari.ParseError{Message: "unexpected character 0x85", Line: 1, Position: 3, Offset: 2, Column: 3, Document: 1, DocumentLine: 1, DocumentPosition: 1, Err: bari.ErrInvalidCharacter}},
+		{"[]\v[]", bari.ParseError{Message: "unexpected character 0x0b", Line: 1, Position: 3, Offset: 2, Column: 3, Document: 1, DocumentLine: 1, DocumentPosition: 1, Err: bari.ErrInvalidCharacter}},
+	}
+
+	for _, c := range testCases {
+		events := parseAll(c.data)
+		require.Equal(t, c.err, events[len(events)-1].Error, "data: %q", c.data)
+	}
+}
+
+func TestStringControlCharacters(t *testing.T) {
+	testCases := []struct {
+		data string
+		err  bari.ParseError
+	}{
+		{"[\"\nab\"]", bari.ParseError{Message: "invalid control character 0x0a in string", Line: 1, Position: 3, Offset: 2, Column: 3, DocumentLine: 1, DocumentPosition: 3, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidString}},
+		{"[\"a\nb\"]", bari.ParseError{Message: "invalid control character 0x0a in string", Line: 1, Position: 4, Offset: 3, Column: 4, DocumentLine: 1, DocumentPosition: 4, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidString}},
+		{"[\"ab\n\"]", bari.ParseError{Message: "invalid control character 0x0a in string", Line: 1, Position: 5, Offset: 4, Column: 5, DocumentLine: 1, DocumentPosition: 5, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidString}},
+		{"[\"\tab\"]", bari.ParseError{Message: "invalid control character 0x09 in string", Line: 1, Position: 3, Offset: 2, Column: 3, DocumentLine: 1, DocumentPosition: 3, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidString}},
+		{"[\"a\tb\"]", bari.ParseError{Message: "invalid control character 0x09 in string", Line: 1, Position: 4, Offset: 3, Column: 4, DocumentLine: 1, DocumentPosition: 4, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidString}},
+		{"[\"ab\t\"]", bari.ParseError{Message: "invalid control character 0x09 in string", Line: 1, Position: 5, Offset: 4, Column: 5, DocumentLine: 1, DocumentPosition: 5, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidString}},
+		{"[\"\x01ab\"]", bari.ParseError{Message: "invalid control character 0x01 in string", Line: 1, Position: 3, Offset: 2, Column: 3, DocumentLine: 1, DocumentPosition: 3, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidString}},
+		{"[\"a\x01b\"]", bari.ParseError{Message: "invalid control character 0x01 in string", Line: 1, Position: 4, Offset: 3, Column: 4, DocumentLine: 1, DocumentPosition: 4, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidString}},
+		{"[\"ab\x01\"]", bari.ParseError{Message: "invalid control character 0x01 in string", Line: 1, Position: 5, Offset: 4, Column: 5, DocumentLine: 1, DocumentPosition: 5, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidString}},
+		{"{\n\"a\x1fb\": 1}", bari.ParseError{Message: "invalid control character 0x1f in string", Line: 2, Position: 3, Offset: 4, Column: 3, DocumentLine: 2, DocumentPosition: 3, Path: "", Context: "in a key of the top-level object", Err: bari.ErrInvalidString}},
+		{"[\"\\\n\"]", bari.ParseError{Message: "invalid control character 0x0a in string", Line: 1, Position: 4, Offset: 3, Column: 4, DocumentLine: 1, DocumentPosition: 4, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidString}},
+	}
+
+	for _, c := range testCases {
+		events := parseAll(c.data)
+		require.Equal(t, c.err, events[len(events)-1].Error, "data: %q", c.data)
+	}
+
+	// The error doesn't wait for the end of the string.
+	r := io.MultiReader(strings.NewReader("[\"a\n"), &failingReader{data: strings.Repeat("a", 1<<20), err: errors.New("unreachable")})
+	parser := bari.NewParser(r)
+	ch := make(chan bari.Event, 10)
+	go parser.ParseAndClose(ch)
+
+	var err error
+	for ev := range ch {
+		err = ev.Error
+	}
+	require.Equal(t, bari.ParseError{Message: "invalid control character 0x0a in string", Line: 1, Position: 4, Offset: 3, Column: 4, DocumentLine: 1, DocumentPosition: 4, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidString}, err)
+}
+
+func TestUnexpectedRune(t *testing.T) {
+	testCases := []struct {
+		data string
+		err  bari.ParseError
+	}{
+		{"\u00e9", bari.ParseError{Message: "unexpected character \u00e9", Line: 1, Position: 1, Offset: 0, Column: 1, DocumentLine: 1, DocumentPosition: 1, Err: bari.ErrInvalidCharacter}},
+		{"\U0001F600", bari.ParseError{Message: "unexpected character \U0001F600", Line: 1, Position: 1, Offset: 0, Column: 1, DocumentLine: 1, DocumentPosition: 1, Err: bari.ErrInvalidCharacter}},
+		{"\x80", bari.ParseError{Message: "unexpected character 0x80", Line: 1, Position: 1, Offset: 0, Column: 1, DocumentLine: 1, DocumentPosition: 1, Err: bari.ErrInvalidCharacter}},
+		{"{\"a\": \x80}", bari.ParseError{Message: "unexpected character 0x80", Line: 1, Position: 7, Offset: 6, Column: 7, DocumentLine: 1, DocumentPosition: 7, Path: "/a", Context: "in object member \"/a\"", Err: bari.ErrInvalidCharacter}},
+		{"{\u00e9: 1}", bari.ParseError{Message: "expected \" but got \u00e9", Line: 1, Position: 2, Offset: 1, Column: 2, DocumentLine: 1, DocumentPosition: 2, Path: "", Context: "in a key of the top-level object", Err: bari.ErrInvalidCharacter}},
+		{"[1 \U0001F600]", bari.ParseError{Message: "expected , but got \U0001F600", Line: 1, Position: 4, Offset: 3, Column: 4, DocumentLine: 1, DocumentPosition: 4, Path: "/0", Context: "after array element \"/0\"", Err: bari.ErrInvalidCharacter}},
+		{"[\u0085]", bari.ParseError{Message: "unexpected character U+0085", Line: 1, Position: 2, Offset: 1, Column: 2, DocumentLine: 1, DocumentPosition: 2, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidCharacter}},
+		{"[\xe2\x82]", bari.ParseError{Message: "unexpected character 0xe2", Line: 1, Position: 2, Offset: 1, Column: 2, DocumentLine: 1, DocumentPosition: 2, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidCharacter}},
+	}
+
+	for _, c := range testCases {
+		events := parseAll(c.data)
+		require.Equal(t, c.err, events[len(events)-1].Error, "data: %q", c.data)
+	}
+}
+
+func TestStringEscapes(t *testing.T) {
+	checkEvents(t, parseAll(`["\u26A1\u26a1", "\/\b\f\n\r\t"]`), []expectedEvent{
+		{bari.ArrayStartEvent, nil, nil},
+		{bari.StringEvent, "\u26a1\u26a1", nil},
+		{bari.StringEvent, "/\b\f\n\r\t", nil},
+		{bari.ArrayEndEvent, nil, nil},
+	})
+
+	testCases := []struct {
+		data string
+		err  bari.ParseError
+	}{
+		{`["abc\u26"]`, bari.ParseError{Message: `invalid \u escape "\u26" (expected 4 hex digits)`, Line: 1, Position: 6, Offset: 5, Column: 6, DocumentLine: 1, DocumentPosition: 6, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidString}},
+		{`["\uZZZZ"]`, bari.ParseError{Message: `invalid \u escape "\uZZZZ" (expected 4 hex digits)`, Line: 1, Position: 3, Offset: 2, Column: 3, DocumentLine: 1, DocumentPosition: 3, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidString}},
+		{`["\u12G4"]`, bari.ParseError{Message: `invalid \u escape "\u12G4" (expected 4 hex digits)`, Line: 1, Position: 3, Offset: 2, Column: 3, DocumentLine: 1, DocumentPosition: 3, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidString}},
+		{`["a\u"]`, bari.ParseError{Message: `invalid \u escape "\u" (expected 4 hex digits)`, Line: 1, Position: 4, Offset: 3, Column: 4, DocumentLine: 1, DocumentPosition: 4, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidString}},
+		{"{\n\"k\": \"\\x\"}", bari.ParseError{Message: `invalid escape "\x" in string`, Line: 2, Position: 7, Offset: 8, Column: 7, DocumentLine: 2, DocumentPosition: 7, Path: "/k", Context: "in object member \"/k\"", Err: bari.ErrInvalidString}},
+		{`["a\"]`, bari.ParseError{Message: "unexpected end of file", Line: 1, Position: 7, Offset: 6, Column: 7, DocumentLine: 1, DocumentPosition: 7, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrUnexpectedEOF}},
+	}
+
+	for _, c := range testCases {
+		events := parseAll(c.data)
+		require.Equal(t, c.err, events[len(events)-1].Error, "data: %q", c.data)
+	}
+}
+
+func TestErrorPositions(t *testing.T) {
+	testCases := []struct {
+		data     string
+		line     int
+		position int
+		offset   int64
+	}{
+		{"{\n  \"a\": 1,\n  \"b\" 2\n}", 3, 7, 18},
+		{"[\n  1,\n  2\n  3\n]", 4, 3, 13},
+		{"[1\n,\n  x]", 3, 3, 7},
+		{"{\"a\":\n\n   tru}", 3, 7, 13},
+		{"{\n\"a\": 01\n}", 2, 7, 8},
+		{"[\n  \"abc\n\"]", 2, 7, 8},
+		{"{\"a\": [\n  1.5e\n]}", 2, 7, 14},
+		{"[1\n\n  ,]", 3, 4, 7},
+		{"{\n\t\"a\"\n\t:\n\t[true\n\t,\n\tnul]}", 6, 5, 24},
+		{"[\n  1\n", 3, 1, 6},
+	}
+
+	for _, c := range testCases {
+		parser := bari.NewParser(strings.NewReader(c.data), bari.IndexLines())
+		events := parseAllWith(parser)
+
+		var err bari.ParseError
+		require.True(t, errors.As(events[len(events)-1].Error, &err), "data: %q", c.data)
+		require.Equal(t, c.line, err.Line, "data: %q", c.data)
+		require.Equal(t, c.position, err.Position, "data: %q", c.data)
+		require.Equal(t, c.offset, err.Offset, "data: %q", c.data)
+
+		line, col := parser.PositionIndex().LineCol(err.Offset)
+		require.Equal(t, err.Line, line, "data: %q", c.data)
+		require.Equal(t, err.Position, col, "data: %q", c.data)
+	}
+}
+
+func TestEventPositions(t *testing.T) {
+	const data = "{\n  \"name\": \"\u00e9\",\n  \"list\": [1, -2.5,\n    true, null],\n  \"obj\": {}\n}"
+
+	type position struct {
+		typ    bari.EventType
+		line   int
+		column int
+		offset int64
+	}
+	expected := []position{
+		{bari.ObjectStartEvent, 1, 1, 0},
+		{bari.ObjectKeyEvent, 2, 3, 4},
+		{bari.StringEvent, 2, 3, 4},
+		{bari.ObjectValueEvent, 2, 9, 10},
+		{bari.StringEvent, 2, 11, 12},
+		{bari.ObjectKeyEvent, 3, 3, 20},
+		{bari.StringEvent, 3, 3, 20},
+		{bari.ObjectValueEvent, 3, 9, 26},
+		{bari.ArrayStartEvent, 3, 11, 28},
+		{bari.NumberEvent, 3, 12, 29},
+		{bari.NumberEvent, 3, 15, 32},
+		{bari.BooleanEvent, 4, 5, 42},
+		{bari.NullEvent, 4, 11, 48},
+		{bari.ArrayEndEvent, 4, 15, 52},
+		{bari.ObjectKeyEvent, 5, 3, 57},
+		{bari.StringEvent, 5, 3, 57},
+		{bari.ObjectValueEvent, 5, 8, 62},
+		{bari.ObjectStartEvent, 5, 10, 64},
+		{bari.ObjectEndEvent, 5, 11, 65},
+		{bari.ObjectEndEvent, 6, 1, 67},
+	}
+
+	events := parseAll(data, bari.EventPositions())
+	require.Equal(t, len(expected), len(events))
+	for i, ev := range events {
+		require.Equal(t, expected[i], position{ev.Type, ev.Line, ev.Column, ev.Offset}, "event %d", i)
+	}
+
+	// Positions are only set with EventPositions.
+	for _, ev := range parseAll(data) {
+		require.Equal(t, 0, ev.Line)
+	}
+
+	// The EOFEvent is at the position of its error.
+	events = parseAll("[1,\n  x]", bari.EventPositions())
+	last := events[len(events)-1]
+	require.Equal(t, position{bari.EOFEvent, 2, 3, 6}, position{last.Type, last.Line, last.Column, last.Offset})
+}
+
+func TestEventSpans(t *testing.T) {
+	const data = "{\"na\\\"me\": \"\\u00e9\\n\u00e9\", \"list\": [1, -2.5e3 , true,null], \"obj\": {\"a\": []}} [42]"
+
+	type span struct {
+		typ  bari.EventType
+		text string
+	}
+	expected := []span{
+		{bari.ObjectStartEvent, `{`},
+		{bari.ObjectKeyEvent, `"na\"me"`},
+		{bari.StringEvent, `"na\"me"`},
+		{bari.ObjectValueEvent, `:`},
+		{bari.StringEvent, "\"\\u00e9\\n\u00e9\""},
+		{bari.ObjectKeyEvent, `"list"`},
+		{bari.StringEvent, `"list"`},
+		{bari.ObjectValueEvent, `:`},
+		{bari.ArrayStartEvent, `[`},
+		{bari.NumberEvent, `1`},
+		{bari.NumberEvent, `-2.5e3`},
+		{bari.BooleanEvent, `true`},
+		{bari.NullEvent, `null`},
+		{bari.ArrayEndEvent, `[1, -2.5e3 , true,null]`},
+		{bari.ObjectKeyEvent, `"obj"`},
+		{bari.StringEvent, `"obj"`},
+		{bari.ObjectValueEvent, `:`},
+		{bari.ObjectStartEvent, `{`},
+		{bari.ObjectKeyEvent, `"a"`},
+		{bari.StringEvent, `"a"`},
+		{bari.ObjectValueEvent, `:`},
+		{bari.ArrayStartEvent, `[`},
+		{bari.ArrayEndEvent, `[]`},
+		{bari.ObjectEndEvent, `{"a": []}`},
+		{bari.ObjectEndEvent, data[:len(data)-5]},
+		{bari.ArrayStartEvent, `[`},
+		{bari.NumberEvent, `42`},
+		{bari.ArrayEndEvent, `[42]`},
+	}
+	spans := func(events []bari.Event) []span {
+		var spans []span
+		for _, ev := range events {
+			spans = append(spans, span{ev.Type, data[ev.StartOffset:ev.EndOffset]})
+		}
+		return spans
+	}
+
+	// Keys are read differently when duplicates are disallowed, and strings as byte slices.
+	for _, opts := range [][]bari.Option{nil, {bari.DisallowDuplicateKeys()}, {bari.ByteStrings()}} {
+		events := parseAll(data, append(opts, bari.EventPositions())...)
+		require.Equal(t, expected, spans(events))
+	}
+
+	// The key of a compact object spans its string.
+	events := parseAll(`{"a\tb": 1}`, bari.CompactEvents(), bari.EventPositions())
+	require.Equal(t, bari.ObjectKeyEvent, events[1].Type)
+	require.Equal(t, []int64{1, 7}, []int64{events[1].StartOffset, events[1].EndOffset})
+
+	// An error is an empty span at its position.
+	events = parseAll("[1,\n  x]", bari.EventPositions())
+	last := events[len(events)-1]
+	require.Equal(t, []int64{6, 6}, []int64{last.StartOffset, last.EndOffset})
+}
+
+func TestEventDepth(t *testing.T) {
+	type depth struct {
+		typ   bari.EventType
+		depth int
+	}
+	collect := func(events []bari.Event) []depth {
+		var depths []depth
+		for _, ev := range events {
+			depths = append(depths, depth{ev.Type, ev.Depth})
+		}
+		return depths
+	}
+
+	require.Equal(t, []depth{
+		{bari.ObjectStartEvent, 0},
+		{bari.ObjectKeyEvent, 1},
+		{bari.StringEvent, 1},
+		{bari.ObjectValueEvent, 1},
+		{bari.ArrayStartEvent, 1},
+		{bari.ObjectStartEvent, 2},
+		{bari.ObjectKeyEvent, 3},
+		{bari.StringEvent, 3},
+		{bari.ObjectValueEvent, 3},
+		{bari.NumberEvent, 3},
+		{bari.ObjectEndEvent, 2},
+		{bari.NumberEvent, 2},
+		{bari.ArrayEndEvent, 1},
+		{bari.ObjectKeyEvent, 1},
+		{bari.StringEvent, 1},
+		{bari.ObjectValueEvent, 1},
+		{bari.ObjectStartEvent, 1},
+		{bari.ObjectEndEvent, 1},
+		{bari.ObjectEndEvent, 0},
+		{bari.ArrayStartEvent, 0},
+		{bari.ArrayEndEvent, 0},
+	}, collect(parseAll(`{"a": [{"b": 1}, 2], "c": {}} []`)))
+
+	// The events of an expanded string are nested where the string is.
+	require.Equal(t, []depth{
+		{bari.ArrayStartEvent, 0},
+		{bari.ArrayStartEvent, 1},
+		{bari.NumberEvent, 2},
+		{bari.ArrayEndEvent, 1},
+		{bari.ArrayEndEvent, 0},
+	}, collect(parseAll(`["[1]"]`, bari.ExpandStringifiedJSON(1))))
+
+	// The EOFEvent is not nested.
+	events := parseAll(`[[x]]`)
+	require.Equal(t, []depth{
+		{bari.ArrayStartEvent, 0},
+		{bari.ArrayStartEvent, 1},
+		{bari.EOFEvent, 0},
+	}, collect(events))
+}
+
+func TestContainerCounts(t *testing.T) {
+	counts := func(events []bari.Event) []int {
+		var c []int
+		for _, ev := range events {
+			if ev.IsContainerEnd() {
+				c = append(c, ev.Count)
+			}
+		}
+		return c
+	}
+
+	testCases := []struct {
+		data   string
+		opts   []bari.Option
+		counts []int
+	}{
+		{`{}`, nil, []int{0}},
+		{`[]`, nil, []int{0}},
+		{`[{}, []]`, nil, []int{0, 0, 2}},
+		{`{"a": 1, "b": [1, [2, 3], {"c": null}], "d": {}}`, nil, []int{2, 1, 3, 0, 3}},
+		{`[1, 2] {"a": "b"}`, nil, []int{2, 1}},
+		{`{"a": 1, "b": [1, 2]}`, []bari.Option{bari.CompactEvents()}, []int{2, 2}},
+		{`{"a": "[1, 2, 3]", "b": 2}`, []bari.Option{bari.ExpandStringifiedJSON(1)}, []int{3, 2}},
+		// The end events of a container which isn't closed are never emitted.
+		{`[1, [2, 3], {"a": 1, "b": `, nil, []int{2}},
+		{`[1, x, 3]`, []bari.Option{bari.SkipInvalidValues()}, []int{3}},
+		{`[1 2, 3]`, []bari.Option{bari.CollectErrors()}, []int{3}},
+		{`{"a": 1 "b": 2, "c": 3,}`, []bari.Option{bari.CollectErrors()}, []int{3}},
+	}
+	for _, c := range testCases {
+		require.Equal(t, c.counts, counts(parseAll(c.data, c.opts...)), "data: %s", c.data)
+	}
+
+	// A skipped value still counts.
+	parser := bari.NewParser(strings.NewReader(`[1, {"a": [1, 2]}, 3]`))
+	for i := 0; i < 2; i++ {
+		_, err := parser.Next()
+		require.NoError(t, err)
+	}
+	require.NoError(t, parser.SkipValue())
+	require.Equal(t, []int{3}, counts(parseAllWith(parser)))
+}
+
+func TestArrayIndex(t *testing.T) {
+	parser := bari.NewParser(strings.NewReader(`[1, {"a": [true, false], "b": 2}, [[]], "x"] [3]`))
+
+	var indices []int
+	for {
+		_, err := parser.Next()
+		if err != nil {
+			require.Equal(t, io.EOF, err)
+			break
+		}
+
+		i, ok := parser.ArrayIndex()
+		if !ok {
+			i = -1
+		}
+		indices = append(indices, i)
+	}
+
+	// The events of [1, {"a": [true, false], "b": 2}, [[]], "x"], then of [3].
+	require.Equal(t, []int{
+		-1, 0,
+		1, 1, 1, 1, 1, 0, 1, 1,
+		1, 1, 1, 1, 1,
+		2, 0, 0, 2,
+		3, -1,
+		-1, 0, -1,
+	}, indices)
+}
+
+func TestTypedValues(t *testing.T) {
+	const data = `["a", 10, 1.5, 1e2, true, false, null, 18446744073709551616, {}]`
+
+	type typed struct {
+		kind  bari.ValueKind
+		str   string
+		i     int64
+		f     float64
+		b     bool
+		value interface{}
+	}
+	collect := func(events []bari.Event) []typed {
+		var values []typed
+		for _, ev := range events {
+			values = append(values, typed{ev.ValueKind, ev.Str, ev.Int, ev.Float, ev.Bool, ev.Value})
+		}
+		return values
+	}
+
+	n, _ := new(big.Int).SetString("18446744073709551616", 10)
+	expected := []typed{
+		{kind: bari.NoValue},
+		{kind: bari.StringValue, str: "a", value: "a"},
+		{kind: bari.IntValue, i: 10, value: int64(10)},
+		{kind: bari.FloatValue, f: 1.5, value: 1.5},
+		{kind: bari.FloatValue, f: 100, value: float64(100)},
+		{kind: bari.BoolValue, b: true, value: true},
+		{kind: bari.BoolValue, value: false},
+		{kind: bari.NullValue},
+		{kind: bari.NoValue, value: n},
+		{kind: bari.NoValue},
+		{kind: bari.NoValue},
+		{kind: bari.NoValue},
+	}
+	require.Equal(t, expected, collect(parseAll(data)))
+
+	// Only the values without a typed field are in Value.
+	for i := range expected {
+		if expected[i].kind != bari.BoolValue {
+			expected[i].value = nil
+		}
+	}
+	expected[8].value = n
+	require.Equal(t, expected, collect(parseAll(data, bari.TypedValues())))
+
+	events := parseAll(`{"a": 1}`, bari.TypedValues())
+	require.Equal(t, "a", events[2].Str)
+	require.Nil(t, events[2].Value)
+
+	// An integer converted to a float64 has a typed value.
+	events = parseAll(`[18446744073709551616]`, bari.TypedValues(), bari.IntegerOverflow(bari.OverflowFloat))
+	require.Equal(t, typed{kind: bari.FloatValue, f: 18446744073709551616}, collect(events)[1])
+	require.True(t, events[1].IsInt)
+}
+
+func TestEventAccessors(t *testing.T) {
+	events := parseAll(`["a", 10, 1.5, true, null]`, bari.TypedValues())
+
+	s, ok := events[1].Text()
+	require.True(t, ok)
+	require.Equal(t, "a", s)
+	i, ok := events[2].Int64()
+	require.True(t, ok)
+	require.Equal(t, int64(10), i)
+	f, ok := events[3].Float64()
+	require.True(t, ok)
+	require.Equal(t, 1.5, f)
+	b, ok := events[4].Boolean()
+	require.True(t, ok)
+	require.True(t, b)
+
+	for _, ev := range events {
+		if ev.Type == bari.NumberEvent {
+			continue
+		}
+		_, ok := ev.Int64()
+		require.False(t, ok, "%v", ev.Type)
+		_, ok = ev.Float64()
+		require.False(t, ok, "%v", ev.Type)
+	}
+	f, ok = events[2].Float64()
+	require.True(t, ok)
+	require.Equal(t, 10.0, f)
+	_, ok = events[3].Int64()
+	require.False(t, ok)
+	_, ok = events[5].Boolean()
+	require.False(t, ok)
+	_, ok = events[5].Text()
+	require.False(t, ok)
+
+	// An event without a ValueKind is read from its Value.
+	s, ok = bari.Event{Type: bari.StringEvent, Value: "b"}.Text()
+	require.True(t, ok)
+	require.Equal(t, "b", s)
+	i, ok = bari.Event{Type: bari.NumberEvent, Value: int64(2)}.Int64()
+	require.True(t, ok)
+	require.Equal(t, int64(2), i)
+	_, ok = bari.Event{Type: bari.SkippedEvent, Value: "x"}.Text()
+	require.False(t, ok)
+}
+
+func TestEventNumberCoercions(t *testing.T) {
+	testCases := []struct {
+		data string
+		opts []bari.Option
+		i    int64
+		iok  bool
+		f    float64
+		fok  bool
+	}{
+		{`[10]`, nil, 10, true, 10, true},
+		{`[-10]`, []bari.Option{bari.TypedValues()}, -10, true, -10, true},
+		{`[9007199254740993]`, nil, 9007199254740993, true, 9007199254740992, true},
+		{`[1e3]`, nil, 1000, true, 1000, true},
+		{`[10.0]`, []bari.Option{bari.TypedValues()}, 10, true, 10, true},
+		{`[1.5]`, nil, 0, false, 1.5, true},
+		{`[-0.5]`, nil, 0, false, -0.5, true},
+		{`[1e19]`, nil, 0, false, 1e19, true},
+		{`[-9223372036854775808.0]`, nil, math.MinInt64, true, -9223372036854775808, true},
+		{`[9223372036854775808.0]`, nil, 0, false, 9223372036854775808, true},
+		{`[18446744073709551616]`, nil, 0, false, 0, false},
+		{`[18446744073709551616]`, []bari.Option{bari.IntegerOverflow(bari.OverflowFloat)}, 0, false, 18446744073709551616, true},
+	}
+
+	for _, c := range testCases {
+		ev := parseAll(c.data, c.opts...)[1]
+		i, ok := ev.Int64()
+		require.Equal(t, c.iok, ok, c.data)
+		require.Equal(t, c.i, i, c.data)
+		f, ok := ev.Float64()
+		require.Equal(t, c.fok, ok, c.data)
+		require.Equal(t, c.f, f, c.data)
+	}
+}
+
+func TestEventKind(t *testing.T) {
+	testCases := []struct {
+		ev     bari.Event
+		kind   bari.EventKind
+		scalar bool
+		start  bool
+		end    bool
+	}{
+		{bari.Event{Type: bari.UnknownEvent}, bari.OtherKind, false, false, false},
+		{bari.Event{Type: bari.ObjectStartEvent}, bari.StartObjectKind, false, true, false},
+		{bari.Event{Type: bari.ObjectKeyEvent}, bari.KeyKind, false, false, false},
+		{bari.Event{Type: bari.ObjectKeyEvent, Value: "a"}, bari.KeyKind, false, false, false},
+		{bari.Event{Type: bari.ObjectValueEvent}, bari.OtherKind, false, false, false},
+		{bari.Event{Type: bari.ObjectEndEvent}, bari.EndObjectKind, false, false, true},
+		{bari.Event{Type: bari.ArrayStartEvent}, bari.StartArrayKind, false, true, false},
+		{bari.Event{Type: bari.ArrayEndEvent}, bari.EndArrayKind, false, false, true},
+		{bari.Event{Type: bari.StringEvent, Value: "a"}, bari.ScalarKind, true, false, false},
+		{bari.Event{Type: bari.StringEvent, ValueKind: bari.BytesValue, Bytes: []byte("a")}, bari.ScalarKind, true, false, false},
+		{bari.Event{Type: bari.NumberEvent, Value: int64(1)}, bari.ScalarKind, true, false, false},
+		{bari.Event{Type: bari.NumberEvent, ValueKind: bari.FloatValue, Float: 1.5}, bari.ScalarKind, true, false, false},
+		{bari.Event{Type: bari.NumberEvent, Value: uint64(1 << 63)}, bari.ScalarKind, true, false, false},
+		{bari.Event{Type: bari.BooleanEvent, Value: true}, bari.ScalarKind, true, false, false},
+		{bari.Event{Type: bari.NullEvent}, bari.ScalarKind, true, false, false},
+		{bari.Event{Type: bari.EOFEvent}, bari.EOFKind, false, false, false},
+		{bari.Event{Type: bari.EOFEvent, Error: bari.ErrUnexpectedEOF}, bari.EOFKind, false, false, false},
+		{bari.Event{Type: bari.ErrorEvent, Error: bari.ErrInvalidString}, bari.OtherKind, false, false, false},
+		{bari.Event{Type: bari.SkippedEvent, Value: "x"}, bari.OtherKind, false, false, false},
+		{bari.Event{Type: bari.DocumentStartEvent}, bari.OtherKind, false, false, false},
+		{bari.Event{Type: bari.DocumentEndEvent}, bari.OtherKind, false, false, false},
+	}
+
+	for _, c := range testCases {
+		require.Equal(t, c.kind, c.ev.Kind(), "%v", c.ev)
+		require.Equal(t, c.scalar, c.ev.IsScalar(), "%v", c.ev)
+		require.Equal(t, c.start, c.ev.IsContainerStart(), "%v", c.ev)
+		require.Equal(t, c.end, c.ev.IsContainerEnd(), "%v", c.ev)
+	}
+}
+
+func TestEventString(t *testing.T) {
+	events := parseAll(`{"a": ["s\"", 10, 1.5, true, null, 18446744073709551616]}`)
+	parser := bari.NewParser(strings.NewReader(`[`))
+	_, err := parser.Next()
+	require.NoError(t, err)
+	_, err = parser.Next()
+	collected := parseAll(`["\x", 1]`, bari.CollectErrors())
+	skipped := parseAll(`[1, x, 2]`, bari.SkipInvalidValues())
+	quote := func(s string) string {
+		data, _ := json.Marshal(s)
+		return string(data)
+	}
+
+	testCases := []struct {
+		ev   bari.Event
+		str  string
+		json string
+	}{
+		{bari.Event{}, `UnknownEvent`, `{"type":"UnknownEvent"}`},
+		{events[0], `ObjectStartEvent`, `{"type":"ObjectStartEvent"}`},
+		{events[1], `ObjectKeyEvent`, `{"type":"ObjectKeyEvent"}`},
+		{events[2], `StringEvent("a")`, `{"type":"StringEvent","value":"a"}`},
+		{events[3], `ObjectValueEvent`, `{"type":"ObjectValueEvent"}`},
+		{events[4], `ArrayStartEvent`, `{"type":"ArrayStartEvent"}`},
+		{events[5], `StringEvent("s\"")`, `{"type":"StringEvent","value":"s\""}`},
+		{events[6], `NumberEvent(10)`, `{"type":"NumberEvent","value":10}`},
+		{events[7], `NumberEvent(1.5)`, `{"type":"NumberEvent","value":1.5}`},
+		{events[8], `BooleanEvent(true)`, `{"type":"BooleanEvent","value":true}`},
+		{events[9], `NullEvent`, `{"type":"NullEvent"}`},
+		{events[10], `NumberEvent(18446744073709551616)`, `{"type":"NumberEvent","value":18446744073709551616}`},
+		{events[11], `ArrayEndEvent`, `{"type":"ArrayEndEvent"}`},
+		{events[12], `ObjectEndEvent`, `{"type":"ObjectEndEvent"}`},
+		{bari.Event{Type: bari.DocumentStartEvent, Document: 1}, `DocumentStartEvent`, `{"type":"DocumentStartEvent"}`},
+		{bari.Event{Type: bari.DocumentEndEvent, Document: 1}, `DocumentEndEvent`, `{"type":"DocumentEndEvent"}`},
+		{bari.Event{Type: bari.EOFEvent}, `EOFEvent`, `{"type":"EOFEvent"}`},
+		{
+			bari.Event{Type: bari.EOFEvent, Error: err},
+			`EOFEvent(err: unexpected end of file @1:2)`,
+			`{"type":"EOFEvent","error":` + quote(err.Error()) + `}`,
+		},
+		{
+			collected[1],
+			`ErrorEvent(err: invalid escape "\x" in string @1:3)`,
+			`{"type":"ErrorEvent","error":` + quote(collected[1].Error.Error()) + `}`,
+		},
+		{
+			skipped[2],
+			`SkippedEvent("x", err: unexpected character x @1:5)`,
+			`{"type":"SkippedEvent","value":"x","error":` + quote(skipped[2].Error.Error()) + `}`,
+		},
+		{bari.Event{Type: bari.EOFEvent, Error: io.ErrClosedPipe}, `EOFEvent(err: io: read/write on closed pipe)`, `{"type":"EOFEvent","error":"io: read/write on closed pipe"}`},
+	}
+	for _, c := range testCases {
+		require.Equal(t, c.str, c.ev.String())
+
+		data, err := json.Marshal(c.ev)
+		require.NoError(t, err)
+		require.Equal(t, c.json, string(data))
+	}
+
+	// The typed fields give the same result as Value.
+	for _, opts := range [][]bari.Option{{bari.TypedValues()}, {bari.ByteStrings()}} {
+		typed := parseAll(`{"a": ["s\"", 10, 1.5, true, null, 18446744073709551616]}`, opts...)
+		for i, ev := range typed {
+			require.Equal(t, events[i].String(), ev.String())
+		}
+	}
+
+	data, merr := json.Marshal(err)
+	require.NoError(t, merr)
+	require.Equal(t, `{"message":"unexpected end of file","line":1,"position":2,"offset":1}`, string(data))
+}
+
+func TestCompactEvents(t *testing.T) {
+	events := parseAll(`{"a": 1, "b": {"c": [true, {"d": null}], "e": {}}, "f": []} {"": "x"}`, bari.CompactEvents())
+	checkEvents(t, events, []expectedEvent{
+		{typ: bari.ObjectStartEvent},
+		{typ: bari.ObjectKeyEvent, value: "a"},
+		{typ: bari.NumberEvent, value: int64(1)},
+		{typ: bari.ObjectKeyEvent, value: "b"},
+		{typ: bari.ObjectStartEvent},
+		{typ: bari.ObjectKeyEvent, value: "c"},
+		{typ: bari.ArrayStartEvent},
+		{typ: bari.BooleanEvent, value: true},
+		{typ: bari.ObjectStartEvent},
+		{typ: bari.ObjectKeyEvent, value: "d"},
+		{typ: bari.NullEvent},
+		{typ: bari.ObjectEndEvent},
+		{typ: bari.ArrayEndEvent},
+		{typ: bari.ObjectKeyEvent, value: "e"},
+		{typ: bari.ObjectStartEvent},
+		{typ: bari.ObjectEndEvent},
+		{typ: bari.ObjectEndEvent},
+		{typ: bari.ObjectKeyEvent, value: "f"},
+		{typ: bari.ArrayStartEvent},
+		{typ: bari.ArrayEndEvent},
+		{typ: bari.ObjectEndEvent},
+		{typ: bari.ObjectStartEvent},
+		{typ: bari.ObjectKeyEvent, value: ""},
+		{typ: bari.StringEvent, value: "x"},
+		{typ: bari.ObjectEndEvent},
+	})
+	require.Equal(t, 1, events[1].Depth)
+	require.Equal(t, 2, events[5].Depth)
+
+	// A key is at its quote.
+	events = parseAll(`{"a": {"b": 1}}`, bari.CompactEvents(), bari.EventPositions())
+	require.Equal(t, bari.ObjectKeyEvent, events[3].Type)
+	require.Equal(t, 8, events[3].Column)
+
+	// The events of an expanded string are compacted too.
+	events = parseAll(`{"a": "{\"b\": 1}"}`, bari.CompactEvents(), bari.ExpandStringifiedJSON(1))
+	checkEvents(t, events, []expectedEvent{
+		{typ: bari.ObjectStartEvent},
+		{typ: bari.ObjectKeyEvent, value: "a"},
+		{typ: bari.ObjectStartEvent},
+		{typ: bari.ObjectKeyEvent, value: "b"},
+		{typ: bari.NumberEvent, value: int64(1)},
+		{typ: bari.ObjectEndEvent},
+		{typ: bari.ObjectEndEvent},
+	})
+
+	// Errors locate the keys as usual.
+	events = parseAll(`{"a": {"b": x}}`, bari.CompactEvents())
+	last := events[len(events)-1]
+	require.Equal(t, bari.EOFEvent, last.Type)
+	var perr bari.ParseError
+	require.True(t, errors.As(last.Error, &perr))
+	require.Equal(t, "/a/b", perr.Path)
+
+	parser := bari.NewParser(strings.NewReader(`{"a": {"b": [1]}}`), bari.CompactEvents())
+	var paths []string
+	for {
+		_, err := parser.Next()
+		if err != nil {
+			break
+		}
+		paths = append(paths, parser.Path())
+	}
+	require.Equal(t, []string{"", "/a", "/a", "/a/b", "/a/b", "/a/b/0", "/a/b", "/a", ""}, paths)
+}
+
+func TestByteStrings(t *testing.T) {
+	const data = `{"a": "x\"y", "b\u00e9": ["c", "d\u00e9"], "": ""}`
+
+	expected := []expectedEvent{
+		{typ: bari.ObjectStartEvent},
+		{typ: bari.ObjectKeyEvent},
+		{typ: bari.StringEvent, value: "a"},
+		{typ: bari.ObjectValueEvent},
+		{typ: bari.StringEvent, value: "x\"y"},
+		{typ: bari.ObjectKeyEvent},
+		{typ: bari.StringEvent, value: "bé"},
+		{typ: bari.ObjectValueEvent},
+		{typ: bari.ArrayStartEvent},
+		{typ: bari.StringEvent, value: "c"},
+		{typ: bari.StringEvent, value: "dé"},
+		{typ: bari.ArrayEndEvent},
+		{typ: bari.ObjectKeyEvent},
+		{typ: bari.StringEvent, value: ""},
+		{typ: bari.ObjectValueEvent},
+		{typ: bari.StringEvent, value: ""},
+		{typ: bari.ObjectEndEvent},
+	}
+	decoded := func(events []bari.Event) []bari.Event {
+		for i, ev := range events {
+			if ev.Type == bari.StringEvent {
+				require.Equal(t, bari.BytesValue, ev.ValueKind)
+				require.Nil(t, ev.Value)
+				events[i].Value, _ = ev.Text()
+			}
+		}
+		return events
+	}
+
+	// The events sent by Parse are copies.
+	checkEvents(t, decoded(parseAll(data, bari.ByteStrings())), expected)
+
+	for _, parser := range []*bari.Parser{
+		bari.NewParser(strings.NewReader(data), bari.ByteStrings()),
+		bari.NewBytesParser([]byte(data), bari.ByteStrings()),
+	} {
+		var copies []bari.Event
+		err := parser.ParseFunc(func(ev bari.Event) bool {
+			copies = append(copies, ev.Copy())
+			return true
+		})
+		require.Nil(t, err)
+		require.Equal(t, bari.Event{Type: bari.EOFEvent}, copies[len(copies)-1])
+		checkEvents(t, decoded(copies[:len(copies)-1]), expected)
+	}
+
+	// The bytes are reused once the event was returned.
+	parser := bari.NewParser(strings.NewReader(`["aaa", "bbb"]`), bari.ByteStrings())
+	_, err := parser.Next()
+	require.Nil(t, err)
+	ev, err := parser.Next()
+	require.Nil(t, err)
+	retained := ev.Copy()
+	_, err = parser.Next()
+	require.Nil(t, err)
+	require.Equal(t, "bbb", string(ev.Bytes))
+	require.Equal(t, "aaa", string(retained.Bytes))
+
+	// The strings without escape sequences of a byte slice are slices of it.
+	input := []byte(`["abc", "d\n"]`)
+	parser = bari.NewBytesParser(input, bari.ByteStrings())
+	events := nextAll(t, parser)
+	require.Equal(t, "abc", string(events[1].Bytes))
+	require.True(t, &events[1].Bytes[0] == &input[2])
+	require.Equal(t, "d\n", string(events[2].Bytes))
+	require.False(t, &events[2].Bytes[0] == &input[9])
+
+	// Keys are tracked and checked like strings.
+	parser = bari.NewBytesParser([]byte(`{"a": {"b\/": 1}}`), bari.ByteStrings())
+	var paths []string
+	require.Nil(t, parser.ParseFunc(func(ev bari.Event) bool {
+		paths = append(paths, parser.Path())
+		return true
+	}))
+	require.Equal(t, "/a/b~1", paths[8])
+
+	events = parseAll(`{"a": 1, "a": 2}`, bari.ByteStrings(), bari.DisallowDuplicateKeys())
+	require.Equal(t, bari.EOFEvent, events[len(events)-1].Type)
+	require.Contains(t, events[len(events)-1].Error.Error(), `duplicate key "a"`)
+
+	events = parseAll(`{"a": "[\"b\"]"}`, bari.ByteStrings(), bari.ExpandStringifiedJSON(1), bari.CompactEvents())
+	checkEvents(t, decoded(events), []expectedEvent{
+		{typ: bari.ObjectStartEvent},
+		{typ: bari.ObjectKeyEvent},
+		{typ: bari.ArrayStartEvent},
+		{typ: bari.StringEvent, value: "b"},
+		{typ: bari.ArrayEndEvent},
+		{typ: bari.ObjectEndEvent},
+	})
+	key, _ := events[1].Text()
+	require.Equal(t, "a", key)
+}
+
+func TestSkipValue(t *testing.T) {
+	types := func(parser *bari.Parser) []bari.EventType {
+		var types []bari.EventType
+		for _, ev := range nextAll(t, parser) {
+			types = append(types, ev.Type)
+		}
+		return types
+	}
+	next := func(parser *bari.Parser, n int) {
+		for i := 0; i < n; i++ {
+			_, err := parser.Next()
+			require.Nil(t, err)
+		}
+	}
+
+	// Strings may hold brackets and escaped quotes.
+	const data = `{"skip": {"a": "x]}\"y", "b": [1, {"c": "[{\\"}]}, "keep": 1}`
+	for _, r := range []io.Reader{strings.NewReader(data), iotest.OneByteReader(strings.NewReader(data))} {
+		parser := bari.NewParser(r)
+		next(parser, 4)
+		require.Nil(t, parser.SkipValue())
+		require.Equal(t, "/skip", parser.Path())
+		require.Equal(t, []bari.EventType{
+			bari.ObjectKeyEvent, bari.StringEvent, bari.ObjectValueEvent, bari.NumberEvent, bari.ObjectEndEvent,
+		}, types(parser))
+	}
+
+	parser := bari.NewBytesParser([]byte(`[1, "a\"]", [[]], true]`))
+	next(parser, 1)
+	require.Nil(t, parser.SkipValue())
+	require.Nil(t, parser.SkipValue())
+	ev, err := parser.Next()
+	require.Nil(t, err)
+	require.Equal(t, bari.ArrayStartEvent, ev.Type)
+	require.Equal(t, "/2", parser.Path())
+	require.Nil(t, parser.SkipValue())
+	require.Equal(t, []bari.EventType{bari.ArrayEndEvent, bari.BooleanEvent, bari.ArrayEndEvent}, types(parser))
+
+	// A peeked value is skipped too.
+	parser = bari.NewParser(strings.NewReader(`[{"a": 1}, 2]`))
+	next(parser, 1)
+	ev, err = parser.Peek()
+	require.Nil(t, err)
+	require.Equal(t, bari.ObjectStartEvent, ev.Type)
+	require.Nil(t, parser.SkipValue())
+	require.Equal(t, []bari.EventType{bari.NumberEvent, bari.ArrayEndEvent}, types(parser))
+
+	// Documents can be skipped.
+	parser = bari.NewParser(strings.NewReader(`{"a": [1]} [[]] [2]`))
+	require.Nil(t, parser.SkipValue())
+	require.Nil(t, parser.SkipValue())
+	require.Equal(t, []bari.EventType{bari.ArrayStartEvent, bari.NumberEvent, bari.ArrayEndEvent}, types(parser))
+	require.Equal(t, io.EOF, parser.SkipValue())
+
+	// Only values are skipped.
+	parser = bari.NewParser(strings.NewReader(`{"a": []}`))
+	next(parser, 1)
+	require.Equal(t, bari.ErrNoValue, parser.SkipValue())
+	next(parser, 1)
+	require.Equal(t, bari.ErrNoValue, parser.SkipValue())
+	next(parser, 3)
+	require.Equal(t, bari.ErrNoValue, parser.SkipValue())
+	require.Equal(t, []bari.EventType{bari.ArrayEndEvent, bari.ObjectEndEvent}, types(parser))
+
+	parser = bari.NewParser(strings.NewReader(`{"a": 1, "b": {"c": 2}}`), bari.CompactEvents())
+	err = parser.ParseFunc(func(ev bari.Event) bool {
+		if key, _ := ev.Text(); ev.Type == bari.ObjectKeyEvent && key == "b" {
+			require.Nil(t, parser.SkipValue())
+		}
+		return true
+	})
+	require.Nil(t, err)
+
+	// Errors are reported where they are found.
+	for _, c := range []struct {
+		data         string
+		line, column int
+	}{
+		{"{\"skip\": [1,\n  \"a]]]}}", 2, 10},
+		{"{\"skip\": [1, {\"a\": [}]}", 1, 21},
+		{"{\"skip\": [\"a\tb\"]}", 1, 13},
+		{"{\"skip\": [[[[1]]]]}", 1, 13},
+	} {
+		parser = bari.NewParser(strings.NewReader(c.data), bari.MaxDepth(4))
+		next(parser, 4)
+		err = parser.SkipValue()
+		var perr bari.ParseError
+		require.True(t, errors.As(err, &perr), "%s: %v", c.data, err)
+		require.Equal(t, c.line, perr.Line, c.data)
+		require.Equal(t, c.column, perr.Column, c.data)
+
+		ev, nextErr := parser.Next()
+		require.Equal(t, bari.EOFEvent, ev.Type)
+		require.Equal(t, err, nextErr)
+	}
+
+	// The positions of the input are kept up to date.
+	parser = bari.NewBytesParser([]byte("{\"skip\": [\n\"x\\\"]\",\n{\"é\": []}],\n \"b\": tru}"), bari.EventPositions())
+	next(parser, 4)
+	require.Nil(t, parser.SkipValue())
+	ev, err = parser.Next()
+	require.Nil(t, err)
+	require.Equal(t, bari.ObjectKeyEvent, ev.Type)
+	require.Equal(t, []int{4, 2, 33}, []int{ev.Line, ev.Column, int(ev.Offset)})
+	next(parser, 2)
+	_, err = parser.Next()
+	var perr bari.ParseError
+	require.True(t, errors.As(err, &perr))
+	require.Equal(t, []int{4, 10}, []int{perr.Line, perr.Column})
+
+	// The errors recovered from in a scalar are kept.
+	parser = bari.NewParser(strings.NewReader(`["\x", 1]`), bari.CollectErrors())
+	next(parser, 1)
+	require.Nil(t, parser.SkipValue())
+	require.Equal(t, []bari.EventType{bari.ErrorEvent, bari.NumberEvent, bari.ArrayEndEvent}, types(parser))
+}
+
+func TestPath(t *testing.T) {
+	parser := bari.NewParser(strings.NewReader(`{"items": [{"name": "x", "a/b": 1, "m~n": [true], "": null}], "e": {}} [1]`))
+	require.Equal(t, "", parser.Path())
+
+	expected := []string{
+		"",                // {
+		"",                // ObjectKeyEvent
+		"/items",          // "items"
+		"/items",          // :
+		"/items",          // [
+		"/items/0",        // {
+		"/items/0",        // ObjectKeyEvent
+		"/items/0/name",   // "name"
+		"/items/0/name",   // :
+		"/items/0/name",   // "x"
+		"/items/0",        // ObjectKeyEvent
+		"/items/0/a~1b",   // "a/b"
+		"/items/0/a~1b",   // :
+		"/items/0/a~1b",   // 1
+		"/items/0",        // ObjectKeyEvent
+		"/items/0/m~0n",   // "m~n"
+		"/items/0/m~0n",   // :
+		"/items/0/m~0n",   // [
+		"/items/0/m~0n/0", // true
+		"/items/0/m~0n",   // ]
+		"/items/0",        // ObjectKeyEvent
+		"/items/0/",       // ""
+		"/items/0/",       // :
+		"/items/0/",       // null
+		"/items/0",        // }
+		"/items",          // ]
+		"",                // ObjectKeyEvent
+		"/e",              // "e"
+		"/e",              // :
+		"/e",              // {
+		"/e",              // }
+		"",                // }
+		"",                // [
+		"/0",              // 1
+		"",                // ]
+	}
+
+	var paths []string
+	for {
+		_, err := parser.Next()
+		if err == io.EOF {
+			break
+		}
+		require.Nil(t, err)
+		paths = append(paths, parser.Path())
+	}
+	require.Equal(t, expected, paths)
+
+	// The path follows the events returned, not the parser which runs ahead of them.
+	parser = bari.NewParser(strings.NewReader(`{"a": {"b": [1, 2]}}`))
+	for i := 0; i < 5; i++ {
+		parser.Next()
+	}
+	_, err := parser.Peek()
+	require.Nil(t, err)
+	require.Equal(t, "/a", parser.Path())
+
+	var callbackPaths []string
+	parser.ParseFunc(func(ev bari.Event) bool {
+		if ev.Type == bari.NumberEvent {
+			callbackPaths = append(callbackPaths, parser.Path())
+		}
+		return true
+	})
+	require.Equal(t, []string{"/a/b/0", "/a/b/1"}, callbackPaths)
+}
+
+func TestErrorCategories(t *testing.T) {
+	categories := []error{bari.ErrUnexpectedEOF, bari.ErrInvalidCharacter, bari.ErrInvalidNumber, bari.ErrInvalidString, bari.ErrDuplicateKey}
+
+	// Each error of the invalid test cases is in exactly one category.
+	for _, c := range testCases {
+		last := c.events[len(c.events)-1]
+		if last.err == nil {
+			continue
+		}
+
+		events := parseAll(c.data)
+		err := events[len(events)-1].Error
+		var n int
+		for _, category := range categories {
+			if errors.Is(err, category) {
+				n++
+			}
+		}
+		require.Equal(t, 1, n, "data: %q, error: %v", c.data, err)
+	}
+
+	testCases := []struct {
+		data string
+		opts []bari.Option
+		err  error
+	}{
+		{`{"a": 1`, nil, bari.ErrUnexpectedEOF},
+		{`{f}`, nil, bari.ErrInvalidCharacter},
+		{`[1 2]`, nil, bari.ErrInvalidCharacter},
+		{`{"a" 1}`, nil, bari.ErrInvalidCharacter},
+		{`[tru]`, nil, bari.ErrInvalidCharacter},
+		{`{} x`, []bari.Option{bari.SingleDocument()}, bari.ErrInvalidCharacter},
+		{`[01]`, nil, bari.ErrInvalidNumber},
+		{`[1.e5]`, nil, bari.ErrInvalidNumber},
+		{`["\q"]`, nil, bari.ErrInvalidString},
+		{"[\"a\nb\"]", nil, bari.ErrInvalidString},
+		{`["\ud800"]`, []bari.Option{bari.RejectLoneSurrogates()}, bari.ErrInvalidString},
+		{"[\"\xc0\"]", []bari.Option{bari.DisallowInvalidUTF8()}, bari.ErrInvalidString},
+		{`{"a": 1, "a": 2}`, []bari.Option{bari.DisallowDuplicateKeys()}, bari.ErrDuplicateKey},
+	}
+	for _, c := range testCases {
+		events := parseAll(c.data, c.opts...)
+		err := events[len(events)-1].Error
+		require.True(t, errors.Is(err, c.err), "data: %q, error: %v", c.data, err)
+	}
+
+	// A number which can't be converted isn't malformed.
+	events := parseAll(`[99999999999999999999]`, bari.IntegerOverflow(bari.OverflowError))
+	require.False(t, errors.Is(events[len(events)-1].Error, bari.ErrInvalidNumber))
+}
+
+func TestErrorColumns(t *testing.T) {
+	testCases := []struct {
+		data     string
+		position int
+		column   int
+	}{
+		{`{"naïve": tru}`, 15, 14},
+		{`{"€": tru}`, 12, 10},
+		{`{"😀": tru}`, 13, 10},
+		{"{\"a\": \"é€😀\", \"b\": x}", 25, 19},
+		{`{"\u00e9": tru}`, 15, 15},
+		{"[\"é\n\"]", 5, 4},
+		{`["é€😀\q"]`, 12, 6},
+		{`{"é": 1.5e}`, 12, 11},
+		{"{\"é\":\n \"ü\" 1}", 7, 6},
+	}
+
+	for _, c := range testCases {
+		for _, r := range []io.Reader{strings.NewReader(c.data), iotest.OneByteReader(strings.NewReader(c.data))} {
+			events := parseAllWith(bari.NewParser(r))
+
+			var err bari.ParseError
+			require.True(t, errors.As(events[len(events)-1].Error, &err), "data: %q", c.data)
+			require.Equal(t, c.position, err.Position, "data: %q", c.data)
+			require.Equal(t, c.column, err.Column, "data: %q", c.data)
+		}
+	}
+}
+
+func TestKeyNotString(t *testing.T) {
+	testCases := []struct {
+		data     string
+		message  string
+		position int
+	}{
+		{`{123: "x"}`, "object keys must be strings, got number", 2},
+		{`{"a": 1, -1: "x"}`, "object keys must be strings, got number", 10},
+		{`{true: 1}`, "object keys must be strings, got boolean", 2},
+		{`{ false: 1}`, "object keys must be strings, got boolean", 3},
+		{`{null: 1}`, "object keys must be strings, got null", 2},
+		{`{"a": {{}: 1}}`, "object keys must be strings, got object", 8},
+		{`{[1]: 1}`, "object keys must be strings, got array", 2},
+		{`{foo: 1}`, "object keys must be strings, got unquoted key", 2},
+		{`{nul: 1}`, "object keys must be strings, got unquoted key", 2},
+		{`{é: 1}`, `expected " but got é`, 2},
+	}
+
+	for _, c := range testCases {
+		events := parseAll(c.data)
+		err := events[len(events)-1].Error.(bari.ParseError)
+		require.Equal(t, c.message, err.Message, c.data)
+		require.Equal(t, c.position, err.Position, c.data)
+	}
+}
+
+func TestErrorContext(t *testing.T) {
+	testCases := []struct {
+		data    string
+		path    string
+		context string
+	}{
+		{`{"config": {"items": [1, 2, 3, {"name": "a" x}]}}`, "/config/items/3/name", `after object member "/config/items/3/name"`},
+		{`{"config": {"items": [{}, [true, nul]]}}`, "/config/items/1/1", `in array element "/config/items/1/1"`},
+		{`{"a": [{"b": {"c" 1}}]}`, "/a/0/b/c", `after object key "/a/0/b/c"`},
+		{`[[{"a": 1}, {x}]]`, "/0/1", `in a key of object "/0/1"`},
+		{`{"a": [[1, 2] 3]}`, "/a/0", `after array element "/a/0"`},
+		{`{"a": {"b": [`, "/a/b", `in array "/a/b"`},
+		{`{"a": {`, "/a", `in object "/a"`},
+		{`{"a/b~": [x]}`, "/a~1b~0/0", `in array element "/a~1b~0/0"`},
+		{`{"a": "\q"}`, "/a", `in object member "/a"`},
+		{`[`, "", "in the top-level array"},
+		{`{"a": 1,`, "", "in a key of the top-level object"},
+		{`[1] x`, "", ""},
+	}
+
+	for _, c := range testCases {
+		events := parseAll(c.data)
+		err := events[len(events)-1].Error.(bari.ParseError)
+		require.Equal(t, c.path, err.Path, c.data)
+		require.Equal(t, c.context, err.Context, c.data)
+	}
+
+	events := parseAll(`{"a": [true, {"b": fals}]}`)
+	require.Equal(t, `ParseError: l:1 pos:24 msg:unexpected character } in literal false in object member "/a/1/b"`, events[len(events)-1].Error.Error())
+
+	events = parseAll(`{"a": [[[1]]]}`, bari.MaxDepth(3))
+	require.Equal(t, `in array element "/a/0/0"`, events[len(events)-1].Error.(bari.ParseError).Context)
+}
+
+func TestMultiDocumentErrorPosition(t *testing.T) {
+	var sb strings.Builder
+	for i := 0; i < 499; i++ {
+		fmt.Fprintf(&sb, "{\"id\": %d}\n", i)
+	}
+	sb.WriteString("{\"id\":\n  500 501}\n")
+
+	events := parseAll(sb.String())
+	require.Equal(t, bari.ParseError{
+		Message:          "expected , but got 5",
+		Line:             501,
+		Position:         7,
+		Offset:           5891,
+		Column:           7,
+		Document:         499,
+		DocumentLine:     2,
+		DocumentPosition: 7,
+		Path:             "/id",
+		Context:          "after object member \"/id\"",
+		Err:              bari.ErrInvalidCharacter,
+	}, events[len(events)-1].Error)
+
+	events = parseAll("[1]  [2] [3, x]")
+	require.Equal(t, bari.ParseError{
+		Message:          "unexpected character x",
+		Line:             1,
+		Position:         14,
+		Offset:           13,
+		Column:           14,
+		Document:         2,
+		DocumentLine:     1,
+		DocumentPosition: 5,
+		Path:             "/1",
+		Context:          "in array element \"/1\"",
+		Err:              bari.ErrInvalidCharacter,
+	}, events[len(events)-1].Error)
+}
+
+func TestNumberIsInt(t *testing.T) {
+	testCases := []struct {
+		data  string
+		value interface{}
+		isInt bool
+	}{
+		{`[10]`, int64(10), true},
+		{`[-10]`, int64(-10), true},
+		{`[10.0]`, float64(10), false},
+		{`[10e0]`, float64(10), false},
+		{`[10E2]`, float64(1000), false},
+		{`[0.5]`, float64(0.5), false},
+	}
+
+	for _, c := range testCases {
+		events := parseAll(c.data)
+		require.Equal(t, 3, len(events))
+		ck(t, events[1], bari.NumberEvent, c.value, nil)
+		require.Equal(t, c.isInt, events[1].IsInt, c.data)
+	}
+
+	for _, ev := range parseAll(`{"a": "10", "b": [true]}`) {
+		require.False(t, ev.IsInt)
+	}
+}
+
+func TestNumberGrammar(t *testing.T) {
+	valid := []struct {
+		data  string
+		value interface{}
+	}{
+		{`[0]`, int64(0)},
+		{`[-0]`, int64(0)},
+		{`[0.5]`, 0.5},
+		{`[-12.5e+3]`, -12500.0},
+		{`[1E-2]`, 0.01},
+		{`[10e0]`, 10.0},
+	}
+
+	for _, c := range valid {
+		events := parseAll(c.data)
+		require.Equal(t, 3, len(events), c.data)
+		ck(t, events[1], bari.NumberEvent, c.value, nil)
+	}
+
+	invalid := []struct {
+		data     string
+		message  string
+		position int
+		path     string
+		context  string
+		err      error
+	}{
+		{`[+10]`, "invalid number: leading +", 2, "/0", `in array element "/0"`, bari.ErrInvalidNumber},
+		{`[0123]`, "invalid number: leading zero", 3, "/0", `in array element "/0"`, bari.ErrInvalidNumber},
+		{`{"a": -01}`, "invalid number: leading zero", 9, "/a", `in object member "/a"`, bari.ErrInvalidNumber},
+		{`[1e]`, "invalid number: expected digit in exponent", 4, "/0", `in array element "/0"`, bari.ErrInvalidNumber},
+		{`[1e+]`, "invalid number: expected digit in exponent", 5, "/0", `in array element "/0"`, bari.ErrInvalidNumber},
+		{`[1e`, "invalid number: expected digit in exponent", 4, "/0", `in array element "/0"`, bari.ErrInvalidNumber},
+		{`[1.]`, "invalid number: expected digit after decimal point", 4, "/0", `in array element "/0"`, bari.ErrInvalidNumber},
+		{`[1.e5]`, "invalid number: expected digit after decimal point", 4, "/0", `in array element "/0"`, bari.ErrInvalidNumber},
+		{`[-]`, "invalid number: expected digit", 3, "/0", `in array element "/0"`, bari.ErrInvalidNumber},
+		{`[--1]`, "invalid number: expected digit", 3, "/0", `in array element "/0"`, bari.ErrInvalidNumber},
+		{`[1.2.3]`, "invalid number: unexpected character .", 5, "/0", `in array element "/0"`, bari.ErrInvalidNumber},
+		{`[1-2]`, "invalid number: unexpected character -", 3, "/0", `in array element "/0"`, bari.ErrInvalidNumber},
+		{`[.5]`, "unexpected character .", 2, "/0", `in array element "/0"`, bari.ErrInvalidCharacter},
+	}
+
+	for _, c := range invalid {
+		events := parseAll(c.data)
+		for _, ev := range events {
+			require.NotEqual(t, bari.NumberEvent, ev.Type, c.data)
+		}
+		require.Equal(t, bari.ParseError{
+			Message:          c.message,
+			Line:             1,
+			Position:         c.position,
+			Offset:           int64(c.position - 1),
+			Column:           c.position,
+			DocumentLine:     1,
+			DocumentPosition: c.position,
+			Path:             c.path,
+			Context:          c.context,
+			Err:              c.err,
+		}, events[len(events)-1].Error, c.data)
+	}
+}
+
+func TestNumberAtEOF(t *testing.T) {
+	testCases := []struct {
+		data     string
+		value    interface{}
+		message  string
+		position int
+		path     string
+		context  string
+	}{
+		{`[1`, int64(1), "unexpected end of file while expecting ',' or ']'", 3, "/0", `after array element "/0"`},
+		{`[1.5`, 1.5, "unexpected end of file while expecting ',' or ']'", 5, "/0", `after array element "/0"`},
+		{`{"a": -20`, int64(-20), "unexpected end of file while expecting ',' or '}'", 10, "/a", `after object member "/a"`},
+	}
+
+	for _, c := range testCases {
+		events := parseAll(c.data)
+		ev := events[len(events)-2]
+		ck(t, ev, bari.NumberEvent, c.value, nil)
+		require.Equal(t, bari.ParseError{
+			Message:          c.message,
+			Line:             1,
+			Position:         c.position,
+			Offset:           int64(c.position - 1),
+			Column:           c.position,
+			DocumentLine:     1,
+			DocumentPosition: c.position,
+			Path:             c.path,
+			Context:          c.context,
+			Err:              bari.ErrUnexpectedEOF,
+		}, events[len(events)-1].Error, c.data)
+	}
+}
+
+func TestUnexpectedEOFMessages(t *testing.T) {
+	testCases := []struct {
+		data     string
+		message  string
+		position int
+	}{
+		{`{"foo"`, "unexpected end of file while expecting ':'", 7},
+		{"{\"foo\" \n", "unexpected end of file while expecting ':'", 1},
+		{`{"foo":1`, "unexpected end of file while expecting ',' or '}'", 9},
+		{`{"foo": {}`, "unexpected end of file while expecting ',' or '}'", 11},
+		{`["a"`, "unexpected end of file while expecting ',' or ']'", 5},
+		{`[[] `, "unexpected end of file while expecting ',' or ']'", 5},
+	}
+
+	for _, c := range testCases {
+		events := parseAll(c.data)
+		err := events[len(events)-1].Error.(bari.ParseError)
+		require.Equal(t, c.message, err.Message, c.data)
+		require.Equal(t, c.position, err.Position, c.data)
+		require.True(t, errors.Is(err, bari.ErrUnexpectedEOF), c.data)
+	}
+}
+
+func TestNumberOverflow(t *testing.T) {
+	big30, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	bigNeg, _ := new(big.Int).SetString("-9223372036854775809", 10)
+	bigUint, _ := new(big.Int).SetString("18446744073709551616", 10)
+
+	testCases := []struct {
+		data  string
+		value interface{}
+	}{
+		{`[9223372036854775807]`, int64(math.MaxInt64)},
+		{`[-9223372036854775808]`, int64(math.MinInt64)},
+		{`[9223372036854775808]`, uint64(math.MaxInt64 + 1)},
+		{`[18446744073709551615]`, uint64(math.MaxUint64)},
+		{`[18446744073709551616]`, bigUint},
+		{`[123456789012345678901234567890]`, big30},
+		{`[-9223372036854775809]`, bigNeg},
+	}
+
+	for _, c := range testCases {
+		events := parseAll(c.data)
+		require.Equal(t, 3, len(events), c.data)
+		ck(t, events[1], bari.NumberEvent, c.value, nil)
+		require.True(t, events[1].IsInt)
+	}
+}
+
+func TestParseGenerated(t *testing.T) {
+	for _, alphabet := range []baritest.Alphabet{baritest.ASCII, baritest.Unicode, baritest.EscapeHeavy} {
+		cfg := baritest.DefaultConfig
+		cfg.Alphabet = alphabet
+		g := baritest.NewGenerator(1, cfg)
+		for i := 0; i < 1000; i++ {
+			doc := g.Next()
+
+			events := parseAll(string(doc.Text))
+			require.Equal(t, len(doc.Events), len(events), "%s", doc.Text)
+			for j, ev := range doc.Events {
+				ck(t, events[j], ev.Type, ev.Value, ev.Error)
+				require.Equal(t, ev.IsInt, events[j].IsInt)
+			}
+		}
+	}
+}
+
+func parseAll(data string, opts ...bari.Option) []bari.Event {
+	return parseAllWith(bari.NewParser(strings.NewReader(data), opts...))
+}
+
+// parseAllWith returns the events emitted by a single call to the Parse method of parser. They must end with
+// a single EOFEvent, which is left out if it has no error, as the events of valid input are compared without it.
+func parseAllWith(parser *bari.Parser) []bari.Event {
+	ch := make(chan bari.Event)
+
+	go parser.ParseAndClose(ch)
+
+	var events []bari.Event
+	for ev := range ch {
+		if n := len(events); n > 0 && events[n-1].Type == bari.EOFEvent {
+			panic(fmt.Sprintf("event %+v after the EOFEvent", ev))
+		}
+		events = append(events, ev)
+	}
+
+	if len(events) == 0 {
+		// The parser was stopped.
+		return nil
+	}
+
+	last := events[len(events)-1]
+	switch {
+	case last.Type != bari.EOFEvent:
+		panic(fmt.Sprintf("last event %+v is not an EOFEvent", last))
+	case last.Error == nil:
+		events = events[:len(events)-1]
+	}
+
+	return events
+}
+
+func TestParseAndClose(t *testing.T) {
+	ch := make(chan bari.Event, 1)
+
+	// Parse leaves the channel open, so it can be shared by several parsers.
+	go func() {
+		bari.NewParser(strings.NewReader(`[1]`)).Parse(ch)
+		bari.NewParser(strings.NewReader(`{"a": x}`)).Parse(ch)
+		bari.NewParser(strings.NewReader(`[true]`)).ParseAndClose(ch)
+	}()
+
+	var types []bari.EventType
+	for ev := range ch {
+		types = append(types, ev.Type)
+	}
+
+	require.Equal(t, []bari.EventType{
+		bari.ArrayStartEvent, bari.NumberEvent, bari.ArrayEndEvent, bari.EOFEvent,
+		bari.ObjectStartEvent, bari.ObjectKeyEvent, bari.StringEvent, bari.ObjectValueEvent, bari.EOFEvent,
+		bari.ArrayStartEvent, bari.BooleanEvent, bari.ArrayEndEvent, bari.EOFEvent,
+	}, types)
+}
+
+func TestParseError(t *testing.T) {
+	for _, c := range testCases {
+		parser := bari.NewParser(strings.NewReader(c.data))
+		ch := make(chan bari.Event)
+		errc := make(chan error, 1)
+
+		go func() {
+			errc <- parser.ParseAndClose(ch)
+		}()
+
+		var last bari.Event
+		for ev := range ch {
+			last = ev
+		}
+
+		err := <-errc
+		require.Equal(t, bari.EOFEvent, last.Type, "data: %q", c.data)
+		require.Equal(t, last.Error, err, "data: %q", c.data)
+	}
+
+	// A stopped parser returns ErrStopped.
+	parser := bari.NewParser(strings.NewReader(`[1, 2]`))
+	parser.Stop()
+	require.Equal(t, bari.ErrStopped, parser.Parse(make(chan bari.Event)))
+
+	ch := make(chan bari.Event, 5)
+	require.Nil(t, bari.ParseBytes([]byte(`[]`), ch))
+	require.NotNil(t, bari.ParseBytes([]byte(`[`), ch))
+}
+
+func TestErr(t *testing.T) {
+	parser := bari.NewParser(strings.NewReader(`[1] {}`))
+	require.Nil(t, parser.Err())
+	require.Nil(t, parser.Parse(make(chan bari.Event, 8)))
+	require.Nil(t, parser.Err())
+
+	parser = bari.NewParser(strings.NewReader(`[1, x]`))
+	events := parseAllWith(parser)
+	require.Equal(t, events[len(events)-1].Error, parser.Err())
+	require.Equal(t, "unexpected character x", parser.Err().(bari.ParseError).Message)
+
+	// Later failures wrapping ErrFailed don't replace the error.
+	parseAllWith(parser)
+	require.Equal(t, events[len(events)-1].Error, parser.Err())
+
+	parser.Reset(strings.NewReader(`[]`))
+	require.Nil(t, parser.Err())
+
+	// Err may be read while another goroutine parses.
+	parser = bari.NewParser(strings.NewReader(strings.Repeat(`[1, 2, 3] `, 1000) + `[x]`))
+	ch := make(chan bari.Event)
+	go parser.ParseAndClose(ch)
+
+	var last bari.Event
+	for ev := range ch {
+		if ev.Type == bari.NumberEvent {
+			// The error can't be known before the last document.
+			require.Nil(t, parser.Err())
+		}
+		last = ev
+	}
+	require.Equal(t, last.Error, parser.Err())
+}
+
+func TestMore(t *testing.T) {
+	for _, data := range []string{"", " \n\t "} {
+		parser := bari.NewParser(strings.NewReader(data))
+		require.False(t, parser.More(), "data: %q", data)
+		_, err := parser.Next()
+		require.Equal(t, io.EOF, err)
+		require.Nil(t, parser.Err())
+	}
+
+	parser := bari.NewParser(strings.NewReader(`[1]`))
+	require.True(t, parser.More())
+	require.Equal(t, 3, len(nextAll(t, parser)))
+	require.False(t, parser.More())
+
+	parser = bari.NewParser(strings.NewReader("{\"a\": 1}\n  [2] \n\n"))
+	for i := 0; i < 6; i++ {
+		require.True(t, parser.More())
+		_, err := parser.Next()
+		require.Nil(t, err)
+	}
+	require.True(t, parser.More())
+	ev, _ := parser.Next()
+	ck(t, ev, bari.ArrayStartEvent, nil, nil)
+	require.True(t, parser.More())
+	ev, _ = parser.Next()
+	ck(t, ev, bari.NumberEvent, int64(2), nil)
+	ev, _ = parser.Next()
+	ck(t, ev, bari.ArrayEndEvent, nil, nil)
+	require.False(t, parser.More())
+	require.False(t, parser.More())
+	require.Nil(t, parser.Err())
+
+	// The error of the reader is returned by Next.
+	readErr := errors.New("read error")
+	parser = bari.NewParser(io.MultiReader(strings.NewReader("[] "), &failingReader{err: readErr}))
+	for i := 0; i < 2; i++ {
+		_, err := parser.Next()
+		require.Nil(t, err)
+	}
+	require.True(t, parser.More())
+	ev, err := parser.Next()
+	require.Equal(t, bari.EOFEvent, ev.Type)
+	require.True(t, errors.Is(err, readErr))
+	require.False(t, parser.More())
+
+	// More may be called between the documents received from Parse.
+	parser = bari.NewParser(strings.NewReader(`[1] {"a": [2]} [] `))
+	ch := make(chan bari.Event)
+	go parser.ParseAndClose(ch)
+
+	var (
+		depth int
+		more  []bool
+	)
+	for ev := range ch {
+		switch ev.Type {
+		case bari.ObjectStartEvent, bari.ArrayStartEvent:
+			depth++
+		case bari.ObjectEndEvent, bari.ArrayEndEvent:
+			depth--
+			if depth == 0 {
+				more = append(more, parser.More())
+			}
+		}
+	}
+	require.Equal(t, []bool{true, true, false}, more)
+}
+
+func TestBuffered(t *testing.T) {
+	payload := "\x00\x01 not JSON " + strings.Repeat("x", 10000)
+
+	testCases := []struct {
+		data   string
+		events int
+		rest   string
+		opts   []bari.Option
+	}{
+		{`{"a": [1, 2]}`, 9, "", nil},
+		{"[true]\n", 3, "\n", nil},
+		// The space ending the number is read, and unread.
+		{`42 `, 1, " ", []bari.Option{bari.StrictMode()}},
+	}
+
+	for _, c := range testCases {
+		r := strings.NewReader(c.data + payload)
+		parser := bari.NewParser(r, c.opts...)
+		for i := 0; i < c.events; i++ {
+			_, err := parser.Next()
+			require.Nil(t, err)
+		}
+
+		rest, err := ioutil.ReadAll(io.MultiReader(parser.Buffered(), r))
+		require.Nil(t, err)
+		require.Equal(t, c.rest+payload, string(rest), "data: %q", c.data)
+
+		parser = bari.NewBytesParser([]byte(c.data+payload), c.opts...)
+		for i := 0; i < c.events; i++ {
+			_, err := parser.Next()
+			require.Nil(t, err)
+		}
+
+		rest, err = ioutil.ReadAll(parser.Buffered())
+		require.Nil(t, err)
+		require.Equal(t, c.rest+payload, string(rest), "data: %q", c.data)
+	}
+}
+
+func TestParseOne(t *testing.T) {
+	garbage := "\x00\xff]}{\"\x01 not JSON " + strings.Repeat("\xfe", 10000)
+
+	for _, data := range []string{`{"a": [1, 2]}`, " \n[true] ", `[[], {}]`} {
+		r := strings.NewReader(data + garbage)
+		parser := bari.NewParser(r)
+
+		ch := make(chan bari.Event, 16)
+		require.Nil(t, parser.ParseOne(ch))
+		close(ch)
+
+		var events []bari.Event
+		for ev := range ch {
+			events = append(events, ev)
+		}
+		require.Equal(t, parseAll(data), events, "data: %q", data)
+
+		// The input following the document is left untouched.
+		end := strings.LastIndexAny(data, "]}") + 1
+		require.Equal(t, int64(end), parser.InputOffset())
+
+		rest, err := ioutil.ReadAll(io.MultiReader(parser.Buffered(), r))
+		require.Nil(t, err)
+		require.Equal(t, data[end:]+garbage, string(rest), "data: %q", data)
+	}
+
+	// Documents are parsed one by one.
+	parser := bari.NewBytesParser([]byte(`[1] {"a": null}` + "\n"))
+	for _, n := range []int{3, 6} {
+		ch := make(chan bari.Event, 16)
+		require.Nil(t, parser.ParseOne(ch))
+		require.Equal(t, n, len(ch))
+	}
+	ch := make(chan bari.Event, 1)
+	require.Equal(t, io.EOF, parser.ParseOne(ch))
+	require.Equal(t, bari.Event{Type: bari.EOFEvent}, <-ch)
+
+	// The error of an invalid document is returned.
+	parser = bari.NewParser(strings.NewReader(`[1, }` + garbage))
+	ch = make(chan bari.Event, 16)
+	err := parser.ParseOne(ch)
+	require.Equal(t, bari.ParseError{Message: "unexpected character }", Line: 1, Position: 5, Offset: 4, Column: 5, DocumentLine: 1, DocumentPosition: 5, Path: "/1", Context: `in array element "/1"`, Err: bari.ErrInvalidCharacter}, err)
+}
+
+func checkEvents(t testing.TB, events []bari.Event, expected []expectedEvent) {
+	require.Equal(t, len(expected), len(events), "events: %+v", events)
+	for i, evt := range expected {
+		ck(t, events[i], evt.typ, evt.value, evt.err)
+	}
+}
+
+func TestParseTestdata(t *testing.T) {
+	f, err := os.Open("./testdata/code.json.gz")
+	require.Nil(t, err)
+
+	gz, err := gzip.NewReader(f)
+	require.Nil(t, err)
+
+	parser := bari.NewParser(gz)
+	ch := make(chan bari.Event)
+
+	go parser.ParseAndClose(ch)
+
+	for ev := range ch {
+		require.Nil(t, ev.Error)
+	}
+}
+
+func TestLimitError(t *testing.T) {
+	var err error = bari.ParseError{
+		Message:  "max depth limit of 2 exceeded",
+		Line:     1,
+		Position: 3,
+		Offset:   2,
+		Column:   3,
+		Err:      bari.LimitError{Limit: "max depth", Value: 2, Line: 1, Position: 3},
+	}
+
+	var limitErr bari.LimitError
+	require.True(t, errors.As(err, &limitErr))
+	require.Equal(t, bari.Limit("max depth"), limitErr.Limit)
+	require.Equal(t, int64(2), limitErr.Value)
+	require.True(t, errors.Is(err, bari.ErrLimitExceeded))
+
+	err = bari.ParseError{Message: "unexpected end of file", Line: 1, Position: 1, Offset: 0, Column: 1, DocumentLine: 1, DocumentPosition: 1, Err: bari.ErrUnexpectedEOF}
+	require.False(t, errors.As(err, &limitErr))
+	require.False(t, errors.Is(err, bari.ErrLimitExceeded))
+}
+
+func TestParseErrorUnwrap(t *testing.T) {
+	events := parseAll(`{"a": [1, `)
+	err := events[len(events)-1].Error
+	require.True(t, errors.Is(err, bari.ErrUnexpectedEOF))
+	require.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	require.Equal(t, `ParseError: l:1 pos:11 msg:unexpected end of file in array element "/a/1"`, err.Error())
+
+	events = parseAll(`[1.2.3]`, bari.LenientNumbers())
+	err = events[len(events)-1].Error
+
+	var numErr *strconv.NumError
+	require.True(t, errors.As(err, &numErr))
+	require.Equal(t, "1.2.3", numErr.Num)
+	require.False(t, errors.Is(err, bari.ErrUnexpectedEOF))
+
+	events = parseAll(`[99999999999999999999]`, bari.IntegerOverflow(bari.OverflowError))
+	err = events[len(events)-1].Error
+	require.True(t, errors.Is(err, strconv.ErrRange))
+
+	var parseErr bari.ParseError
+	require.True(t, errors.As(err, &parseErr))
+	require.Equal(t, 1, parseErr.Line)
+
+	errBoom := errors.New("boom")
+	parser := bari.NewParser(&failingReader{data: `[1, `, err: errBoom})
+	ch := make(chan bari.Event)
+	go parser.ParseAndClose(ch)
+
+	for ev := range ch {
+		err = ev.Error
+	}
+	require.True(t, errors.Is(err, errBoom))
+	require.False(t, errors.Is(err, bari.ErrUnexpectedEOF))
+	require.True(t, errors.As(err, &parseErr))
+}
+
+func TestStop(t *testing.T) {
+	// The input never ends, so Parse only returns once stopped.
+	parser := bari.NewParser(&cyclingReader{data: `{"foo": "bar"}`})
+	ch := make(chan bari.Event)
+	done := make(chan struct{})
+
+	go func() {
+		parser.Parse(ch)
+		close(done)
+	}()
+
+	for i := 0; i < 3; i++ {
+		ev := <-ch
+		require.Nil(t, ev.Error)
+	}
+	parser.Stop()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Parse didn't return after Stop")
+	}
+
+	// Nothing reads ch anymore.
+	parser.Stop()
+	parser.Parse(ch)
+}
+
+func TestSendTimeout(t *testing.T) {
+	data := `[1, 2, 3, 4]`
+
+	// A consumer which receives the events in time doesn't fail the parser.
+	ch := make(chan bari.Event)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- bari.NewParser(strings.NewReader(data), bari.SendTimeout(time.Second)).ParseAndClose(ch)
+	}()
+	var events []bari.Event
+	for ev := range ch {
+		time.Sleep(time.Millisecond)
+		events = append(events, ev)
+	}
+	require.Nil(t, <-errc)
+	require.Equal(t, append(parseAll(data), bari.Event{Type: bari.EOFEvent}), events)
+
+	// A consumer which stops receiving them stops the parser.
+	for _, parse := range []func(p *bari.Parser, ch chan bari.Event) error{(*bari.Parser).Parse, (*bari.Parser).ParseOne} {
+		parser := bari.NewParser(strings.NewReader(data), bari.SendTimeout(20*time.Millisecond))
 		ch := make(chan bari.Event)
-
 		go func() {
-			parser.Parse(ch)
-			close(ch)
+			errc <- parse(parser, ch)
 		}()
+		<-ch
+		<-ch
 
-		for _, evt := range c.events {
-			ev := <-ch
-			fmt.Printf("case %d: `%s` %+v\n", i, c.data, ev)
-			ck(t, ev, evt.typ, evt.value, evt.err)
+		select {
+		case err := <-errc:
+			var perr bari.ParseError
+			require.True(t, errors.As(err, &perr), "err: %v", err)
+			require.Equal(t, bari.ErrConsumerStalled, perr.Err)
+			require.Equal(t, err, parser.Err())
+		case <-time.After(5 * time.Second):
+			t.Fatal("the parser is blocked")
 		}
+		require.Equal(t, 0, len(ch))
 	}
 }
 
-func TestParseTestdata(t *testing.T) {
-	f, err := os.Open("./testdata/code.json.gz")
-	require.Nil(t, err)
+func TestParseContext(t *testing.T) {
+	// The parse is canceled while the parser waits for the rest of the document.
+	pr, pw := io.Pipe()
+	parser := bari.NewParser(pr)
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan bari.Event, 1)
+	errc := make(chan error, 1)
 
-	gz, err := gzip.NewReader(f)
+	go func() {
+		errc <- parser.ParseContext(ctx, ch)
+	}()
+
+	_, err := pw.Write([]byte(`{"a": [1, `))
 	require.Nil(t, err)
+	for i := 0; i < 6; i++ {
+		ev := <-ch
+		require.Nil(t, ev.Error)
+	}
 
-	parser := bari.NewParser(gz)
-	ch := make(chan bari.Event)
+	cancel()
+	// The parser stops once a blocked read returns.
+	go func() {
+		for {
+			if _, err := pw.Write([]byte(`2`)); err != nil {
+				return
+			}
+		}
+	}()
+
+	select {
+	case err = <-errc:
+	case <-time.After(5 * time.Second):
+		t.Fatal("ParseContext didn't return after the cancellation")
+	}
+	require.True(t, errors.Is(err, context.Canceled))
+	pr.Close()
+
+	ev := <-ch
+	require.Equal(t, bari.EOFEvent, ev.Type)
+	require.Equal(t, err, ev.Error)
+	require.Equal(t, parser.Err(), err)
+
+	// The consumer went away: nothing reads the events anymore.
+	parser = bari.NewParser(&cyclingReader{data: `{"foo": "bar"}`})
+	ctx, cancel = context.WithCancel(context.Background())
+	unbuffered := make(chan bari.Event)
 
 	go func() {
-		parser.Parse(ch)
-		close(ch)
+		errc <- parser.ParseContext(ctx, unbuffered)
 	}()
 
+	<-unbuffered
+	cancel()
+
+	select {
+	case err = <-errc:
+	case <-time.After(5 * time.Second):
+		t.Fatal("ParseContext didn't return after the cancellation")
+	}
+	require.True(t, errors.Is(err, context.Canceled))
+
+	// A canceled context stops the parse before the first event.
+	err = bari.NewParser(strings.NewReader(`[1]`)).ParseContext(ctx, ch)
+	require.True(t, errors.Is(err, context.Canceled))
+	ev = <-ch
+	require.Equal(t, err, ev.Error)
+}
+
+func TestNextContext(t *testing.T) {
+	parser := bari.NewParser(strings.NewReader(`[1, 2]`))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	ev, err := parser.NextContext(ctx)
+	require.Nil(t, err)
+	ck(t, ev, bari.ArrayStartEvent, nil, nil)
+
+	cancel()
+	ev, err = parser.NextContext(ctx)
+	require.True(t, errors.Is(err, context.Canceled))
+	require.Equal(t, bari.Event{Type: bari.EOFEvent, Error: err}, ev)
+
+	_, err = parser.Next()
+	require.True(t, errors.Is(err, bari.ErrFailed))
+}
+
+func TestTimeout(t *testing.T) {
+	data := `[` + strings.Repeat(`"abcdefghij", `, 100) + `1]`
+
+	// A slow input stops the parser near the deadline, while the same input read at once doesn't.
+	parser := bari.NewParser(&slowReader{strings.NewReader(data), 10 * time.Millisecond}, bari.Timeout(100*time.Millisecond))
+	ch := make(chan bari.Event, len(data))
+	start := time.Now()
+	err := parser.Parse(ch)
+	elapsed := time.Since(start)
+
+	var perr bari.ParseError
+	require.True(t, errors.As(err, &perr), "err: %v", err)
+	require.Equal(t, bari.ErrTimeout, perr.Err)
+	require.Equal(t, err, parser.Err())
+	require.True(t, elapsed >= 100*time.Millisecond && elapsed < time.Second, "elapsed: %v", elapsed)
+	close(ch)
+	var last bari.Event
 	for ev := range ch {
-		require.Nil(t, ev.Error)
+		last = ev
+	}
+	require.Equal(t, bari.Event{Type: bari.EOFEvent, Error: err}, last)
+
+	parser.Reset(strings.NewReader(data))
+	require.Nil(t, parser.ParseFunc(func(bari.Event) bool { return true }))
+
+	// Next fails the same way.
+	parser = bari.NewParser(&slowReader{strings.NewReader(data), 10 * time.Millisecond}, bari.Timeout(50*time.Millisecond))
+	for err == nil || !errors.As(err, &perr) {
+		_, err = parser.Next()
+	}
+	require.True(t, errors.Is(err, bari.ErrTimeout))
+
+	// It composes with a context, the first one done stopping the parser.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	parser = bari.NewParser(&slowReader{strings.NewReader(data), 10 * time.Millisecond}, bari.Timeout(50*time.Millisecond))
+	err = parser.ParseContext(ctx, make(chan bari.Event, len(data)))
+	require.True(t, errors.Is(err, bari.ErrTimeout), "err: %v", err)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	parser = bari.NewParser(&slowReader{strings.NewReader(data), 10 * time.Millisecond}, bari.Timeout(time.Hour))
+	err = parser.ParseContext(ctx, make(chan bari.Event, len(data)))
+	require.True(t, errors.Is(err, context.DeadlineExceeded), "err: %v", err)
+}
+
+func TestParseAfterError(t *testing.T) {
+	parser := bari.NewParser(strings.NewReader(`{"a": x} {"b": 1}`))
+
+	events := parseAllWith(parser)
+	require.Equal(t, "unexpected character x", events[len(events)-1].Error.(bari.ParseError).Message)
+
+	events = parseAllWith(parser)
+	require.Equal(t, events, parseAllWith(parser))
+	require.Equal(t, 1, len(events))
+	require.Equal(t, bari.ParseError{
+		Message:          "parser is in a failed state",
+		Line:             1,
+		Position:         8,
+		Offset:           7,
+		Column:           8,
+		DocumentLine:     1,
+		DocumentPosition: 8,
+		Path:             "/a",
+		Context:          "after object member \"/a\"",
+		Err:              bari.ErrFailed,
+	}, events[0].Error)
+	require.True(t, errors.Is(events[0].Error, bari.ErrFailed))
+
+	// A parser which reached the end of its input cleanly can still be used.
+	parser = bari.NewParser(strings.NewReader(`{}`))
+	require.Equal(t, 2, len(parseAllWith(parser)))
+	require.Equal(t, 0, len(parseAllWith(parser)))
+}
+
+func TestReset(t *testing.T) {
+	parser := bari.NewParser(strings.NewReader("{\"a\": 1,\n \"a\": x}"), bari.DisallowDuplicateKeys(), bari.IndexLines())
+	events := parseAllWith(parser)
+	require.Equal(t, "duplicate key \"a\"", events[len(events)-1].Error.(bari.ParseError).Message)
+	index := parser.PositionIndex()
+
+	// The failed state is cleared, and the options are kept.
+	parser.Reset(strings.NewReader(`[1] {"b": 2, "b": 3}`))
+	events = parseAllWith(parser)
+	require.Equal(t, parseAll(`[1] {"b": 2, "b": 3}`, bari.DisallowDuplicateKeys()), events)
+	require.Equal(t, 1, events[len(events)-1].Error.(bari.ParseError).Document)
+
+	// The index of the previous input is left untouched.
+	line, _ := index.LineCol(10)
+	require.Equal(t, 2, line)
+	require.True(t, index != parser.PositionIndex())
+
+	// A stopped parser can be reset too.
+	parser.Stop()
+	require.Empty(t, parseAllWith(parser))
+	parser.Reset(strings.NewReader(`{}`))
+	checkEvents(t, parseAllWith(parser), []expectedEvent{
+		{bari.ObjectStartEvent, nil, nil},
+		{bari.ObjectEndEvent, nil, nil},
+	})
+
+	// Reset drops the events which were parsed but not returned yet.
+	parser.Reset(strings.NewReader(`[true]`))
+	_, err := parser.Peek()
+	require.Nil(t, err)
+	parser.Reset(strings.NewReader(`[null]`))
+	checkEvents(t, nextAll(t, parser), []expectedEvent{
+		{bari.ArrayStartEvent, nil, nil},
+		{bari.NullEvent, nil, nil},
+		{bari.ArrayEndEvent, nil, nil},
+	})
+}
+
+// nextAll returns the events returned by Next until the end of the input or an error.
+func nextAll(t testing.TB, parser *bari.Parser) []bari.Event {
+	var events []bari.Event
+	for {
+		ev, err := parser.Next()
+		if err == io.EOF {
+			require.Equal(t, bari.Event{Type: bari.EOFEvent}, ev)
+			return events
+		}
+
+		events = append(events, ev)
+		if err != nil {
+			require.Equal(t, bari.EOFEvent, ev.Type)
+			require.Equal(t, ev.Error, err)
+			return events
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	for _, c := range testCases {
+		events := nextAll(t, bari.NewParser(strings.NewReader(c.data)))
+		checkEvents(t, events[:len(c.events)], c.events)
+		require.Equal(t, parseAll(c.data), events, "data: %q", c.data)
+	}
+
+	// Next keeps returning io.EOF once the input is exhausted, and an error wrapping ErrFailed after a failure.
+	parser := bari.NewParser(strings.NewReader(`[1]`))
+	require.Equal(t, 3, len(nextAll(t, parser)))
+	_, err := parser.Next()
+	require.Equal(t, io.EOF, err)
+
+	parser = bari.NewParser(strings.NewReader(`[1 x`))
+	require.Equal(t, 3, len(nextAll(t, parser)))
+	ev, err := parser.Next()
+	require.Equal(t, bari.EOFEvent, ev.Type)
+	require.True(t, errors.Is(err, bari.ErrFailed))
+	require.Equal(t, err, ev.Error)
+}
+
+func TestPeek(t *testing.T) {
+	parser := bari.NewParser(strings.NewReader(`{"a": [1, true]} [x]`))
+
+	peek := func(typ bari.EventType, value interface{}) {
+		for i := 0; i < 2; i++ {
+			ev, err := parser.Peek()
+			require.Nil(t, err)
+			ck(t, ev, typ, value, nil)
+		}
+	}
+	next := func(typ bari.EventType, value interface{}) {
+		ev, err := parser.Next()
+		require.Nil(t, err)
+		ck(t, ev, typ, value, nil)
+	}
+
+	peek(bari.ObjectStartEvent, nil)
+	next(bari.ObjectStartEvent, nil)
+	next(bari.ObjectKeyEvent, nil)
+	peek(bari.StringEvent, "a")
+	next(bari.StringEvent, "a")
+	next(bari.ObjectValueEvent, nil)
+	peek(bari.ArrayStartEvent, nil)
+	next(bari.ArrayStartEvent, nil)
+	peek(bari.NumberEvent, int64(1))
+	next(bari.NumberEvent, int64(1))
+	next(bari.BooleanEvent, true)
+	next(bari.ArrayEndEvent, nil)
+	peek(bari.ObjectEndEvent, nil)
+	next(bari.ObjectEndEvent, nil)
+	next(bari.ArrayStartEvent, nil)
+
+	// The error is peeked like an event.
+	ev, err := parser.Peek()
+	require.Equal(t, "unexpected character x", err.(bari.ParseError).Message)
+	require.Equal(t, bari.Event{Type: bari.EOFEvent, Error: err}, ev)
+
+	next2, err2 := parser.Next()
+	require.Equal(t, ev, next2)
+	require.Equal(t, err, err2)
+
+	_, err = parser.Peek()
+	require.True(t, errors.Is(err, bari.ErrFailed))
+}
+
+func TestParseFunc(t *testing.T) {
+	for _, c := range testCases {
+		var events []bari.Event
+		err := bari.NewParser(strings.NewReader(c.data)).ParseFunc(func(ev bari.Event) bool {
+			events = append(events, ev)
+			return true
+		})
+		// The EOFEvent always comes last, with the parse error if any.
+		last := events[len(events)-1]
+		require.Equal(t, bari.EOFEvent, last.Type, "data: %q", c.data)
+		require.Equal(t, last.Error, err)
+		if last.Error == nil {
+			events = events[:len(events)-1]
+		}
+		require.Equal(t, parseAll(c.data), events, "data: %q", c.data)
+	}
+
+	// The callback stops the parse at the value of "b".
+	readErr := errors.New("unreachable")
+	r := io.MultiReader(strings.NewReader(`{"a": 1, "b": 2, `), &failingReader{data: `"c": 3}`, err: readErr})
+	parser := bari.NewParser(r)
+
+	var value interface{}
+	err := parser.ParseFunc(func(ev bari.Event) bool {
+		if ev.Type == bari.NumberEvent && ev.Value == int64(2) {
+			value = ev.Value
+			return false
+		}
+		return true
+	})
+	require.Equal(t, bari.ErrStopped, err)
+	require.Equal(t, int64(2), value)
+
+	// A stopped parse may be resumed.
+	events := nextAll(t, parser)
+	require.Equal(t, 6, len(events))
+	ck(t, events[0], bari.ObjectKeyEvent, nil, nil)
+	require.True(t, errors.Is(events[5].Error, readErr))
+}
+
+// failingReader returns data and then fails with err.
+type failingReader struct {
+	data string
+	err  error
+}
+
+func (r *failingReader) Read(b []byte) (int, error) {
+	if r.data == "" {
+		return 0, r.err
+	}
+
+	n := copy(b, r.data)
+	r.data = r.data[n:]
+
+	return n, nil
+}
+
+func TestReaderError(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	testCases := []struct {
+		data     string
+		events   int
+		position int
+		path     string
+		context  string
+	}{
+		{`{"a": "bc`, 4, 10, "/a", `in object member "/a"`},
+		{`[1, 23`, 2, 7, "/1", `in array element "/1"`},
+		{`[tr`, 1, 4, "/0", `in array element "/0"`},
+		{`{}  `, 2, 5, "", ""},
+	}
+
+	for _, tc := range testCases {
+		parser := bari.NewParser(&failingReader{data: tc.data, err: errBoom})
+		ch := make(chan bari.Event)
+
+		go parser.ParseAndClose(ch)
+
+		var events []bari.Event
+		for ev := range ch {
+			events = append(events, ev)
+		}
+
+		require.Equal(t, tc.events+1, len(events), "data: %s", tc.data)
+
+		ev := events[len(events)-1]
+		require.Equal(t, bari.EOFEvent, ev.Type)
+		require.True(t, errors.Is(ev.Error, errBoom))
+		require.Equal(t, bari.ParseError{
+			Message:          "boom",
+			Line:             1,
+			Position:         tc.position,
+			Offset:           int64(tc.position - 1),
+			Column:           tc.position,
+			DocumentLine:     1,
+			DocumentPosition: tc.position,
+			Path:             tc.path,
+			Context:          tc.context,
+			Err:              errBoom,
+		}, ev.Error)
+	}
+}
+
+func TestConcurrentParsers(t *testing.T) {
+	const (
+		parsers   = 8
+		documents = 200
+	)
+
+	errCh := make(chan error, parsers)
+	for i := 0; i < parsers; i++ {
+		go func(i int) {
+			var sb strings.Builder
+			for j := 0; j < documents; j++ {
+				fmt.Fprintf(&sb, `{"s": "parser %d document %d", "n": %d}`+"\n", i, j, i*documents+j)
+			}
+
+			events := parseAll(sb.String())
+			if len(events) != documents*10 {
+				errCh <- fmt.Errorf("parser %d: got %d events", i, len(events))
+				return
+			}
+
+			for j := 0; j < documents; j++ {
+				doc := events[j*10 : (j+1)*10]
+				if s := doc[4].Value; s != fmt.Sprintf("parser %d document %d", i, j) {
+					errCh <- fmt.Errorf("parser %d: got string %v", i, s)
+					return
+				}
+				if n := doc[8].Value; n != int64(i*documents+j) {
+					errCh <- fmt.Errorf("parser %d: got number %v", i, n)
+					return
+				}
+			}
+
+			errCh <- nil
+		}(i)
+	}
+
+	for i := 0; i < parsers; i++ {
+		require.Nil(t, <-errCh)
 	}
 }
 
@@ -403,6 +2955,29 @@ func BenchmarkParseMultiObjectStream(b *testing.B) {
 	b.SetBytes(int64(len(data)))
 }
 
+func BenchmarkParseMultiObjectStreamCompact(b *testing.B) {
+	b.ReportAllocs()
+	b.StopTimer()
+
+	const data = `{"foo": "bar"}{"foo": "bar"}{"foo": "bar"}{"foo": "bar"}`
+	parser := bari.NewParser(&cyclingReader{data: data}, bari.CompactEvents())
+	ch := make(chan bari.Event)
+
+	b.StartTimer()
+
+	go func() {
+		parser.Parse(ch)
+	}()
+
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < 4; j++ {
+			<-ch
+		}
+	}
+
+	b.SetBytes(int64(len(data)))
+}
+
 func BenchmarkParseStringWithUnicodeChars(b *testing.B) {
 	b.ReportAllocs()
 	b.StopTimer()
@@ -426,6 +3001,172 @@ func BenchmarkParseStringWithUnicodeChars(b *testing.B) {
 	b.SetBytes(int64(len(data)))
 }
 
+func BenchmarkNewParser(b *testing.B) {
+	b.ReportAllocs()
+
+	const data = `{"foo": [1, 2, "bar"]}`
+	for i := 0; i < b.N; i++ {
+		bari.NewParser(strings.NewReader(data)).ParseFunc(func(ev bari.Event) bool { return true })
+	}
+
+	b.SetBytes(int64(len(data)))
+}
+
+func BenchmarkReset(b *testing.B) {
+	b.ReportAllocs()
+
+	const data = `{"foo": [1, 2, "bar"]}`
+	var (
+		r      strings.Reader
+		parser = bari.NewParser(&r)
+	)
+	for i := 0; i < b.N; i++ {
+		r.Reset(data)
+		parser.Reset(&r)
+		parser.ParseFunc(func(ev bari.Event) bool { return true })
+	}
+
+	b.SetBytes(int64(len(data)))
+}
+
+func BenchmarkParseReaderTestdata(b *testing.B) {
+	codeJSON := readTestdata(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		bari.NewParser(bytes.NewReader(codeJSON)).ParseFunc(func(ev bari.Event) bool { return true })
+	}
+
+	b.SetBytes(int64(len(codeJSON)))
+}
+
+func BenchmarkParseReaderTestdataSize(b *testing.B) {
+	codeJSON := readTestdata(b)
+
+	for _, size := range []int{512, 4096, 64 * 1024} {
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				bari.NewParserSize(bytes.NewReader(codeJSON), size).ParseFunc(func(ev bari.Event) bool { return true })
+			}
+
+			b.SetBytes(int64(len(codeJSON)))
+		})
+	}
+}
+
+func BenchmarkParseBytesTestdata(b *testing.B) {
+	codeJSON := readTestdata(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		bari.NewBytesParser(codeJSON).ParseFunc(func(ev bari.Event) bool { return true })
+	}
+
+	b.SetBytes(int64(len(codeJSON)))
+}
+
+func BenchmarkParseBytesTestdataTypedValues(b *testing.B) {
+	codeJSON := readTestdata(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		bari.NewBytesParser(codeJSON, bari.TypedValues()).ParseFunc(func(ev bari.Event) bool { return true })
+	}
+
+	b.SetBytes(int64(len(codeJSON)))
+}
+
+// stringArray returns an array of n strings without escape sequences.
+func stringArray(n int) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(&buf, `"string number %d"`, i)
+	}
+	buf.WriteByte(']')
+
+	return buf.Bytes()
+}
+
+func BenchmarkParseStrings(b *testing.B) {
+	data := stringArray(10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		bari.NewBytesParser(data).ParseFunc(func(ev bari.Event) bool { return true })
+	}
+
+	b.SetBytes(int64(len(data)))
+}
+
+func BenchmarkParseByteStrings(b *testing.B) {
+	data := stringArray(10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		bari.NewBytesParser(data, bari.ByteStrings()).ParseFunc(func(ev bari.Event) bool { return true })
+	}
+
+	b.SetBytes(int64(len(data)))
+}
+
+func BenchmarkParseByteStringsReader(b *testing.B) {
+	data := stringArray(10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		bari.NewParser(bytes.NewReader(data), bari.ByteStrings()).ParseFunc(func(ev bari.Event) bool { return true })
+	}
+
+	b.SetBytes(int64(len(data)))
+}
+
+// BenchmarkSkipValue skips the tree of testdata/code.json.gz, which is nearly all of it.
+func BenchmarkSkipValue(b *testing.B) {
+	codeJSON := readTestdata(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		parser := bari.NewBytesParser(codeJSON)
+		for j := 0; j < 4; j++ {
+			parser.Next()
+		}
+		if err := parser.SkipValue(); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.SetBytes(int64(len(codeJSON)))
+}
+
+// readTestdata returns the uncompressed content of testdata/code.json.gz.
+func readTestdata(b testing.TB) []byte {
+	f, err := os.Open("./testdata/code.json.gz")
+	require.Nil(b, err)
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	require.Nil(b, err)
+
+	codeJSON, err := ioutil.ReadAll(gz)
+	require.Nil(b, err)
+
+	return codeJSON
+}
+
 func BenchmarkParseTestdata(b *testing.B) {
 	b.ReportAllocs()
 	b.StopTimer()
@@ -456,3 +3197,29 @@ func BenchmarkParseTestdata(b *testing.B) {
 
 	b.SetBytes(int64(len(codeJSON)))
 }
+
+func BenchmarkParseFuncTestdata(b *testing.B) {
+	b.ReportAllocs()
+	b.StopTimer()
+
+	f, err := os.Open("./testdata/code.json.gz")
+	require.Nil(b, err)
+
+	gz, err := gzip.NewReader(f)
+	require.Nil(b, err)
+
+	codeJSON, err := ioutil.ReadAll(gz)
+	require.Nil(b, err)
+
+	parser := bari.NewParser(&cyclingReader{data: string(codeJSON)})
+	n := b.N * 396995
+
+	b.StartTimer()
+
+	parser.ParseFunc(func(ev bari.Event) bool {
+		n--
+		return n > 0
+	})
+
+	b.SetBytes(int64(len(codeJSON)))
+}
@@ -0,0 +1,301 @@
+// Package baritest provides utilities for testing code built on bari.
+package baritest
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/vrischmann/bari"
+)
+
+// Alphabet selects the characters used in generated strings.
+type Alphabet uint
+
+const (
+	// ASCII strings contain printable ASCII characters only.
+	ASCII Alphabet = iota
+	// Unicode strings contain characters from the whole Unicode range, including supplementary planes.
+	Unicode
+	// EscapeHeavy strings are mostly made of characters which must be escaped, and use \u escapes liberally.
+	EscapeHeavy
+)
+
+// NumberStyle is a set of number syntaxes the generator may use.
+type NumberStyle uint
+
+const (
+	// Integers are numbers without fraction or exponent, like -42.
+	Integers NumberStyle = 1 << iota
+	// Floats are numbers with a fraction, like 3.25.
+	Floats
+	// Exponents are numbers with an exponent, like 1.5e-3 or 2E10.
+	Exponents
+
+	// AllNumbers allows every number syntax.
+	AllNumbers = Integers | Floats | Exponents
+)
+
+// Config tunes the documents produced by a Generator.
+type Config struct {
+	// MaxDepth is the maximum nesting depth of containers. The top-level container is at depth 1.
+	MaxDepth int
+	// MaxContainerSize is the maximum number of members of an object or elements of an array.
+	MaxContainerSize int
+	// MaxStringLength is the maximum number of characters in strings, keys included.
+	MaxStringLength int
+
+	Alphabet Alphabet
+	// Numbers are the number syntaxes to use. If it is zero, no number is generated.
+	Numbers NumberStyle
+
+	// NullFrequency is the probability for a scalar to be null.
+	NullFrequency float64
+	// BoolFrequency is the probability for a scalar to be a boolean.
+	BoolFrequency float64
+}
+
+// DefaultConfig is a configuration producing small documents of every kind.
+var DefaultConfig = Config{
+	MaxDepth:         5,
+	MaxContainerSize: 6,
+	MaxStringLength:  12,
+	Alphabet:         Unicode,
+	Numbers:          AllNumbers,
+	NullFrequency:    0.1,
+	BoolFrequency:    0.2,
+}
+
+// A Document is a generated JSON document, along with the events a parser emits for it.
+type Document struct {
+	Text   []byte
+	Events []bari.Event
+}
+
+// A Generator produces random valid JSON documents.
+//
+// The documents only depend on the seed and the configuration, so a failure found with a
+// generated document can be reproduced from the seed.
+type Generator struct {
+	rnd *rand.Rand
+	cfg Config
+
+	buf    bytes.Buffer
+	events []bari.Event
+}
+
+// NewGenerator creates a generator seeded with seed.
+func NewGenerator(seed int64, cfg Config) *Generator {
+	if cfg.MaxDepth < 1 {
+		cfg.MaxDepth = 1
+	}
+
+	return &Generator{
+		rnd: rand.New(rand.NewSource(seed)),
+		cfg: cfg,
+	}
+}
+
+// Next generates a document. Its top-level value is always an object or an array.
+func (g *Generator) Next() Document {
+	g.buf.Reset()
+	g.events = nil
+
+	if g.rnd.Intn(2) == 0 {
+		g.object(1)
+	} else {
+		g.array(1)
+	}
+
+	return Document{
+		Text:   append([]byte(nil), g.buf.Bytes()...),
+		Events: g.events,
+	}
+}
+
+func (g *Generator) emit(typ bari.EventType, value interface{}) {
+	g.events = append(g.events, bari.Event{Type: typ, Value: value})
+}
+
+func (g *Generator) size() int {
+	if g.cfg.MaxContainerSize <= 0 {
+		return 0
+	}
+	return g.rnd.Intn(g.cfg.MaxContainerSize + 1)
+}
+
+func (g *Generator) object(depth int) {
+	g.buf.WriteByte('{')
+	g.emit(bari.ObjectStartEvent, nil)
+
+	for i, n := 0, g.size(); i < n; i++ {
+		if i > 0 {
+			g.buf.WriteString(", ")
+		}
+
+		g.emit(bari.ObjectKeyEvent, nil)
+		g.string()
+		g.buf.WriteString(": ")
+		g.emit(bari.ObjectValueEvent, nil)
+		g.value(depth)
+	}
+
+	g.buf.WriteByte('}')
+	g.emit(bari.ObjectEndEvent, nil)
+}
+
+func (g *Generator) array(depth int) {
+	g.buf.WriteByte('[')
+	g.emit(bari.ArrayStartEvent, nil)
+
+	for i, n := 0, g.size(); i < n; i++ {
+		if i > 0 {
+			g.buf.WriteString(", ")
+		}
+		g.value(depth)
+	}
+
+	g.buf.WriteByte(']')
+	g.emit(bari.ArrayEndEvent, nil)
+}
+
+func (g *Generator) value(depth int) {
+	if depth < g.cfg.MaxDepth {
+		switch g.rnd.Intn(4) {
+		case 0:
+			g.object(depth + 1)
+			return
+		case 1:
+			g.array(depth + 1)
+			return
+		}
+	}
+
+	f := g.rnd.Float64()
+	switch {
+	case f < g.cfg.NullFrequency:
+		g.buf.WriteString("null")
+		g.emit(bari.NullEvent, nil)
+	case f < g.cfg.NullFrequency+g.cfg.BoolFrequency:
+		b := g.rnd.Intn(2) == 0
+		g.buf.WriteString(strconv.FormatBool(b))
+		g.emit(bari.BooleanEvent, b)
+	case g.cfg.Numbers != 0 && g.rnd.Intn(2) == 0:
+		g.number()
+	default:
+		g.string()
+	}
+}
+
+func (g *Generator) number() {
+	var styles []NumberStyle
+	for _, s := range []NumberStyle{Integers, Floats, Exponents} {
+		if g.cfg.Numbers&s != 0 {
+			styles = append(styles, s)
+		}
+	}
+
+	n := g.rnd.Int63n(1 << uint(g.rnd.Intn(63)))
+	if g.rnd.Intn(2) == 0 {
+		n = -n
+	}
+
+	var text string
+	switch styles[g.rnd.Intn(len(styles))] {
+	case Integers:
+		g.buf.WriteString(strconv.FormatInt(n, 10))
+		g.events = append(g.events, bari.Event{Type: bari.NumberEvent, Value: n, IsInt: true})
+		return
+	case Floats:
+		text = fmt.Sprintf("%d.%d", n%1000000, g.rnd.Intn(1000000))
+	case Exponents:
+		exp := []string{"e", "E", "e+", "e-", "E-"}[g.rnd.Intn(5)]
+		text = fmt.Sprintf("%d%s%d", n%1000, exp, g.rnd.Intn(300))
+		if g.rnd.Intn(2) == 0 {
+			text = fmt.Sprintf("%d.%d%s%d", n%1000, g.rnd.Intn(1000), exp, g.rnd.Intn(300))
+		}
+	}
+
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		panic(err)
+	}
+
+	g.buf.WriteString(text)
+	g.emit(bari.NumberEvent, f)
+}
+
+func (g *Generator) rune() rune {
+	switch g.cfg.Alphabet {
+	case Unicode:
+		for {
+			var r rune
+			switch g.rnd.Intn(4) {
+			case 0:
+				r = rune(0x20 + g.rnd.Intn(0x5f))
+			case 1:
+				r = rune(0x80 + g.rnd.Intn(0x800-0x80))
+			case 2:
+				r = rune(0x800 + g.rnd.Intn(0x10000-0x800))
+			default:
+				r = rune(0x10000 + g.rnd.Intn(utf8.MaxRune+1-0x10000))
+			}
+			if utf8.ValidRune(r) {
+				return r
+			}
+		}
+	case EscapeHeavy:
+		if g.rnd.Intn(4) == 0 {
+			return rune(0x20 + g.rnd.Intn(0x5f))
+		}
+		return []rune{'"', '\\', '/', '\b', '\f', '\n', '\r', '\t', 0, 0x1f, 'é', '☂', '😀'}[g.rnd.Intn(13)]
+	default:
+		return rune(0x20 + g.rnd.Intn(0x5f))
+	}
+}
+
+func (g *Generator) string() {
+	n := 0
+	if g.cfg.MaxStringLength > 0 {
+		n = g.rnd.Intn(g.cfg.MaxStringLength + 1)
+	}
+
+	runes := make([]rune, n)
+	for i := range runes {
+		runes[i] = g.rune()
+	}
+
+	g.buf.WriteByte('"')
+	for _, r := range runes {
+		g.writeRune(r)
+	}
+	g.buf.WriteByte('"')
+
+	g.emit(bari.StringEvent, string(runes))
+}
+
+func (g *Generator) writeRune(r rune) {
+	escapeAll := g.cfg.Alphabet == EscapeHeavy && g.rnd.Intn(2) == 0
+
+	switch {
+	case r == '"' || r == '\\':
+		g.buf.WriteByte('\\')
+		g.buf.WriteRune(r)
+	case r == '\n' && !escapeAll:
+		g.buf.WriteString(`\n`)
+	case r == '\t' && !escapeAll:
+		g.buf.WriteString(`\t`)
+	case r < 0x20 || escapeAll:
+		if r >= 0x10000 {
+			r1, r2 := utf16.EncodeRune(r)
+			fmt.Fprintf(&g.buf, `\u%04x\u%04X`, r1, r2)
+		} else {
+			fmt.Fprintf(&g.buf, `\u%04x`, r)
+		}
+	default:
+		g.buf.WriteRune(r)
+	}
+}
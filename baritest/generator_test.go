@@ -0,0 +1,92 @@
+package baritest_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/bari"
+	"github.com/vrischmann/bari/baritest"
+)
+
+func TestGeneratorDeterministic(t *testing.T) {
+	g1 := baritest.NewGenerator(42, baritest.DefaultConfig)
+	g2 := baritest.NewGenerator(42, baritest.DefaultConfig)
+	g3 := baritest.NewGenerator(43, baritest.DefaultConfig)
+
+	same := true
+	for i := 0; i < 100; i++ {
+		d1, d2, d3 := g1.Next(), g2.Next(), g3.Next()
+		require.Equal(t, d1, d2)
+		same = same && string(d1.Text) == string(d3.Text)
+	}
+	require.False(t, same)
+}
+
+func TestGeneratorValid(t *testing.T) {
+	for _, alphabet := range []baritest.Alphabet{baritest.ASCII, baritest.Unicode, baritest.EscapeHeavy} {
+		cfg := baritest.DefaultConfig
+		cfg.Alphabet = alphabet
+
+		g := baritest.NewGenerator(1, cfg)
+		for i := 0; i < 1000; i++ {
+			doc := g.Next()
+			require.True(t, json.Valid(doc.Text), "%s", doc.Text)
+
+			var v interface{}
+			require.Nil(t, json.Unmarshal(doc.Text, &v))
+		}
+	}
+}
+
+func TestGeneratorConfig(t *testing.T) {
+	cfg := baritest.Config{
+		MaxDepth:         3,
+		MaxContainerSize: 4,
+		MaxStringLength:  5,
+		Alphabet:         baritest.ASCII,
+		Numbers:          baritest.Integers,
+	}
+
+	g := baritest.NewGenerator(7, cfg)
+	for i := 0; i < 1000; i++ {
+		doc := g.Next()
+
+		var (
+			depth int
+			key   bool
+			sizes []int
+		)
+		value := func() {
+			if len(sizes) > 0 && !key {
+				sizes[len(sizes)-1]++
+			}
+			key = false
+		}
+
+		for _, ev := range doc.Events {
+			switch ev.Type {
+			case bari.ObjectStartEvent, bari.ArrayStartEvent:
+				value()
+				depth++
+				sizes = append(sizes, 0)
+				require.True(t, depth <= cfg.MaxDepth)
+			case bari.ObjectEndEvent, bari.ArrayEndEvent:
+				require.True(t, sizes[len(sizes)-1] <= cfg.MaxContainerSize)
+				depth--
+				sizes = sizes[:len(sizes)-1]
+			case bari.ObjectKeyEvent:
+				key = true
+			case bari.NullEvent, bari.BooleanEvent:
+				t.Fatalf("unexpected %v", ev.Type)
+			case bari.NumberEvent:
+				_, ok := ev.Value.(int64)
+				require.True(t, ok)
+				value()
+			case bari.StringEvent:
+				require.True(t, len(ev.Value.(string)) <= cfg.MaxStringLength)
+				value()
+			}
+		}
+	}
+}
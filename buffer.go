@@ -0,0 +1,230 @@
+package bari
+
+import "sync/atomic"
+
+// A DropPolicy tells an EventBuffer what to do with new events when it is full.
+type DropPolicy uint
+
+const (
+	// Block makes the producer wait until the consumer catches up. No event is ever dropped.
+	Block DropPolicy = iota
+	// DropNewest discards incoming events while the buffer is full.
+	DropNewest
+	// DropOldest discards the oldest buffered events to make room for incoming ones.
+	DropOldest
+)
+
+// An EventBuffer decouples a parser from a consumer which may fall behind, holding up to a fixed
+// number of events and dropping events according to its policy when it is full.
+//
+// In the lossy policies, the events starting and ending a top-level object or array, the events of
+// DocumentEvents and the EOFEvent are never dropped on their own, so that a consumer can always find the
+// document boundaries again. When the buffer only holds such events, the oldest buffered document is dropped
+// as a whole, from its start to its end, so the buffer never grows past its capacity.
+type EventBuffer struct {
+	capacity int
+	policy   DropPolicy
+
+	// queue is a ring of len(queue) events, holding n of them from head.
+	queue   []bufferedEvent
+	head, n int
+	seq     uint64
+	dropped uint64
+
+	depth    int
+	inDoc    bool   // between a DocumentStartEvent and its DocumentEndEvent
+	lastOpen uint64 // seq of the event which started the last document
+}
+
+type bufferedEvent struct {
+	ev        Event
+	seq       uint64
+	protected bool
+	// opens and closes are set for the events starting and ending a document: the DocumentStartEvent and
+	// DocumentEndEvent with DocumentEvents, the start and end of the top-level value otherwise.
+	opens, closes bool
+}
+
+// minLossyCapacity is the smallest capacity of a lossy EventBuffer. It leaves room for an event which can be
+// dropped besides the boundaries of the document being delivered, the ones of the document being parsed and
+// the EOFEvent, which are kept.
+const minLossyCapacity = 8
+
+// NewEventBuffer creates an EventBuffer holding up to capacity events.
+//
+// The capacity of a lossy policy is at least 8 events.
+func NewEventBuffer(capacity int, policy DropPolicy) *EventBuffer {
+	if capacity < 1 {
+		capacity = 1
+	}
+	if policy != Block && capacity < minLossyCapacity {
+		capacity = minLossyCapacity
+	}
+
+	return &EventBuffer{
+		capacity: capacity,
+		policy:   policy,
+		queue:    make([]bufferedEvent, capacity),
+	}
+}
+
+// Dropped returns the number of events dropped so far.
+//
+// It is safe to call concurrently with Run.
+func (b *EventBuffer) Dropped() uint64 {
+	return atomic.LoadUint64(&b.dropped)
+}
+
+// Run forwards the events read from in to out until in is closed and every buffered event is delivered,
+// then it closes out.
+func (b *EventBuffer) Run(in <-chan Event, out chan<- Event) {
+	for in != nil || b.n > 0 {
+		var (
+			recv = in
+			send chan<- Event
+			next Event
+		)
+
+		if b.n > 0 {
+			send = out
+			next = b.at(0).ev
+		}
+		if b.policy == Block && b.n >= b.capacity {
+			recv = nil
+		}
+
+		select {
+		case ev, ok := <-recv:
+			if !ok {
+				in = nil
+				continue
+			}
+			b.push(ev)
+		case send <- next:
+			*b.at(0) = bufferedEvent{}
+			b.head = (b.head + 1) % len(b.queue)
+			b.n--
+		}
+	}
+
+	close(out)
+}
+
+// at returns the i-th buffered event, starting from the oldest.
+func (b *EventBuffer) at(i int) *bufferedEvent {
+	return &b.queue[(b.head+i)%len(b.queue)]
+}
+
+func (b *EventBuffer) push(ev Event) {
+	b.seq++
+	e := b.classify(ev)
+
+	if b.n >= b.capacity && b.policy != Block {
+		switch {
+		case b.policy == DropNewest && !e.protected:
+			atomic.AddUint64(&b.dropped, 1)
+			return
+		case b.policy == DropNewest && !b.at(b.n-1).protected:
+			b.remove(b.n-1, 1)
+		case !b.evictOldest() && !e.protected:
+			atomic.AddUint64(&b.dropped, 1)
+			return
+		}
+	}
+
+	if b.n == len(b.queue) {
+		// Only reached if a lossy buffer holds nothing it may drop, which its minimum capacity prevents.
+		queue := make([]bufferedEvent, 2*len(b.queue))
+		for i := 0; i < b.n; i++ {
+			queue[i] = *b.at(i)
+		}
+		b.queue, b.head = queue, 0
+	}
+
+	*b.at(b.n) = e
+	b.n++
+}
+
+// evictOldest drops the oldest event which is not protected, or the oldest document which is entirely buffered,
+// whichever comes first. It returns false if there is neither.
+//
+// Only the few boundaries of the document being delivered and of the one being parsed are passed over, so it
+// takes constant time besides the dropped events.
+func (b *EventBuffer) evictOldest() bool {
+	for i := 0; i < b.n; i++ {
+		e := b.at(i)
+
+		switch {
+		case !e.protected:
+			b.remove(i, 1)
+			return true
+		case e.opens && (e.seq != b.lastOpen || !b.inDocument()):
+			// Only the document being parsed may lack its end.
+			j := i + 1
+			for j < b.n && !b.at(j).closes {
+				j++
+			}
+			if j < b.n {
+				b.remove(i, j-i+1)
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// remove drops the count events from the i-th one. The events before them are moved rather than the ones
+// after them, as there are only a few.
+func (b *EventBuffer) remove(i, count int) {
+	if i+count == b.n {
+		for k := i; k < b.n; k++ {
+			*b.at(k) = bufferedEvent{}
+		}
+	} else {
+		for k := i - 1; k >= 0; k-- {
+			*b.at(k + count) = *b.at(k)
+		}
+		for k := 0; k < count; k++ {
+			*b.at(k) = bufferedEvent{}
+		}
+		b.head = (b.head + count) % len(b.queue)
+	}
+
+	b.n -= count
+	atomic.AddUint64(&b.dropped, uint64(count))
+}
+
+func (b *EventBuffer) inDocument() bool {
+	return b.inDoc || b.depth > 0
+}
+
+// classify wraps ev, telling whether it is a boundary which must be kept.
+func (b *EventBuffer) classify(ev Event) bufferedEvent {
+	e := bufferedEvent{ev: ev, seq: b.seq}
+
+	switch ev.Type {
+	case ObjectStartEvent, ArrayStartEvent:
+		b.depth++
+		e.protected = b.depth == 1
+		e.opens = e.protected && !b.inDoc
+	case ObjectEndEvent, ArrayEndEvent:
+		b.depth--
+		e.protected = b.depth == 0
+		e.closes = e.protected && !b.inDoc
+	case DocumentStartEvent:
+		b.inDoc = true
+		e.protected, e.opens = true, true
+	case DocumentEndEvent:
+		b.inDoc = false
+		e.protected, e.closes = true, true
+	case EOFEvent:
+		e.protected = true
+	}
+
+	if e.opens {
+		b.lastOpen = e.seq
+	}
+
+	return e
+}
@@ -0,0 +1,122 @@
+package bari_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/bari"
+)
+
+const (
+	bufferTestDocument  = `["foo", {"bar": 1, "baz": true}, 2]`
+	bufferTestDocuments = 1000
+)
+
+func runEventBuffer(capacity int, policy bari.DropPolicy) (*bari.EventBuffer, chan bari.Event, chan struct{}) {
+	return runEventBufferOn(strings.Repeat(bufferTestDocument+"\n", bufferTestDocuments), capacity, policy)
+}
+
+func runEventBufferOn(data string, capacity int, policy bari.DropPolicy, opts ...bari.Option) (*bari.EventBuffer, chan bari.Event, chan struct{}) {
+	parser := bari.NewParser(strings.NewReader(data), opts...)
+	in := make(chan bari.Event)
+	out := make(chan bari.Event)
+	parsed := make(chan struct{})
+
+	go func() {
+		parser.Parse(in)
+		close(in)
+		close(parsed)
+	}()
+
+	buf := bari.NewEventBuffer(capacity, policy)
+	go buf.Run(in, out)
+
+	return buf, out, parsed
+}
+
+func TestEventBufferLossy(t *testing.T) {
+	// The parser ends with a clean EOFEvent.
+	total := uint64(len(parseAll(bufferTestDocument))*bufferTestDocuments + 1)
+
+	for _, policy := range []bari.DropPolicy{bari.DropNewest, bari.DropOldest} {
+		buf, out, parsed := runEventBuffer(16, policy)
+
+		// The consumer doesn't read anything until the parser is done.
+		select {
+		case <-parsed:
+		case <-time.After(10 * time.Second):
+			t.Fatal("parser stalled by the consumer")
+		}
+
+		// Only the top-level values are arrays, so they mark the document boundaries.
+		var delivered, starts, ends int
+		for ev := range out {
+			time.Sleep(time.Microsecond)
+			delivered++
+
+			switch ev.Type {
+			case bari.ArrayStartEvent:
+				starts++
+			case bari.ArrayEndEvent:
+				ends++
+			}
+		}
+
+		require.True(t, buf.Dropped() > 0)
+		require.Equal(t, total, uint64(delivered)+buf.Dropped())
+		require.True(t, delivered <= 16)
+		require.True(t, starts > 0)
+		require.Equal(t, starts, ends)
+	}
+}
+
+func TestEventBufferOnlyBoundaries(t *testing.T) {
+	data := strings.Repeat("[]\n", bufferTestDocuments)
+
+	for _, policy := range []bari.DropPolicy{bari.DropNewest, bari.DropOldest} {
+		buf, out, parsed := runEventBufferOn(data, 8, policy, bari.DocumentEvents())
+
+		select {
+		case <-parsed:
+		case <-time.After(10 * time.Second):
+			t.Fatal("parser stalled by the consumer")
+		}
+
+		// Every event is a boundary: whole documents are dropped to stay within capacity.
+		var types []string
+		for ev := range out {
+			types = append(types, ev.Type.String())
+		}
+
+		require.True(t, len(types) <= 8)
+		require.Equal(t, uint64(4*bufferTestDocuments+1), uint64(len(types))+buf.Dropped())
+
+		doc := []string{"DocumentStartEvent", "ArrayStartEvent", "ArrayEndEvent", "DocumentEndEvent"}
+		require.Equal(t, 1, len(types)%len(doc))
+		for i := 0; i+len(doc) <= len(types); i += len(doc) {
+			require.Equal(t, doc, types[i:i+len(doc)])
+		}
+		require.Equal(t, "EOFEvent", types[len(types)-1])
+	}
+}
+
+func TestEventBufferBlock(t *testing.T) {
+	buf, out, parsed := runEventBuffer(16, bari.Block)
+
+	select {
+	case <-parsed:
+		t.Fatal("parser should be blocked by the consumer")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	n := 0
+	for range out {
+		n++
+	}
+	<-parsed
+
+	require.Equal(t, uint64(0), buf.Dropped())
+	require.Equal(t, len(parseAll(bufferTestDocument))*bufferTestDocuments+1, n)
+}
@@ -0,0 +1,81 @@
+package bari
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrNotBetweenDocuments is returned by Checkpoint when the events returned so far don't end a document.
+var ErrNotBetweenDocuments = errors.New("not between documents")
+
+// A Checkpoint records the position of a parser between two documents, so that a parser created by NewParserAt
+// resumes parsing from there. It can be serialized, with encoding/json for example.
+type Checkpoint struct {
+	// Offset is the offset of the byte following the last document returned, and Line and Column its line and
+	// column, LineOffset being the offset of the start of its line.
+	Offset     int64 `json:"offset"`
+	Line       int   `json:"line"`
+	Column     int   `json:"column"`
+	LineOffset int64 `json:"line_offset"`
+	// Document is the index of the next document.
+	Document int `json:"document"`
+}
+
+// Checkpoint returns the position of the parser once the last event of a document was returned by Next, or before
+// the first document. It returns ErrNotBetweenDocuments in the middle of a document, or if an event following the
+// document was already parsed but not returned yet, like its DocumentEndEvent. It returns the error of a parser
+// which failed.
+func (p *Parser) Checkpoint() (Checkpoint, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.Err(); err != nil {
+		return Checkpoint{}, err
+	}
+	if p.head < len(p.queue) || len(p.stack) > 0 {
+		return Checkpoint{}, ErrNotBetweenDocuments
+	}
+
+	cp := Checkpoint{
+		Offset:     p.offset,
+		Line:       p.line,
+		Column:     p.column + 1,
+		LineOffset: p.lineStart,
+		Document:   p.document,
+	}
+	if !p.first {
+		cp.Document++
+	}
+
+	return cp, nil
+}
+
+// NewParserAt creates a new parser configured with opts which reads r from cp, a checkpoint of a parser of the
+// same input. The events and errors are positioned as they would be by a parser reading r from the start.
+func NewParserAt(r io.ReadSeeker, cp Checkpoint, opts ...Option) (*Parser, error) {
+	p := newParser(opts)
+	if err := p.Restore(r, cp); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Restore is like Reset, making the parser read r from cp, like a parser created by NewParserAt. It seeks r to
+// the offset of cp.
+//
+// The PositionIndex of a parser created with IndexLines is not usable, since the lines preceding cp are unknown.
+func (p *Parser) Restore(r io.ReadSeeker, cp Checkpoint) error {
+	if _, err := r.Seek(cp.Offset, io.SeekStart); err != nil {
+		return err
+	}
+	p.Reset(r)
+
+	p.offset, p.line, p.lineStart, p.column = cp.Offset, cp.Line, cp.LineOffset, cp.Column-1
+	p.resetProgress()
+	if cp.Document > 0 {
+		p.document, p.first = cp.Document-1, false
+	}
+
+	return nil
+}
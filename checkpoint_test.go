@@ -0,0 +1,89 @@
+package bari_test
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/bari"
+)
+
+func TestCheckpoint(t *testing.T) {
+	const data = "{\"a\": 1}\n[\"é\", true]\t{\"b\": {\"c\": null}}\n\n[\"x\", 2]\r\n{}  "
+
+	for _, opts := range [][]bari.Option{
+		{bari.EventPositions()},
+		{bari.EventPositions(), bari.DocumentEvents()},
+	} {
+		all := nextAll(t, bari.NewParser(strings.NewReader(data), opts...))
+
+		p := bari.NewParser(strings.NewReader(data), opts...)
+		for i := 0; ; i++ {
+			cp, err := p.Checkpoint()
+			if err == bari.ErrNotBetweenDocuments {
+				_, err = p.Next()
+				require.True(t, err == nil || err == io.EOF)
+				continue
+			}
+			require.NoError(t, err)
+
+			// Resume in a parser which only knows the serialized checkpoint.
+			buf, err := json.Marshal(cp)
+			require.NoError(t, err)
+			var restored bari.Checkpoint
+			require.NoError(t, json.Unmarshal(buf, &restored))
+
+			rp, err := bari.NewParserAt(strings.NewReader(data), restored, opts...)
+			require.NoError(t, err)
+			require.Equal(t, append([]bari.Event(nil), all[i:]...), nextAll(t, rp), "checkpoint: %+v", cp)
+
+			if _, err := p.Next(); err == io.EOF {
+				require.Equal(t, len(all), i)
+				break
+			}
+		}
+	}
+}
+
+func TestCheckpointBoundaries(t *testing.T) {
+	p := bari.NewParser(strings.NewReader(`{"a": [1]} [`), bari.DocumentEvents())
+
+	cp, err := p.Checkpoint()
+	require.NoError(t, err)
+	require.Equal(t, bari.Checkpoint{Line: 1, Column: 1}, cp)
+
+	for {
+		ev, err := p.Next()
+		require.NoError(t, err)
+		if ev.Type == bari.DocumentEndEvent {
+			break
+		}
+
+		_, err = p.Checkpoint()
+		require.Equal(t, bari.ErrNotBetweenDocuments, err, "event: %v", ev)
+	}
+
+	cp, err = p.Checkpoint()
+	require.NoError(t, err)
+	require.Equal(t, bari.Checkpoint{Offset: 10, Line: 1, Column: 11, Document: 1}, cp)
+
+	// The error of the next document is positioned as it is without the checkpoint.
+	_, expectedErr := parseFuncAll(`{"a": [1]} [`)
+	rp, err := bari.NewParserAt(strings.NewReader(`{"a": [1]} [`), cp)
+	require.NoError(t, err)
+	ev, err := rp.Next()
+	require.NoError(t, err)
+	require.Equal(t, bari.ArrayStartEvent, ev.Type)
+	_, err = rp.Next()
+	require.Equal(t, expectedErr, err)
+
+	// A failed parser has no checkpoint.
+	_, err = p.Next()
+	for err == nil {
+		_, err = p.Next()
+	}
+	_, cerr := p.Checkpoint()
+	require.Equal(t, err, cerr)
+}
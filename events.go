@@ -0,0 +1,25 @@
+//go:build go1.23
+// +build go1.23
+
+package bari
+
+import (
+	"io"
+	"iter"
+)
+
+// Events returns an iterator over the events of the input, built on Next: the input is only read as the loop
+// goes on, and breaking out of the loop stops the parse right away.
+//
+// The iterator yields the EOFEvent of an invalid input along with its ParseError, and ends after it. It must only
+// be used once per parser, unless the parser is Reset.
+func (p *Parser) Events() iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		for {
+			ev, err := p.Next()
+			if err == io.EOF || !yield(ev, err) || err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,89 @@
+//go:build go1.23
+// +build go1.23
+
+package bari_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/bari"
+)
+
+func ExampleParser_Events() {
+	const data = `{"id": 1, "name": "foo", "tags": ["a", "b"]}`
+
+	parser := bari.NewParser(strings.NewReader(data))
+
+	// last is the last string read, which is the key of the member when its ObjectValueEvent comes.
+	var (
+		last  string
+		value bool
+	)
+	for ev, err := range parser.Events() {
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		if value && ev.Type == bari.StringEvent {
+			fmt.Println(ev.Value)
+			break
+		}
+
+		value = ev.Type == bari.ObjectValueEvent && last == "name"
+		if ev.Type == bari.StringEvent {
+			last = ev.Value.(string)
+		}
+	}
+	// Output:
+	// foo
+}
+
+func TestEvents(t *testing.T) {
+	for _, c := range testCases {
+		var events []bari.Event
+		for ev, err := range bari.NewParser(strings.NewReader(c.data)).Events() {
+			events = append(events, ev)
+			require.Equal(t, ev.Error, err)
+		}
+		require.Equal(t, parseAll(c.data), events, "data: %q", c.data)
+	}
+}
+
+func TestEventsBreak(t *testing.T) {
+	// The rest of the input can't be read: the loop breaks before it is needed.
+	r := io.MultiReader(strings.NewReader(`[1, 2, `), &failingReader{err: errors.New("unreachable")})
+	parser := bari.NewParser(r)
+
+	var events []bari.Event
+	for ev, err := range parser.Events() {
+		require.Nil(t, err)
+		events = append(events, ev)
+		if ev.Value == int64(2) {
+			break
+		}
+	}
+	require.Equal(t, 3, len(events))
+}
+
+func TestEventsReset(t *testing.T) {
+	parser := bari.NewParser(strings.NewReader(`[1, 2] [3]`))
+	for range parser.Events() {
+		break
+	}
+
+	// The parser is reused for the next input once the loop ended.
+	parser.Reset(strings.NewReader(`{"a": true}`))
+
+	var events []bari.Event
+	for ev, err := range parser.Events() {
+		require.Nil(t, err)
+		events = append(events, ev)
+	}
+	require.Equal(t, parseAll(`{"a": true}`), events)
+}
@@ -4,9 +4,9 @@ package bari
 
 import "fmt"
 
-const _EventType_name = "UnknownEventObjectStartEventObjectKeyEventObjectValueEventObjectEndEventArrayStartEventArrayEndEventStringEventNumberEventBooleanEventNullEventEOFEvent"
+const _EventType_name = "UnknownEventObjectStartEventObjectKeyEventObjectValueEventObjectEndEventArrayStartEventArrayEndEventStringEventNumberEventBooleanEventNullEventEOFEventErrorEventSkippedEventDocumentStartEventDocumentEndEvent"
 
-var _EventType_index = [...]uint8{0, 12, 28, 42, 58, 72, 87, 100, 111, 122, 134, 143, 151}
+var _EventType_index = [...]uint8{0, 12, 28, 42, 58, 72, 87, 100, 111, 122, 134, 143, 151, 161, 173, 191, 207}
 
 func (i EventType) String() string {
 	if i >= EventType(len(_EventType_index)-1) {
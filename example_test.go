@@ -7,16 +7,13 @@ import (
 	"github.com/vrischmann/bari"
 )
 
-func ExampleParser_Parse_single() {
+func ExampleParser_ParseAndClose_single() {
 	const data = `{"foo": "bar"}`
 
 	parser := bari.NewParser(strings.NewReader(data))
 	ch := make(chan bari.Event)
 
-	go func() {
-		parser.Parse(ch)
-		close(ch)
-	}()
+	go parser.ParseAndClose(ch)
 
 	for ev := range ch {
 		fmt.Println(ev.Type, ev.Value)
@@ -28,18 +25,16 @@ func ExampleParser_Parse_single() {
 	// ObjectValueEvent <nil>
 	// StringEvent bar
 	// ObjectEndEvent <nil>
+	// EOFEvent <nil>
 }
 
-func ExampleParser_Parse_multi() {
+func ExampleParser_ParseAndClose_multi() {
 	const data = `{"foo": "bar"}{"bar": true}`
 
 	parser := bari.NewParser(strings.NewReader(data))
 	ch := make(chan bari.Event)
 
-	go func() {
-		parser.Parse(ch)
-		close(ch)
-	}()
+	go parser.ParseAndClose(ch)
 
 	for ev := range ch {
 		fmt.Println(ev.Type, ev.Value)
@@ -57,4 +52,5 @@ func ExampleParser_Parse_multi() {
 	// ObjectValueEvent <nil>
 	// BooleanEvent true
 	// ObjectEndEvent <nil>
+	// EOFEvent <nil>
 }
@@ -0,0 +1,283 @@
+package bari
+
+import (
+	"bytes"
+	"io"
+)
+
+// maxExtractSize bounds how far ExtractDocuments looks for the end of a candidate document.
+const maxExtractSize = 1 << 20
+
+// ExtractDocuments scans r for JSON objects and arrays embedded in arbitrary text,
+// for example log lines of the form `payload={...}`, and calls fn with the offset in r
+// and the raw bytes of each one that parses successfully.
+//
+// Every { or [ outside of an already extracted document starts a candidate, which ends
+// at its matching closing byte and is only then handed to a Parser. Brackets inside
+// strings are ignored and a candidate may span multiple lines.
+//
+// A candidate is abandoned when a bracket is mismatched, a string contains a raw control
+// character, the input ends, or it grows past 1MiB. Where a scan went is remembered for the
+// brackets nested in a candidate and for the points where the scan of another candidate may
+// join it, so that each byte is scanned a bounded number of times.
+//
+// raw is only valid until fn returns. If fn returns an error, scanning stops and the error
+// is returned.
+func ExtractDocuments(r io.Reader, fn func(off int64, raw []byte) error) error {
+	e := extractor{
+		r:    r,
+		buf:  make([]byte, 0, 32*1024),
+		memo: make(map[scanPoint]scanOutcome),
+	}
+
+	for e.nextCandidate() {
+		start := e.pos
+
+		end, ok := e.scan(start)
+		if ok {
+			raw := e.buf[start-e.base : end-e.base]
+			if validDocument(raw) {
+				if err := fn(start, raw); err != nil {
+					return err
+				}
+
+				e.advance(end)
+				continue
+			}
+		}
+
+		e.advance(start + 1)
+	}
+
+	if e.err == io.EOF {
+		return nil
+	}
+
+	return e.err
+}
+
+type extractor struct {
+	r   io.Reader
+	err error
+
+	buf  []byte
+	base int64 // offset of buf[0] in the input
+	pos  int64 // offset of the next byte to look at for a candidate
+
+	// memo records where the scans of previous candidates went from some of the points they went through.
+	memo    map[scanPoint]scanOutcome
+	maxMemo int64
+}
+
+// fill reads more data into the buffer, dropping the bytes before pos if it needs room.
+func (e *extractor) fill() bool {
+	if e.err != nil {
+		return false
+	}
+
+	if len(e.buf) == cap(e.buf) {
+		drop := e.pos - e.base
+		if drop < int64(len(e.buf))/2 {
+			nb := make([]byte, len(e.buf), 2*cap(e.buf))
+			copy(nb, e.buf)
+			e.buf = nb
+		}
+
+		n := copy(e.buf, e.buf[drop:])
+		e.buf = e.buf[:n]
+		e.base += drop
+	}
+
+	n, err := e.r.Read(e.buf[len(e.buf):cap(e.buf)])
+	e.buf = e.buf[:len(e.buf)+n]
+	if err != nil {
+		e.err = err
+	}
+
+	return n > 0 || err == nil
+}
+
+// nextCandidate moves pos to the next { or [, reading more data if needed.
+func (e *extractor) nextCandidate() bool {
+	for {
+		i := bytes.IndexAny(e.buf[e.pos-e.base:], "{[")
+		if i >= 0 {
+			e.pos += int64(i)
+			return true
+		}
+
+		e.pos = e.base + int64(len(e.buf))
+		if !e.fill() {
+			return false
+		}
+	}
+}
+
+func (e *extractor) advance(to int64) {
+	e.pos = to
+	if len(e.memo) > 0 && e.pos > e.maxMemo {
+		e.memo = make(map[scanPoint]scanOutcome)
+	}
+}
+
+// A scanPoint is an offset in the input, and whether the scan is inside a string there, in its lowest bit.
+//
+// Scans which reach the same point go the same way from there on, except for the brackets they
+// opened before it. The first scan to go through a point records in a scanOutcome where it
+// next closed one of those brackets, and later scans reaching the point jump there.
+//
+// Scans can only start to agree right after a bracket they opened, after a closing byte, or inside a
+// string right after an escape sequence, so only these points are recorded.
+type scanPoint int64
+
+func newScanPoint(off int64, inString bool) scanPoint {
+	if inString {
+		return scanPoint(off<<1 | 1)
+	}
+	return scanPoint(off << 1)
+}
+
+// A scanOutcome is the offset and value of the first closing byte met after a scanPoint for a
+// bracket opened before it. at is -1 if the scan was abandoned first.
+type scanOutcome struct {
+	at int64
+	c  byte
+}
+
+// scan looks for the end of the value starting at start, tracking nesting and strings only.
+func (e *extractor) scan(start int64) (int64, bool) {
+	var (
+		sc bracketScanner
+		// pending[k] are the points gone through while the bracket sc.open[k] was the innermost one.
+		pending [][]scanPoint
+	)
+
+	// settle records the outcome of the points gone through while the innermost bracket was open.
+	settle := func(out scanOutcome) {
+		k := len(pending) - 1
+		for _, pt := range pending[k] {
+			e.memo[pt] = out
+			if off := int64(pt >> 1); off > e.maxMemo {
+				e.maxMemo = off
+			}
+		}
+		pending = pending[:k]
+	}
+
+	fail := func() (int64, bool) {
+		for len(pending) > 0 {
+			settle(scanOutcome{at: -1})
+		}
+		return -1, false
+	}
+
+	for i := start; ; {
+		if i-start >= maxExtractSize {
+			return fail()
+		}
+
+		for i-e.base >= int64(len(e.buf)) {
+			if !e.fill() {
+				return fail()
+			}
+		}
+
+		c := e.buf[i-e.base]
+		delta, ok := sc.step(c)
+		i++
+
+		switch {
+		case !ok:
+			if !sc.inString {
+				// A mismatched closing byte: it still closes the innermost bracket for the scans
+				// which opened another one there.
+				settle(scanOutcome{at: i - 1, c: c})
+			}
+			return fail()
+		case delta > 0:
+			pending = append(pending, nil)
+		case delta < 0:
+			settle(scanOutcome{at: i - 1, c: c})
+			if len(pending) == 0 {
+				return i, true
+			}
+		case !sc.inString || sc.escaped || e.buf[i-2-e.base] != '\\':
+			continue
+		}
+
+		for {
+			pt := newScanPoint(i, sc.inString)
+			out, ok := e.memo[pt]
+			if !ok {
+				pending[len(pending)-1] = append(pending[len(pending)-1], pt)
+				break
+			}
+
+			if out.at < 0 || out.at-start >= maxExtractSize {
+				return fail()
+			}
+
+			n := len(sc.open)
+			if (sc.open[n-1] == '{') != (out.c == '}') {
+				settle(out)
+				return fail()
+			}
+
+			sc = bracketScanner{open: sc.open[:n-1]}
+			settle(out)
+			if len(pending) == 0 {
+				return out.at + 1, true
+			}
+			i = out.at + 1
+		}
+	}
+}
+
+// A bracketScanner follows the nesting of objects and arrays in JSON text, without validating anything else.
+type bracketScanner struct {
+	open     []byte
+	inString bool
+	escaped  bool
+}
+
+// step moves the scanner past c. It returns 1 if c opens a container and -1 if it closes one.
+//
+// It returns false if c can't appear there in valid JSON text: a mismatched closing byte or a
+// control character in a string.
+func (s *bracketScanner) step(c byte) (int, bool) {
+	if s.inString {
+		switch {
+		case s.escaped:
+			s.escaped = false
+		case c == '\\':
+			s.escaped = true
+		case c == '"':
+			s.inString = false
+		case c < ' ':
+			return 0, false
+		}
+		return 0, true
+	}
+
+	switch c {
+	case '"':
+		s.inString = true
+	case '{', '[':
+		s.open = append(s.open, c)
+		return 1, true
+	case '}', ']':
+		n := len(s.open)
+		if n == 0 || (s.open[n-1] == '{') != (c == '}') {
+			return 0, false
+		}
+		s.open = s.open[:n-1]
+		return -1, true
+	}
+
+	return 0, true
+}
+
+func validDocument(raw []byte) bool {
+	events := parseEvents(raw)
+	return len(events) > 0 && events[len(events)-1].Error == nil
+}
@@ -0,0 +1,179 @@
+package bari_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/bari"
+)
+
+type extracted struct {
+	off int64
+	raw string
+}
+
+func extractAll(t testing.TB, data []byte, oneByte bool) []extracted {
+	r := bytes.NewReader(data)
+
+	var res []extracted
+
+	fn := func(off int64, raw []byte) error {
+		res = append(res, extracted{off, string(raw)})
+		return nil
+	}
+
+	var err error
+	if oneByte {
+		err = bari.ExtractDocuments(iotest.OneByteReader(r), fn)
+	} else {
+		err = bari.ExtractDocuments(r, fn)
+	}
+	require.Nil(t, err)
+
+	return res
+}
+
+func TestExtractDocuments(t *testing.T) {
+	data, err := ioutil.ReadFile("./testdata/messy.log")
+	require.Nil(t, err)
+
+	docs := []string{
+		`{"user": "alice", "tags": ["a", "b"]}`,
+		`{"a": 1}`,
+		"{\n    \"b\": [1, 2,\n          3],\n    \"c\": {\"d\": true}\n}",
+		`{"ok": true}`,
+		`[1, 2, 3]`,
+	}
+
+	var exp []extracted
+	for _, doc := range docs {
+		exp = append(exp, extracted{int64(bytes.Index(data, []byte(doc))), doc})
+	}
+
+	require.Equal(t, exp, extractAll(t, data, false))
+	require.Equal(t, exp, extractAll(t, data, true))
+}
+
+func TestExtractDocumentsUnbalanced(t *testing.T) {
+	data := strings.Repeat("{[", 100000) + `{"foo": "bar"}`
+
+	res := extractAll(t, []byte(data), false)
+	require.Equal(t, []extracted{{200000, `{"foo": "bar"}`}}, res)
+}
+
+func TestExtractDocumentsCallbackError(t *testing.T) {
+	errStop := errors.New("stop")
+
+	n := 0
+	err := bari.ExtractDocuments(strings.NewReader(`a={"a": 1} b={"b": 2}`), func(off int64, raw []byte) error {
+		n++
+		return errStop
+	})
+	require.Equal(t, errStop, err)
+	require.Equal(t, 1, n)
+}
+
+// naiveExtract extracts the documents of data by scanning again from every candidate.
+func naiveExtract(data []byte) []extracted {
+	var res []extracted
+
+	for pos := 0; pos < len(data); pos++ {
+		if data[pos] != '{' && data[pos] != '[' {
+			continue
+		}
+
+		var (
+			open             []byte
+			inString, escape bool
+			end              = -1
+		)
+	scan:
+		for i := pos; i < len(data); i++ {
+			c := data[i]
+			switch {
+			case inString && escape:
+				escape = false
+			case inString && c == '\\':
+				escape = true
+			case inString && c == '"':
+				inString = false
+			case inString && c < ' ':
+				break scan
+			case inString:
+			case c == '"':
+				inString = true
+			case c == '{' || c == '[':
+				open = append(open, c)
+			case c == '}' || c == ']':
+				if (open[len(open)-1] == '{') != (c == '}') {
+					break scan
+				}
+				open = open[:len(open)-1]
+				if len(open) == 0 {
+					end = i + 1
+					break scan
+				}
+			}
+		}
+		if end < 0 {
+			continue
+		}
+
+		var last bari.Event
+		bari.NewBytesParser(data[pos:end]).ParseFunc(func(ev bari.Event) bool {
+			last = ev
+			return true
+		})
+		if last.Error == nil {
+			res = append(res, extracted{int64(pos), string(data[pos:end])})
+			pos = end - 1
+		}
+	}
+
+	return res
+}
+
+func TestExtractDocumentsRandom(t *testing.T) {
+	const alphabet = "{}[]\"\\a1:, \n"
+
+	rnd := rand.New(rand.NewSource(1))
+	for n := 0; n < 20000; n++ {
+		data := make([]byte, rnd.Intn(40))
+		for i := range data {
+			data[i] = alphabet[rnd.Intn(len(alphabet))]
+		}
+
+		require.Equal(t, naiveExtract(data), extractAll(t, data, false), "%q", data)
+	}
+}
+
+func TestExtractDocumentsHostile(t *testing.T) {
+	testCases := []string{
+		strings.Repeat(`{\"`, 1<<17),
+		`{x"` + strings.Repeat(`{\"`, 1<<17) + `"}`,
+		`{"` + strings.Repeat(`{\\`, 1<<17) + `"}`,
+		strings.Repeat(`["`, 1<<17),
+		strings.Repeat(`{"a": "\"`, 1<<15) + strings.Repeat(`}`, 1<<15),
+	}
+
+	for _, tc := range testCases {
+		done := make(chan []extracted, 1)
+		go func() {
+			done <- extractAll(t, []byte(tc+` {"ok": true}`), false)
+		}()
+
+		select {
+		case res := <-done:
+			require.Equal(t, []extracted{{int64(len(tc)) + 1, `{"ok": true}`}}, res)
+		case <-time.After(10 * time.Second):
+			t.Fatalf("extracting from %q... takes too long", tc[:12])
+		}
+	}
+}
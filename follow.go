@@ -0,0 +1,260 @@
+package bari
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// A FileWatcher tells a Follower when the file it follows may have changed.
+//
+// Implementations may poll the file or rely on file system notifications.
+type FileWatcher interface {
+	// Wait blocks until the file may have changed or ctx is done.
+	Wait(ctx context.Context) error
+}
+
+// A PollWatcher is a FileWatcher which makes the Follower check the file at a fixed interval.
+type PollWatcher time.Duration
+
+// Wait implements FileWatcher.
+func (w PollWatcher) Wait(ctx context.Context) error {
+	t := time.NewTimer(time.Duration(w))
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// A Follower parses the documents appended to a file as it grows, like tail -F.
+//
+// When the end of the file is reached in the middle of a document, the Follower waits for the rest
+// of it instead of failing. It detects when the file is truncated or when Path is replaced by a new
+// file, for example by log rotation, and starts again from the beginning of the new content.
+type Follower struct {
+	Path string
+
+	// Watcher tells the follower when to look at the file again. It defaults to polling every second.
+	Watcher FileWatcher
+
+	// OnDocument is called with the events of each complete document. The last event of an invalid
+	// document is an EOFEvent carrying the error, and following resumes after the document.
+	// It is required.
+	OnDocument func(events []Event) error
+
+	// OnTruncate, if set, is called when the file shrinks.
+	OnTruncate func()
+	// OnRotate, if set, is called when Path refers to a new file.
+	OnRotate func()
+}
+
+var errNoOnDocument = errors.New("follower has no OnDocument function")
+
+// Run follows the file until ctx is done or OnDocument returns an error.
+//
+// The file doesn't need to exist when Run starts; it is waited for.
+func (f *Follower) Run(ctx context.Context) error {
+	if f.OnDocument == nil {
+		return errNoOnDocument
+	}
+
+	watcher := f.Watcher
+	if watcher == nil {
+		watcher = PollWatcher(time.Second)
+	}
+
+	var (
+		file   *os.File
+		info   os.FileInfo
+		offset int64
+		docs   documentSplitter
+		buf    = make([]byte, 32*1024)
+	)
+	defer func() {
+		if file != nil {
+			file.Close()
+		}
+	}()
+
+	for {
+		if file == nil {
+			var err error
+			if file, info, err = openFollowed(f.Path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+
+		if file != nil {
+			// The complete documents are handled after every read, so that only the last partial one is buffered.
+			for {
+				n, err := file.Read(buf)
+				docs.write(buf[:n])
+				offset += int64(n)
+
+				for {
+					raw, ok := docs.next()
+					if !ok {
+						break
+					}
+					if err := f.OnDocument(parseEvents(raw)); err != nil {
+						return err
+					}
+				}
+
+				if err == io.EOF {
+					break
+				} else if err != nil {
+					return err
+				}
+			}
+
+			cur, err := os.Stat(f.Path)
+			switch {
+			case os.IsNotExist(err):
+				// Rotated but the new file isn't there yet.
+			case err != nil:
+				return err
+			case !os.SameFile(info, cur):
+				file.Close()
+				file, offset = nil, 0
+				docs.reset()
+				if f.OnRotate != nil {
+					f.OnRotate()
+				}
+				continue
+			case cur.Size() < offset:
+				if _, err := file.Seek(0, io.SeekStart); err != nil {
+					return err
+				}
+				offset = 0
+				docs.reset()
+				if f.OnTruncate != nil {
+					f.OnTruncate()
+				}
+				continue
+			}
+		}
+
+		if err := watcher.Wait(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+func openFollowed(path string) (*os.File, os.FileInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+
+	return file, info, nil
+}
+
+// A documentSplitter cuts complete top-level values out of data written to it.
+type documentSplitter struct {
+	buf []byte
+	sc  bracketScanner
+	// pos is the number of bytes of the current document already given to sc.
+	pos int
+}
+
+func (d *documentSplitter) write(p []byte) {
+	d.buf = append(d.buf, p...)
+}
+
+func (d *documentSplitter) reset() {
+	d.buf = d.buf[:0]
+	d.sc = bracketScanner{open: d.sc.open[:0]}
+	d.pos = 0
+}
+
+func (d *documentSplitter) consume(n int) {
+	d.buf = d.buf[n:]
+	d.sc = bracketScanner{open: d.sc.open[:0]}
+	d.pos = 0
+}
+
+// next returns the next complete document, or false if more data is needed.
+//
+// A document which is not an object or an array extends to the end of its line, and one with
+// mismatched brackets ends at the first offending byte, so that parsing it reports the error.
+func (d *documentSplitter) next() ([]byte, bool) {
+	if d.pos == 0 {
+		i := 0
+		for i < len(d.buf) && bytes.IndexByte([]byte(" \t\r\n"), d.buf[i]) >= 0 {
+			i++
+		}
+		d.buf = d.buf[i:]
+
+		if len(d.buf) == 0 {
+			return nil, false
+		}
+
+		if c := d.buf[0]; c != '{' && c != '[' {
+			j := bytes.IndexByte(d.buf, '\n')
+			if j < 0 {
+				return nil, false
+			}
+
+			doc := d.buf[:j]
+			d.consume(j + 1)
+			return doc, true
+		}
+	}
+
+	for ; d.pos < len(d.buf); d.pos++ {
+		delta, ok := d.sc.step(d.buf[d.pos])
+		if !ok || delta < 0 && len(d.sc.open) == 0 {
+			n := d.pos + 1
+			doc := d.buf[:n]
+			d.consume(n)
+			return doc, true
+		}
+	}
+
+	return nil, false
+}
+
+// follow waits for the input to grow, for Follow, and returns its next byte. It returns io.EOF once the context of
+// Follow is done, and the cause of the stop if the parser is stopped.
+func (p *Parser) follow() (byte, error) {
+	t := time.NewTicker(p.opts.followInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+		case <-p.opts.followCtx.Done():
+			return 0, io.EOF
+		case <-p.done:
+			return 0, p.stopCause
+		}
+
+		if r, err := p.nextByte(); err != io.EOF {
+			return r, err
+		}
+	}
+}
+
+// holding reports whether the events of the document being read are held until it is complete, for Follow.
+func (p *Parser) holding() bool {
+	return p.opts.followCtx != nil && len(p.stack) > 0 && p.getError() == nil
+}
+
+// followed reports whether err is the end of the input of a parser created with Follow whose context is done.
+func (p *Parser) followed(err error) bool {
+	return p.opts.followCtx != nil && p.opts.followCtx.Err() != nil && errors.Is(err, ErrUnexpectedEOF)
+}
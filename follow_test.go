@@ -0,0 +1,287 @@
+package bari_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/bari"
+)
+
+// chanWatcher lets a test decide when the follower looks at the file again.
+type chanWatcher struct {
+	waiting chan struct{}
+	wake    chan struct{}
+}
+
+func (w *chanWatcher) Wait(ctx context.Context) error {
+	w.waiting <- struct{}{}
+	select {
+	case <-w.wake:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func summarize(events []bari.Event) string {
+	var parts []string
+	for _, ev := range events {
+		switch {
+		case ev.Error != nil:
+			parts = append(parts, "error")
+		case ev.Value != nil:
+			parts = append(parts, fmt.Sprint(ev.Value))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestFollower(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	appendFile := func(data string) {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		require.Nil(t, err)
+		_, err = f.WriteString(data)
+		require.Nil(t, err)
+		require.Nil(t, f.Close())
+	}
+
+	var (
+		watcher = &chanWatcher{make(chan struct{}), make(chan struct{})}
+		notes   = make(chan string, 100)
+	)
+
+	f := bari.Follower{
+		Path:    path,
+		Watcher: watcher,
+		OnDocument: func(events []bari.Event) error {
+			notes <- summarize(events)
+			return nil
+		},
+		OnTruncate: func() { notes <- "truncate" },
+		OnRotate:   func() { notes <- "rotate" },
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- f.Run(ctx)
+	}()
+
+	// step makes the follower look at the file once, and returns what it reported.
+	step := func(change func()) []string {
+		<-watcher.waiting
+		change()
+		watcher.wake <- struct{}{}
+		<-watcher.waiting
+
+		var res []string
+		for {
+			select {
+			case n := <-notes:
+				res = append(res, n)
+			default:
+				go func() { watcher.waiting <- struct{}{} }()
+				return res
+			}
+		}
+	}
+
+	require.Nil(t, step(func() {}))
+	require.Nil(t, step(func() { appendFile(`{"a": `) }))
+	require.Equal(t, []string{"a,1"}, step(func() { appendFile("1}\n{\"b\"") }))
+	require.Equal(t, []string{"b,2", "c"}, step(func() { appendFile(": 2}\n[\n\"c\"\n]\n") }))
+	require.Equal(t, []string{"error", "d,true"}, step(func() { appendFile("oops\n{\"d\": true}\n{\"e\"") }))
+
+	require.Equal(t, []string{"rotate", "f,3"}, step(func() {
+		require.Nil(t, os.Rename(path, path+".1"))
+		appendFile(`{"f": 3}` + "\n")
+	}))
+
+	require.Equal(t, []string{"truncate", "5"}, step(func() {
+		require.Nil(t, os.Truncate(path, 0))
+		appendFile("[5]\n")
+	}))
+
+	<-watcher.waiting
+	cancel()
+
+	select {
+	case err := <-errCh:
+		require.Equal(t, context.Canceled, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not stop")
+	}
+}
+
+func TestFollowerWithoutOnDocument(t *testing.T) {
+	f := bari.Follower{Path: filepath.Join(t.TempDir(), "app.log")}
+	require.NotNil(t, f.Run(context.Background()))
+}
+
+// stopWatcher makes the follower stop the first time it waits for the file to change.
+type stopWatcher struct{}
+
+var errWatcherStopped = errors.New("watcher stopped")
+
+func (stopWatcher) Wait(ctx context.Context) error { return errWatcherStopped }
+
+func TestFollowerDispatchesEveryRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	// Larger than a single read.
+	data := strings.Repeat(`{"a": 1}`+"\n", 20000)
+	require.Nil(t, ioutil.WriteFile(path, []byte(data), 0644))
+
+	var docs []string
+	f := bari.Follower{
+		Path:    path,
+		Watcher: stopWatcher{},
+		OnDocument: func(events []bari.Event) error {
+			if len(docs) == 0 {
+				// The first document is handled before the whole file is read, so this one is read in the same pass.
+				file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+				require.Nil(t, err)
+				_, err = file.WriteString(`{"late": true}` + "\n")
+				require.Nil(t, err)
+				require.Nil(t, file.Close())
+			}
+			docs = append(docs, summarize(events))
+			return nil
+		},
+	}
+
+	require.Equal(t, errWatcherStopped, f.Run(context.Background()))
+	require.Len(t, docs, 20001)
+	require.Equal(t, "late,true", docs[len(docs)-1])
+}
+
+// growingReader is an in-memory input which grows over time. It returns io.EOF when it was read entirely.
+type growingReader struct {
+	mu   sync.Mutex
+	data []byte
+}
+
+func (r *growingReader) Read(b []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(b, r.data)
+	r.data = r.data[n:]
+
+	return n, nil
+}
+
+func (r *growingReader) append(s string) {
+	r.mu.Lock()
+	r.data = append(r.data, s...)
+	r.mu.Unlock()
+}
+
+func TestFollow(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r := &growingReader{}
+	r.append(`{"a": 1}` + "\n")
+
+	ch := make(chan bari.Event, 64)
+	errc := make(chan error)
+	go func() {
+		errc <- bari.NewParser(r, bari.Follow(ctx, time.Millisecond)).Parse(ch)
+	}()
+
+	next := func() []bari.Event {
+		var events []bari.Event
+		for len(events) == 0 || events[len(events)-1].Type != bari.ObjectEndEvent {
+			select {
+			case ev := <-ch:
+				events = append(events, ev)
+			case <-time.After(5 * time.Second):
+				t.Fatalf("no event after %v", events)
+			}
+		}
+		return events
+	}
+	require.Equal(t, "a,1", summarize(next()))
+
+	// The events of a document are only returned once it is complete.
+	r.append(`{"b": [2, `)
+	time.Sleep(20 * time.Millisecond)
+	require.Equal(t, 0, len(ch))
+
+	r.append(`3]}` + "\n" + `{"c": 4}`)
+	require.Equal(t, "b,2,3", summarize(next()))
+	require.Equal(t, "c,4", summarize(next()))
+
+	// The partial document is dropped once the context is done.
+	r.append(`[5, `)
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-errc:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the parse didn't end")
+	}
+	require.Equal(t, []bari.Event{{Type: bari.EOFEvent}}, []bari.Event{<-ch})
+	require.Equal(t, 0, len(ch))
+}
+
+func TestFollowExpandStringifiedJSON(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r := &growingReader{}
+	r.append(`{"a": "[1, 2]", "b": "{\"c\": 3}"}` + "\n")
+
+	ch := make(chan bari.Event, 64)
+	go bari.NewParser(r, bari.Follow(ctx, time.Millisecond), bari.ExpandStringifiedJSON(1)).Parse(ch)
+
+	// The expanded strings don't wait for more input.
+	var events []bari.Event
+	for len(events) == 0 || events[len(events)-1].Type != bari.ObjectEndEvent || events[len(events)-1].Depth > 0 {
+		select {
+		case ev := <-ch:
+			events = append(events, ev)
+		case <-time.After(time.Second):
+			t.Fatalf("no event after %v", events)
+		}
+	}
+	require.Equal(t, "a,1,2,b,c,3", summarize(events))
+}
+
+func TestFollowStop(t *testing.T) {
+	p := bari.NewParser(&growingReader{}, bari.Follow(context.Background(), time.Millisecond))
+
+	errc := make(chan error)
+	go func() {
+		errc <- p.ParseFunc(func(bari.Event) bool { return true })
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	p.Stop()
+
+	select {
+	case err := <-errc:
+		require.True(t, errors.Is(err, bari.ErrStopped), "err: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the parse didn't stop")
+	}
+}
@@ -0,0 +1,413 @@
+package bari
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// A Finding is a problem reported by a lint rule.
+type Finding struct {
+	// Rule is the ID of the rule which reported the finding.
+	Rule    string
+	Message string
+	// Pointer is the JSON pointer of the value the finding is about.
+	Pointer string
+	// Document is the index of the document in the input, starting at 0.
+	Document int
+	// Line, Column and Offset locate the value like the position of its Event: the first byte of a scalar, or the
+	// opening bracket of an object or an array.
+	Line   int
+	Column int
+	Offset int64
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("document %d, line %d, column %d: %s: %s (%s)",
+		f.Document, f.Line, f.Column, f.Pointer, f.Message, f.Rule)
+}
+
+// A Rule is a check run by a Linter.
+//
+// A Rule may keep state across the documents of an input, so a new one must be used for every run.
+type Rule interface {
+	// ID identifies the rule in findings.
+	ID() string
+	// Check is called with every value of the input and at the end of every object and array.
+	// Object keys are only available through LintContext.Key.
+	Check(c *LintContext, ev Event)
+}
+
+// A LintContext describes the location of an event checked by a Rule, and collects its findings.
+type LintContext struct {
+	tracker  pathTracker
+	document int
+
+	// at is the event of the current value, the start event of the container which ends for an end event.
+	// starts holds the start events of the enclosing containers.
+	at     Event
+	starts []Event
+
+	rule     string
+	findings []Finding
+}
+
+// Document returns the index of the current document, starting at 0.
+func (c *LintContext) Document() int {
+	return c.document
+}
+
+// Pointer returns the JSON pointer of the current value, or of the container which ends.
+func (c *LintContext) Pointer() string {
+	return c.tracker.pointer(false)
+}
+
+// Pattern is like Pointer, with every array index replaced by "-", so that it is the same
+// for the matching values of every element of an array.
+func (c *LintContext) Pattern() string {
+	return c.tracker.pointer(true)
+}
+
+// Depth returns the number of containers enclosing the current value, or the container which ends.
+func (c *LintContext) Depth() int {
+	if c.tracker.value {
+		return c.tracker.depth
+	}
+	return len(c.tracker.frames)
+}
+
+// Key returns the key of the current value if it is in an object.
+func (c *LintContext) Key() (string, bool) {
+	frames := c.tracker.frames
+	if !c.tracker.value || c.tracker.depth == 0 || frames[c.tracker.depth-1].array {
+		return "", false
+	}
+
+	return string(frames[c.tracker.depth-1].key), true
+}
+
+// Report adds a finding about the current value.
+func (c *LintContext) Report(format string, args ...interface{}) {
+	c.findings = append(c.findings, Finding{
+		Rule:     c.rule,
+		Message:  fmt.Sprintf(format, args...),
+		Pointer:  c.Pointer(),
+		Document: c.document,
+		Line:     c.at.Line,
+		Column:   c.at.Column,
+		Offset:   c.at.Offset,
+	})
+}
+
+// A Linter runs rules over every document of an input.
+type Linter struct {
+	Rules []Rule
+	// Disabled holds the IDs of the rules which must not be run.
+	Disabled map[string]bool
+}
+
+// DefaultRules returns new instances of every built-in rule, with their default settings.
+func DefaultRules() []Rule {
+	return []Rule{
+		&DuplicateKeyRule{},
+		&CaseOnlyKeyRule{},
+		&KeyCasingRule{},
+		&MixedNumberRule{},
+		&DeepNestingRule{MaxDepth: 32},
+		&StringifiedScalarRule{},
+	}
+}
+
+// Run parses the documents of r, calling fn with the findings of each document once the document is done.
+//
+// If fn returns an error or the input is not valid JSON, Run stops and returns the error.
+func (l *Linter) Run(r io.Reader, fn func(f Finding) error) error {
+	var rules []Rule
+	for _, rule := range l.Rules {
+		if !l.Disabled[rule.ID()] {
+			rules = append(rules, rule)
+		}
+	}
+
+	parser := NewParser(r, EventPositions())
+	ch := make(chan Event)
+	go parser.ParseAndClose(ch)
+	defer parser.Stop()
+
+	var c LintContext
+	for ev := range ch {
+		if ev.Error != nil {
+			return ev.Error
+		}
+
+		c.tracker.update(ev)
+
+		switch ev.Type {
+		case ObjectEndEvent, ArrayEndEvent:
+		default:
+			if !c.tracker.value {
+				continue
+			}
+		}
+
+		c.at = ev
+		switch ev.Type {
+		case ObjectStartEvent, ArrayStartEvent:
+			c.starts = append(c.starts, ev)
+		case ObjectEndEvent, ArrayEndEvent:
+			c.at = c.starts[len(c.starts)-1]
+			c.starts = c.starts[:len(c.starts)-1]
+		}
+
+		for _, rule := range rules {
+			c.rule = rule.ID()
+			rule.Check(&c, ev)
+		}
+
+		if len(c.tracker.frames) > 0 {
+			continue
+		}
+
+		for _, f := range c.findings {
+			if err := fn(f); err != nil {
+				return err
+			}
+		}
+		c.findings = c.findings[:0]
+		c.document++
+	}
+
+	return nil
+}
+
+// A DuplicateKeyRule reports keys appearing more than once in an object.
+type DuplicateKeyRule struct {
+	keys []map[string]bool
+}
+
+// ID implements Rule.
+func (r *DuplicateKeyRule) ID() string { return "duplicate-key" }
+
+// Check implements Rule.
+func (r *DuplicateKeyRule) Check(c *LintContext, ev Event) {
+	if key, ok := c.Key(); ok {
+		keys := r.keys[len(r.keys)-1]
+		if keys[key] {
+			c.Report("duplicate key %q", key)
+		}
+		keys[key] = true
+	}
+
+	switch ev.Type {
+	case ObjectStartEvent:
+		r.keys = append(r.keys, make(map[string]bool))
+	case ObjectEndEvent:
+		r.keys = r.keys[:len(r.keys)-1]
+	}
+}
+
+// A CaseOnlyKeyRule reports keys of an object which only differ from another one by case, like "id" and "ID".
+type CaseOnlyKeyRule struct {
+	keys []map[string]string
+}
+
+// ID implements Rule.
+func (r *CaseOnlyKeyRule) ID() string { return "case-only-key" }
+
+// Check implements Rule.
+func (r *CaseOnlyKeyRule) Check(c *LintContext, ev Event) {
+	if key, ok := c.Key(); ok {
+		keys := r.keys[len(r.keys)-1]
+
+		folded := strings.ToLower(key)
+		if prev, ok := keys[folded]; ok && prev != key {
+			c.Report("key %q only differs by case from %q", key, prev)
+		} else if !ok {
+			keys[folded] = key
+		}
+	}
+
+	switch ev.Type {
+	case ObjectStartEvent:
+		r.keys = append(r.keys, make(map[string]string))
+	case ObjectEndEvent:
+		r.keys = r.keys[:len(r.keys)-1]
+	}
+}
+
+// A KeyCasingRule reports objects of an array whose keys use another casing convention than
+// the previous objects of the array, like "user_id" in an object and "userId" in the next one.
+//
+// Keys made of a single lowercase word fit every convention, and objects mixing conventions are ignored.
+type KeyCasingRule struct {
+	frames []casingFrame
+}
+
+type casingFrame struct {
+	// style is the casing of the keys of an object, or of the first objects of an array.
+	style string
+	mixed bool
+}
+
+// ID implements Rule.
+func (r *KeyCasingRule) ID() string { return "key-casing" }
+
+// Check implements Rule.
+func (r *KeyCasingRule) Check(c *LintContext, ev Event) {
+	if key, ok := c.Key(); ok {
+		f := &r.frames[len(r.frames)-1]
+		if style := keyStyle(key); style != "" && !f.mixed {
+			if f.style != "" && f.style != style {
+				f.mixed = true
+			}
+			f.style = style
+		}
+	}
+
+	switch ev.Type {
+	case ObjectStartEvent, ArrayStartEvent:
+		r.frames = append(r.frames, casingFrame{})
+	case ArrayEndEvent:
+		r.frames = r.frames[:len(r.frames)-1]
+	case ObjectEndEvent:
+		obj := r.frames[len(r.frames)-1]
+		r.frames = r.frames[:len(r.frames)-1]
+
+		n := len(r.frames)
+		if n == 0 || obj.style == "" || obj.mixed || !c.tracker.frames[n-1].array {
+			return
+		}
+
+		parent := &r.frames[n-1]
+		switch parent.style {
+		case "":
+			parent.style = obj.style
+		case obj.style:
+		default:
+			c.Report("keys are %s but the previous objects use %s", obj.style, parent.style)
+		}
+	}
+}
+
+// keyStyle returns the casing convention of key, or "" if it is ambiguous.
+func keyStyle(key string) string {
+	var upper, lower, underscore, dash bool
+	for _, r := range key {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case r == '_':
+			underscore = true
+		case r == '-':
+			dash = true
+		}
+	}
+
+	first := []rune(key + " ")[0]
+
+	switch {
+	case underscore && !dash && upper != lower:
+		if upper {
+			return "SCREAMING_SNAKE_CASE"
+		}
+		return "snake_case"
+	case dash && !underscore && lower && !upper:
+		return "kebab-case"
+	case underscore || dash || !upper || !lower:
+		return ""
+	case unicode.IsUpper(first):
+		return "PascalCase"
+	default:
+		return "camelCase"
+	}
+}
+
+// A MixedNumberRule reports numbers which are integers where other numbers at the same location
+// are not, or the other way around, across the elements of arrays and the documents of the input.
+//
+// It reports a location once, at the first number of the other kind.
+type MixedNumberRule struct {
+	seen map[string]bool
+	done map[string]bool
+}
+
+// ID implements Rule.
+func (r *MixedNumberRule) ID() string { return "mixed-number" }
+
+// Check implements Rule.
+func (r *MixedNumberRule) Check(c *LintContext, ev Event) {
+	if ev.Type != NumberEvent {
+		return
+	}
+
+	if r.seen == nil {
+		r.seen = make(map[string]bool)
+		r.done = make(map[string]bool)
+	}
+
+	pattern := c.Pattern()
+
+	isInt, ok := r.seen[pattern]
+	switch {
+	case !ok:
+		r.seen[pattern] = ev.IsInt
+	case isInt != ev.IsInt && !r.done[pattern]:
+		r.done[pattern] = true
+		if ev.IsInt {
+			c.Report("integer where previous numbers are floats")
+		} else {
+			c.Report("float where previous numbers are integers")
+		}
+	}
+}
+
+// A DeepNestingRule reports containers nested deeper than MaxDepth levels. Only the outermost
+// container past the limit is reported.
+type DeepNestingRule struct {
+	MaxDepth int
+}
+
+// ID implements Rule.
+func (r *DeepNestingRule) ID() string { return "deep-nesting" }
+
+// Check implements Rule.
+func (r *DeepNestingRule) Check(c *LintContext, ev Event) {
+	switch ev.Type {
+	case ObjectStartEvent, ArrayStartEvent:
+		if c.Depth() == r.MaxDepth {
+			c.Report("nested deeper than %d levels", r.MaxDepth)
+		}
+	}
+}
+
+// A StringifiedScalarRule reports strings holding a JSON number, boolean or null, like "42" or "true".
+type StringifiedScalarRule struct{}
+
+// ID implements Rule.
+func (r *StringifiedScalarRule) ID() string { return "stringified-scalar" }
+
+// Check implements Rule.
+func (r *StringifiedScalarRule) Check(c *LintContext, ev Event) {
+	if ev.Type != StringEvent {
+		return
+	}
+
+	s := ev.Value.(string)
+	switch {
+	case s == "true" || s == "false":
+		c.Report("boolean in a string: %q", s)
+	case s == "null":
+		c.Report("null in a string")
+	case isNumberLiteral(s):
+		c.Report("number in a string: %q", s)
+	}
+}
+
+// isNumberLiteral reports whether s is a number as defined by the JSON grammar.
+func isNumberLiteral(s string) bool {
+	i, _ := checkNumber([]byte(s))
+	return i < 0
+}
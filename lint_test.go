@@ -0,0 +1,144 @@
+package bari_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/bari"
+)
+
+func lintAll(t *testing.T, data string, rules ...bari.Rule) []bari.Finding {
+	l := bari.Linter{Rules: rules}
+
+	var findings []bari.Finding
+	err := l.Run(strings.NewReader(data), func(f bari.Finding) error {
+		findings = append(findings, f)
+		return nil
+	})
+	require.Nil(t, err)
+
+	return findings
+}
+
+func TestLintRules(t *testing.T) {
+	testCases := []struct {
+		name     string
+		rule     bari.Rule
+		data     string
+		findings []bari.Finding
+	}{
+		{
+			"duplicate key", &bari.DuplicateKeyRule{},
+			`{"a": 1, "b": {"a": 2}, "a": 3}`,
+			[]bari.Finding{
+				{Rule: "duplicate-key", Message: `duplicate key "a"`, Pointer: "/a", Line: 1, Column: 30, Offset: 29},
+			},
+		},
+		{
+			"case only key", &bari.CaseOnlyKeyRule{},
+			`{"id": 1, "Id": 2, "x": {"ID": 3}}`,
+			[]bari.Finding{
+				{Rule: "case-only-key", Message: `key "Id" only differs by case from "id"`, Pointer: "/Id", Line: 1, Column: 17, Offset: 16},
+			},
+		},
+		{
+			"key casing", &bari.KeyCasingRule{},
+			`[{"user_id": 1}, {"id": 2}, {"userId": 3}, {"a_b": 1, "aB": 2}]`,
+			[]bari.Finding{
+				{Rule: "key-casing", Message: "keys are camelCase but the previous objects use snake_case", Pointer: "/2", Line: 1, Column: 29, Offset: 28},
+			},
+		},
+		{
+			"mixed number", &bari.MixedNumberRule{},
+			`[{"a": 1}, {"a": 2.5}, {"a": 3.5}] {"b": 1e3} {"b": 1}`,
+			[]bari.Finding{
+				{Rule: "mixed-number", Message: "float where previous numbers are integers", Pointer: "/1/a", Line: 1, Column: 18, Offset: 17},
+				{Rule: "mixed-number", Message: "integer where previous numbers are floats", Pointer: "/b", Document: 2, Line: 1, Column: 53, Offset: 52},
+			},
+		},
+		{
+			"deep nesting", &bari.DeepNestingRule{MaxDepth: 2},
+			`{"a": [[1]], "b": [[[[]]]]}`,
+			[]bari.Finding{
+				{Rule: "deep-nesting", Message: "nested deeper than 2 levels", Pointer: "/a/0", Line: 1, Column: 8, Offset: 7},
+				{Rule: "deep-nesting", Message: "nested deeper than 2 levels", Pointer: "/b/0", Line: 1, Column: 20, Offset: 19},
+			},
+		},
+		{
+			"stringified scalar", &bari.StringifiedScalarRule{},
+			`{"a": "12", "b": "-1.5e3", "c": "false", "d": "null", "e": "012", "f": "1.", "g": "yes", "12": 1}`,
+			[]bari.Finding{
+				{Rule: "stringified-scalar", Message: `number in a string: "12"`, Pointer: "/a", Line: 1, Column: 7, Offset: 6},
+				{Rule: "stringified-scalar", Message: `number in a string: "-1.5e3"`, Pointer: "/b", Line: 1, Column: 18, Offset: 17},
+				{Rule: "stringified-scalar", Message: `boolean in a string: "false"`, Pointer: "/c", Line: 1, Column: 33, Offset: 32},
+				{Rule: "stringified-scalar", Message: "null in a string", Pointer: "/d", Line: 1, Column: 47, Offset: 46},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.findings, lintAll(t, tc.data, tc.rule))
+		})
+	}
+}
+
+func TestLintMessy(t *testing.T) {
+	f, err := os.Open("./testdata/lint/messy.ndjson")
+	require.Nil(t, err)
+	defer f.Close()
+
+	rules := bari.DefaultRules()
+	rules[4] = &bari.DeepNestingRule{MaxDepth: 3}
+
+	l := bari.Linter{Rules: rules}
+
+	var findings []string
+	err = l.Run(f, func(f bari.Finding) error {
+		findings = append(findings, f.String())
+		return nil
+	})
+	require.Nil(t, err)
+
+	exp := []string{
+		`document 0, line 1, column 61: /active: boolean in a string: "true" (stringified-scalar)`,
+		`document 0, line 1, column 89: /meta/a/b: nested deeper than 3 levels (deep-nesting)`,
+		`document 1, line 2, column 17: /ID: key "ID" only differs by case from "id" (case-only-key)`,
+		`document 1, line 2, column 42: /price: float where previous numbers are integers (mixed-number)`,
+		`document 1, line 2, column 57: /count: number in a string: "12" (stringified-scalar)`,
+		`document 1, line 2, column 89: /items/1: keys are camelCase but the previous objects use snake_case (key-casing)`,
+		`document 2, line 3, column 17: /id: duplicate key "id" (duplicate-key)`,
+		`document 2, line 3, column 55: /items/0/item_id: float where previous numbers are integers (mixed-number)`,
+	}
+	require.Equal(t, exp, findings)
+}
+
+func TestLintDisabled(t *testing.T) {
+	l := bari.Linter{
+		Rules:    []bari.Rule{&bari.DuplicateKeyRule{}, &bari.StringifiedScalarRule{}},
+		Disabled: map[string]bool{"stringified-scalar": true},
+	}
+
+	var findings []bari.Finding
+	err := l.Run(strings.NewReader(`{"a": "1", "a": "2"}`), func(f bari.Finding) error {
+		findings = append(findings, f)
+		return nil
+	})
+	require.Nil(t, err)
+	require.Len(t, findings, 1)
+	require.Equal(t, "duplicate-key", findings[0].Rule)
+}
+
+func TestLintErrors(t *testing.T) {
+	l := bari.Linter{Rules: bari.DefaultRules()}
+
+	err := l.Run(strings.NewReader(`{"a": "1"} {"a": `), func(f bari.Finding) error { return nil })
+	require.NotNil(t, err)
+
+	errStop := errors.New("stop")
+	err = l.Run(strings.NewReader(`{"a": "1"}`), func(f bari.Finding) error { return errStop })
+	require.Equal(t, errStop, err)
+}
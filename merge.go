@@ -0,0 +1,124 @@
+package bari
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// A Source is a named input of a Merger.
+type Source struct {
+	Label  string
+	Reader io.Reader
+}
+
+// A TaggedEvent is an event emitted while parsing the source named Source.
+type TaggedEvent struct {
+	Source string
+	Event
+}
+
+// A Merger parses several sources concurrently and merges their events into a single stream.
+//
+// The events of a document are never interleaved with the events of another document: a source's
+// events are forwarded one whole document at a time, in the order the documents are complete.
+// Each source ends with exactly one EOFEvent, whose Error is nil if the source ended cleanly.
+type Merger struct {
+	Sources []Source
+
+	// StopOnError makes Run stop as soon as a source fails, instead of carrying on with the others.
+	StopOnError bool
+}
+
+// Run merges the events of the sources into out until every source is done, the cancellation
+// of ctx, or the failure of a source if StopOnError is set. It closes out when it returns.
+//
+// The parsers of a stopped Merger are stopped too, so that their sources aren't read anymore.
+func (m *Merger) Run(ctx context.Context, out chan<- TaggedEvent) error {
+	defer close(out)
+
+	var (
+		wg      sync.WaitGroup
+		batches = make(chan []TaggedEvent)
+		done    = make(chan struct{})
+	)
+	defer close(done)
+
+	for _, src := range m.Sources {
+		wg.Add(1)
+		go func(src Source) {
+			defer wg.Done()
+			collectDocuments(src, batches, done)
+		}(src)
+	}
+	go func() {
+		wg.Wait()
+		close(batches)
+	}()
+
+	for {
+		select {
+		case batch, ok := <-batches:
+			if !ok {
+				return nil
+			}
+
+			for _, ev := range batch {
+				select {
+				case out <- ev:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+
+			if last := batch[len(batch)-1]; m.StopOnError && last.Error != nil {
+				return last.Error
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// collectDocuments parses src and sends its events to batches, one document at a time.
+func collectDocuments(src Source, batches chan<- []TaggedEvent, done <-chan struct{}) {
+	parser := NewParser(src.Reader)
+	ch := make(chan Event)
+
+	go parser.ParseAndClose(ch)
+	defer parser.Stop()
+
+	var (
+		batch []TaggedEvent
+		depth int
+	)
+	send := func() bool {
+		select {
+		case batches <- batch:
+			batch = nil
+			return true
+		case <-done:
+			return false
+		}
+	}
+
+	for ev := range ch {
+		batch = append(batch, TaggedEvent{src.Label, ev})
+
+		switch ev.Type {
+		case ObjectStartEvent, ArrayStartEvent:
+			depth++
+		case ObjectEndEvent, ArrayEndEvent:
+			depth--
+			if depth == 0 && !send() {
+				return
+			}
+		case EOFEvent:
+			send()
+			return
+		}
+	}
+
+	batch = append(batch, TaggedEvent{src.Label, Event{Type: EOFEvent}})
+	send()
+}
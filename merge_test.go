@@ -0,0 +1,173 @@
+package bari_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/bari"
+)
+
+type slowReader struct {
+	r     io.Reader
+	delay time.Duration
+}
+
+func (r *slowReader) Read(b []byte) (int, error) {
+	time.Sleep(r.delay)
+	if len(b) > 3 {
+		b = b[:3]
+	}
+	return r.r.Read(b)
+}
+
+func makeDocuments(n int, label string) string {
+	var docs []string
+	for i := 0; i < n; i++ {
+		docs = append(docs, fmt.Sprintf(`{"source": %q, "n": [%d, {"x": true}]}`, label, i))
+	}
+	return strings.Join(docs, "\n")
+}
+
+func mergeAll(t testing.TB, m *bari.Merger) ([]bari.TaggedEvent, error) {
+	out := make(chan bari.TaggedEvent)
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- m.Run(context.Background(), out)
+	}()
+
+	var events []bari.TaggedEvent
+	for ev := range out {
+		events = append(events, ev)
+	}
+
+	return events, <-errCh
+}
+
+func TestMerger(t *testing.T) {
+	sources := map[string]string{
+		"fast":   makeDocuments(50, "fast"),
+		"slow":   makeDocuments(5, "slow"),
+		"medium": makeDocuments(10, "medium"),
+	}
+
+	m := bari.Merger{
+		Sources: []bari.Source{
+			{"fast", strings.NewReader(sources["fast"])},
+			{"slow", &slowReader{strings.NewReader(sources["slow"]), time.Millisecond}},
+			{"medium", &slowReader{strings.NewReader(sources["medium"]), 100 * time.Microsecond}},
+		},
+	}
+
+	events, err := mergeAll(t, &m)
+	require.Nil(t, err)
+
+	var (
+		depth   int
+		current string
+		bySrc   = make(map[string][]bari.Event)
+	)
+	for _, ev := range events {
+		if depth > 0 {
+			require.Equal(t, current, ev.Source, "documents must not be interleaved")
+		}
+		current = ev.Source
+
+		switch ev.Type {
+		case bari.ObjectStartEvent, bari.ArrayStartEvent:
+			depth++
+		case bari.ObjectEndEvent, bari.ArrayEndEvent:
+			depth--
+		}
+
+		bySrc[ev.Source] = append(bySrc[ev.Source], ev.Event)
+	}
+
+	for label, data := range sources {
+		exp := append(parseAll(data), bari.Event{Type: bari.EOFEvent})
+		require.Equal(t, exp, bySrc[label], label)
+	}
+
+	// the fast source is done long before the slow one
+	var fastEnd, slowEnd int
+	for i, ev := range events {
+		if ev.Type == bari.EOFEvent && ev.Source == "fast" {
+			fastEnd = i
+		}
+		if ev.Type == bari.EOFEvent && ev.Source == "slow" {
+			slowEnd = i
+		}
+	}
+	require.True(t, fastEnd < slowEnd)
+}
+
+func TestMergerSourceError(t *testing.T) {
+	m := bari.Merger{
+		Sources: []bari.Source{
+			{"good", &slowReader{strings.NewReader(makeDocuments(5, "good")), time.Millisecond}},
+			{"bad", strings.NewReader(`{"a": 1} {"b": }`)},
+		},
+	}
+
+	events, err := mergeAll(t, &m)
+	require.Nil(t, err)
+
+	ends := make(map[string]error)
+	for _, ev := range events {
+		if ev.Type == bari.EOFEvent {
+			ends[ev.Source] = ev.Error
+		}
+	}
+	require.Equal(t, 2, len(ends))
+	require.Nil(t, ends["good"])
+	require.NotNil(t, ends["bad"])
+
+	m = bari.Merger{
+		Sources: []bari.Source{
+			{"good", &slowReader{strings.NewReader(makeDocuments(5, "good")), time.Millisecond}},
+			{"bad", strings.NewReader(`{"a": 1} {"b": }`)},
+		},
+		StopOnError: true,
+	}
+
+	events, err = mergeAll(t, &m)
+	require.NotNil(t, err)
+	require.Equal(t, err, events[len(events)-1].Error)
+}
+
+func TestMergerCancel(t *testing.T) {
+	m := bari.Merger{
+		Sources: []bari.Source{
+			{"a", strings.NewReader(makeDocuments(100, "a"))},
+			{"b", strings.NewReader(makeDocuments(100, "b"))},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := make(chan bari.TaggedEvent)
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- m.Run(ctx, out)
+	}()
+
+	for i := 0; i < 3; i++ {
+		<-out
+	}
+	cancel()
+
+	select {
+	case err := <-errCh:
+		require.Equal(t, context.Canceled, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not stop")
+	}
+
+	for range out {
+	}
+}
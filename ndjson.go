@@ -0,0 +1,131 @@
+package bari
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// A RecordFilter selects records of a newline-delimited JSON stream, rejecting most of them before they are parsed.
+//
+// A record is first checked against Substrings and Prefilter using only its raw bytes. Since a substring may
+// also appear in an unrelated part of a record, the records which pass are then parsed and checked against Match.
+type RecordFilter struct {
+	// Substrings lists byte strings of which a record must contain at least one. If it is empty, every record passes.
+	Substrings [][]byte
+	// Prefilter, if set, must return true for a record to be parsed.
+	Prefilter func(raw []byte) bool
+	// Match, if set, must return true for the events of a record for it to be selected.
+	Match func(events []Event) bool
+
+	// Skipped is the number of records rejected without being parsed.
+	Skipped int64
+	// Parsed is the number of records which were parsed.
+	Parsed int64
+}
+
+// Run reads records from r and calls fn with the raw bytes of every selected record, without the line terminator.
+//
+// raw is only valid until fn returns. If fn returns an error, Run stops and returns it.
+func (f *RecordFilter) Run(r io.Reader, fn func(raw []byte) error) error {
+	return readRecords(r, func(line int, raw []byte) error {
+		if !f.prefilter(raw) {
+			return nil
+		}
+
+		events, err := parseRecord(raw)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if f.Match != nil && !f.Match(events) {
+			return nil
+		}
+
+		return fn(raw)
+	})
+}
+
+// prefilter checks raw against the substrings and the prefilter, and counts the outcome.
+func (f *RecordFilter) prefilter(raw []byte) bool {
+	ok := len(f.Substrings) == 0
+	for _, s := range f.Substrings {
+		if bytes.Contains(raw, s) {
+			ok = true
+			break
+		}
+	}
+
+	if ok && f.Prefilter != nil {
+		ok = f.Prefilter(raw)
+	}
+
+	if ok {
+		f.Parsed++
+	} else {
+		f.Skipped++
+	}
+
+	return ok
+}
+
+// readRecords calls fn with the line number and the content of each non-blank line of r, without the line terminator.
+//
+// raw is only valid until fn returns.
+func readRecords(r io.Reader, fn func(line int, raw []byte) error) error {
+	var (
+		br   = bufio.NewReaderSize(r, 64*1024)
+		long []byte
+	)
+
+	for line := 1; ; line++ {
+		raw, err := br.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			long = append(long[:0], raw...)
+			for err == bufio.ErrBufferFull {
+				raw, err = br.ReadSlice('\n')
+				long = append(long, raw...)
+			}
+			raw = long
+		}
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		raw = bytes.TrimRight(raw, "\r\n")
+		if len(bytes.TrimSpace(raw)) > 0 {
+			if ferr := fn(line, raw); ferr != nil {
+				return ferr
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// parseRecord returns all the events of raw, or the error of the first invalid document.
+func parseRecord(raw []byte) ([]Event, error) {
+	events := parseEvents(raw)
+	if n := len(events); n > 0 && events[n-1].Error != nil {
+		return nil, events[n-1].Error
+	}
+
+	return events, nil
+}
+
+// parseEvents returns all the events of raw, the last one carrying the error if it is invalid.
+func parseEvents(raw []byte) []Event {
+	var events []Event
+	NewParser(bytes.NewReader(raw)).ParseFunc(func(ev Event) bool {
+		events = append(events, ev)
+		return true
+	})
+
+	if n := len(events); n > 0 && events[n-1].Type == EOFEvent && events[n-1].Error == nil {
+		events = events[:n-1]
+	}
+
+	return events
+}
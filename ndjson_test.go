@@ -0,0 +1,128 @@
+package bari_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/bari"
+)
+
+func TestRecordFilter(t *testing.T) {
+	const data = `{"id": "abc", "name": "foo"}
+{"id": "def", "name": "abc"}
+
+{"id": "ghi", "name": "bar"}
+{"id": "abc", "name": "baz"}
+`
+
+	f := bari.RecordFilter{
+		Substrings: [][]byte{[]byte(`"abc"`)},
+		Match: func(events []bari.Event) bool {
+			// the value of the first key is the id
+			return events[4].Value == "abc"
+		},
+	}
+
+	var res []string
+	err := f.Run(strings.NewReader(data), func(raw []byte) error {
+		res = append(res, string(raw))
+		return nil
+	})
+	require.Nil(t, err)
+
+	require.Equal(t, []string{`{"id": "abc", "name": "foo"}`, `{"id": "abc", "name": "baz"}`}, res)
+	require.Equal(t, int64(1), f.Skipped)
+	require.Equal(t, int64(3), f.Parsed)
+}
+
+func TestRecordFilterPrefilterFunc(t *testing.T) {
+	const data = "[1]\r\n[2]\r\n" + `["` + "\x00" + `"]` + "\r\n[3]"
+
+	f := bari.RecordFilter{
+		Prefilter: func(raw []byte) bool {
+			return bytes.IndexByte(raw, 0) < 0
+		},
+	}
+
+	var res []string
+	err := f.Run(strings.NewReader(data), func(raw []byte) error {
+		res = append(res, string(raw))
+		return nil
+	})
+	require.Nil(t, err)
+	require.Equal(t, []string{"[1]", "[2]", "[3]"}, res)
+	require.Equal(t, int64(1), f.Skipped)
+}
+
+func TestRecordFilterLongRecord(t *testing.T) {
+	long := `["` + strings.Repeat("a", 200*1024) + `", "needle"]`
+	data := "[1]\n" + long + "\n[2]\n"
+
+	f := bari.RecordFilter{Substrings: [][]byte{[]byte("needle")}}
+
+	var res []string
+	err := f.Run(strings.NewReader(data), func(raw []byte) error {
+		res = append(res, string(raw))
+		return nil
+	})
+	require.Nil(t, err)
+	require.Equal(t, []string{long}, res)
+}
+
+func TestRecordFilterInvalidRecord(t *testing.T) {
+	f := bari.RecordFilter{Substrings: [][]byte{[]byte("a")}}
+
+	err := f.Run(strings.NewReader("[1]\n[\"a\"\n"), func(raw []byte) error { return nil })
+	require.NotNil(t, err)
+	require.True(t, strings.HasPrefix(err.Error(), "line 2: "))
+}
+
+func makeRecords(n, every int) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("user-%d", i)
+		if i%every == 0 {
+			id = "needle"
+		}
+		fmt.Fprintf(&buf, `{"id": %q, "seq": %d, "tags": ["a", "b", "c"], "payload": {"ok": true, "size": 1.5}}`+"\n", id, i)
+	}
+
+	return buf.Bytes()
+}
+
+func benchmarkRecordFilter(b *testing.B, substrings [][]byte) {
+	data := makeRecords(10000, 1000)
+
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		f := bari.RecordFilter{
+			Substrings: substrings,
+			Match: func(events []bari.Event) bool {
+				return events[4].Value == "needle"
+			},
+		}
+
+		n := 0
+		err := f.Run(bytes.NewReader(data), func(raw []byte) error {
+			n++
+			return nil
+		})
+		if err != nil || n != 10 {
+			b.Fatal(err, n)
+		}
+	}
+}
+
+func BenchmarkRecordFilterSubstrings(b *testing.B) {
+	benchmarkRecordFilter(b, [][]byte{[]byte(`"needle"`)})
+}
+
+func BenchmarkRecordFilterParseAll(b *testing.B) {
+	benchmarkRecordFilter(b, nil)
+}
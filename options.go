@@ -0,0 +1,546 @@
+package bari
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// An Option configures optional behavior of a Parser.
+type Option func(*options)
+
+type options struct {
+	expandDepth int
+	indexLines  bool
+	positions   bool
+	seq         bool
+	typedValues bool
+	byteStrings bool
+	maxDepth    int
+
+	// disableStats turns off the counters of Stats. progress is called every progressEvery bytes, and tee receives
+	// the bytes parsed.
+	disableStats  bool
+	progress      func(offset int64)
+	progressEvery int64
+	tee           io.Writer
+
+	// followCtx and followInterval configure Follow.
+	followCtx      context.Context
+	followInterval time.Duration
+	// sendTimeout is how long Parse waits for an event to be received, and timeout how long the parser may run.
+	sendTimeout time.Duration
+	timeout     time.Duration
+
+	compactEvents  bool
+	documentEvents bool
+
+	requireDocument bool
+	singleDocument  bool
+
+	xssiPrefixes   []string
+	lenientNumbers bool
+	nanInf         bool
+	hexNumbers     bool
+	pythonLiterals bool
+	overflow       OverflowMode
+
+	lenientWhitespace bool
+	allowComments     bool
+	allowHashComments bool
+
+	allowTrailingCommas bool
+	singleQuotedStrings bool
+	unquotedKeys        bool
+	lineContinuations   bool
+	unicodeWhitespace   bool
+	// json5 is set by JSON5, for the messages of the errors about its unsupported syntax.
+	json5 bool
+
+	rejectLoneSurrogates bool
+	disallowInvalidUTF8  bool
+	disallowNul          bool
+
+	disallowDuplicateKeys bool
+
+	strict bool
+
+	collectErrors     bool
+	skipInvalidValues bool
+
+	// validate is set by Valid and ValidReader: the values are checked but not decoded. Neither are the keys if
+	// validateKeys is not set, which leaves the paths of errors incomplete.
+	validate     bool
+	validateKeys bool
+}
+
+// extendedSyntax reports whether the input may hold comments or single-quoted strings, which the scanner of
+// SkipValue doesn't know about.
+func (o *options) extendedSyntax() bool {
+	return o.allowComments || o.allowHashComments || o.singleQuotedStrings || o.lineContinuations
+}
+
+// ExpandStringifiedJSON makes the parser expand string values which contain a serialized JSON object or array,
+// such as the "payload" in {"payload": "{\"a\": 1}"}.
+//
+// The events of the nested document are spliced in place of the StringEvent the value would otherwise produce,
+// so the consumer sees the same events as if the document had not been serialized into a string.
+// Strings nested in the expanded document are expanded too, up to depth levels.
+//
+// Object keys are never expanded, and a string which does not hold exactly one valid document is emitted as a
+// plain StringEvent.
+func ExpandStringifiedJSON(depth int) Option {
+	return func(o *options) {
+		o.expandDepth = depth
+	}
+}
+
+// IndexLines makes the parser record the offset of every line it reads, so that a PositionIndex
+// is available once parsing is done.
+func IndexLines() Option {
+	return func(o *options) {
+		o.indexLines = true
+	}
+}
+
+// EventPositions makes the parser set the position of every event, in its Line, Column and Offset fields, and its
+// span in StartOffset and EndOffset.
+func EventPositions() Option {
+	return func(o *options) {
+		o.positions = true
+	}
+}
+
+// SequenceNumbers makes the parser number the events it returns, in their Seq field, from 0 and across documents
+// until it is Reset, so that events dispatched to several consumers can be put back in order.
+func SequenceNumbers() Option {
+	return func(o *options) {
+		o.seq = true
+	}
+}
+
+// DisableStats makes the parser skip counting the events, documents and strings it parses, for the hottest paths:
+// Stats then only reports the number of bytes read.
+func DisableStats() Option {
+	return func(o *options) {
+		o.disableStats = true
+	}
+}
+
+// Progress makes the parser call fn with the number of bytes it parsed every time it parsed about every more bytes,
+// and once it reaches the end of the input, to report the progress of a long parse. The bytes buffered but not
+// parsed yet are not counted.
+//
+// fn is called synchronously by the parser, while it reads its input: it must be fast.
+func Progress(every int64, fn func(offset int64)) Option {
+	return func(o *options) {
+		o.progress = fn
+		o.progressEvery = every
+	}
+}
+
+// Tee makes the parser write to w the bytes it parses, unlike an io.TeeReader which would receive the bytes read
+// ahead too. The bytes of a document are written once its last byte is parsed, before the event ending it is
+// returned, so that w holds every document returned so far and nothing more. The whitespace following a document is
+// written with the next one, or once the end of the input is reached. An error writing to w fails the parser.
+func Tee(w io.Writer) Option {
+	return func(o *options) {
+		o.tee = w
+	}
+}
+
+// Follow makes the parser wait for more input when its reader reaches the end of the input, like tail -f, reading
+// it again every interval until ctx is done. The events of a document are only returned once the document is
+// complete. Once ctx is done, the parser ends as if the input ended cleanly, dropping the document it was reading.
+//
+// A parser reading a byte slice waits for ctx to be done at the end of its input.
+func Follow(ctx context.Context, interval time.Duration) Option {
+	return func(o *options) {
+		o.followCtx = ctx
+		o.followInterval = interval
+	}
+}
+
+// SendTimeout makes Parse, ParseAndClose and ParseOne fail if an event is not received within d, so that a stalled
+// consumer doesn't block the parser forever. The parser is then stopped, and they return a ParseError wrapping
+// ErrConsumerStalled, without sending it.
+func SendTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.sendTimeout = d
+	}
+}
+
+// Timeout makes the parser stop once d elapsed since it started reading, like Stop, with a ParseError wrapping
+// ErrTimeout, to bound the time taken by a slow input. The time starts again when the parser is used after it
+// reached the end of its input, or is Reset. It is checked for every byte read, but a read blocked on the
+// underlying reader can't be interrupted: the parser stops once it returns.
+//
+// It composes with ParseContext and NextContext, the first of the timeout and the context stopping the parser.
+func Timeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// TypedValues makes the parser leave the Value of the events of strings and numbers nil, their values being only
+// in the typed fields of the events. This saves an allocation for each of them.
+//
+// The integers which don't fit in an int64 and are not converted to a float64 are still in Value,
+// see IntegerOverflow.
+func TypedValues() Option {
+	return func(o *options) {
+		o.typedValues = true
+	}
+}
+
+// ByteStrings makes the parser emit the strings as byte slices, in the Bytes field of their events, instead of
+// converting them to Go strings. Their ValueKind is BytesValue, and their Value nil.
+//
+// The bytes belong to the parser: they are only valid until the next call to Next or Peek, which may overwrite
+// them, and must not be modified. Event.Copy makes a copy which can be retained. The strings of a parser reading
+// a byte slice which have no escape sequence are slices of it.
+//
+// The events of Parse and ParseContext are sent as copies, since the parser runs ahead of the consumer. To avoid
+// the copies, use Next or ParseFunc.
+func ByteStrings() Option {
+	return func(o *options) {
+		o.byteStrings = true
+	}
+}
+
+// CompactEvents makes the parser emit the key of each member of an object as an ObjectKeyEvent holding the key,
+// like a StringEvent, instead of an ObjectKeyEvent followed by a StringEvent. The ObjectValueEvent following the key
+// is not emitted either. A member is then made of the ObjectKeyEvent and the events of its value, so that
+// {"a": [1]} produces:
+//
+//	ObjectStartEvent
+//	ObjectKeyEvent "a"
+//	ArrayStartEvent
+//	NumberEvent 1
+//	ArrayEndEvent
+//	ObjectEndEvent
+func CompactEvents() Option {
+	return func(o *options) {
+		o.compactEvents = true
+	}
+}
+
+// DocumentEvents makes the parser emit a DocumentStartEvent before each top-level value and a DocumentEndEvent
+// after it, so that the documents of a stream can be told apart without tracking the depth of the events.
+// Their Document is the index of the document, and their Offset its start and end, even without EventPositions.
+func DocumentEvents() Option {
+	return func(o *options) {
+		o.documentEvents = true
+	}
+}
+
+// DefaultMaxDepth is the maximum nesting of objects and arrays of a parser created without MaxDepth.
+const DefaultMaxDepth = 10000
+
+// MaxDepth limits the nesting of objects and arrays to depth levels. Input nested deeper fails with a ParseError
+// wrapping a LimitError for MaxDepthLimit.
+//
+// A depth of 0 or less removes the limit. Nesting doesn't consume stack space, so only memory bounds the depth
+// of the input then.
+func MaxDepth(depth int) Option {
+	return func(o *options) {
+		o.maxDepth = depth
+	}
+}
+
+// RequireDocument makes the parser report an unexpected end of file when the input holds no document, like when
+// it is empty or only made of whitespace. By default, such input is a clean end of stream.
+func RequireDocument() Option {
+	return func(o *options) {
+		o.requireDocument = true
+	}
+}
+
+// SingleDocument makes the parser reject anything but whitespace after the first document, instead of parsing
+// the input as a stream of documents. It suits input which must hold a single value, like a request body.
+func SingleDocument() Option {
+	return func(o *options) {
+		o.singleDocument = true
+	}
+}
+
+// XSSIPrefixes lists the anti-XSSI prefixes commonly put in front of JSON responses to prevent them from
+// being executed as scripts.
+var XSSIPrefixes = []string{")]}',", ")]}'", "while(1);", "for(;;);"}
+
+// StripXSSIPrefix makes the parser skip one of prefixes, and the whitespace following it, at the very start of
+// the input. If no prefix is given, XSSIPrefixes is used.
+//
+// A prefix is never stripped anywhere else in the input, and input starting with another prefix fails as usual.
+// Positions and offsets still refer to the input including the prefix.
+func StripXSSIPrefix(prefixes ...string) Option {
+	if len(prefixes) == 0 {
+		prefixes = XSSIPrefixes
+	}
+
+	return func(o *options) {
+		o.xssiPrefixes = prefixes
+	}
+}
+
+// LenientNumbers makes the parser accept numbers which don't follow the grammar of RFC 8259, as long as they can
+// be converted by strconv, like +10, 0123, 1. or .5 in place of 10, 123, 1.0 and 0.5.
+func LenientNumbers() Option {
+	return func(o *options) {
+		o.lenientNumbers = true
+	}
+}
+
+// AllowNaNInf makes the parser accept the NaN, Infinity and -Infinity literals written by some serializers, like
+// Python's json module, as values. Their NumberEvent holds the corresponding float64.
+func AllowNaNInf() Option {
+	return func(o *options) {
+		o.nanInf = true
+	}
+}
+
+// PythonLiterals makes the parser accept the True, False and None literals written by the str function of Python,
+// as true, false and null. They must be written exactly so: TRUE or none are still rejected.
+//
+// Without it, these literals fail with an error suggesting the JSON one, like "did you mean true?".
+func PythonLiterals() Option {
+	return func(o *options) {
+		o.pythonLiterals = true
+	}
+}
+
+// AllowHexNumbers makes the parser accept hexadecimal integers, like 0xFF00 or -0x10, whose prefix and digits may be
+// lowercase or uppercase. They are emitted like the other integers, a hexadecimal integer which doesn't fit in an
+// int64 being converted as configured with IntegerOverflow. A prefix without digits, or followed by a fraction,
+// is invalid.
+func AllowHexNumbers() Option {
+	return func(o *options) {
+		o.hexNumbers = true
+	}
+}
+
+// UnicodeWhitespace makes the parser skip vertical tabs, form feeds, the Unicode space separators, like U+00A0,
+// and U+2028, U+2029 and U+FEFF between tokens, in addition to the whitespace allowed by RFC 8259, like JSON5.
+// Unlike LenientWhitespace, the input is read as UTF-8.
+func UnicodeWhitespace() Option {
+	return func(o *options) {
+		o.unicodeWhitespace = true
+	}
+}
+
+// LenientWhitespace makes the parser skip vertical tabs, form feeds and the 0x85 and 0xA0 bytes between tokens,
+// in addition to the spaces, tabs, carriage returns and line feeds allowed by RFC 8259.
+//
+// 0x85 and 0xA0 are NEL and NBSP in Latin-1, but they are also continuation bytes of multi-byte UTF-8 sequences.
+func LenientWhitespace() Option {
+	return func(o *options) {
+		o.lenientWhitespace = true
+	}
+}
+
+// AllowComments makes the parser skip // comments, up to the end of their line, and /* */ comments, which may
+// span several lines, wherever whitespace is allowed, like in "JSON with comments" configuration files.
+// A comment can't start inside a string. An unterminated /* comment fails with a ParseError at its start, wrapping
+// ErrUnexpectedEOF.
+func AllowComments() Option {
+	return func(o *options) {
+		o.allowComments = true
+	}
+}
+
+// AllowHashComments makes the parser skip # comments, up to the end of their line, wherever whitespace is allowed,
+// like in shell scripts. It is independent from AllowComments. A # inside a string is part of the string.
+func AllowHashComments() Option {
+	return func(o *options) {
+		o.allowHashComments = true
+	}
+}
+
+// AllowTrailingCommas makes the parser accept a comma after the last member of an object or the last element of an
+// array, like in {"a": 1,} and [1, 2,]. A comma alone in an empty object or array is still invalid.
+func AllowTrailingCommas() Option {
+	return func(o *options) {
+		o.allowTrailingCommas = true
+	}
+}
+
+// AllowSingleQuotedStrings makes the parser accept strings, keys included, enclosed in single quotes, like in
+// JSON5: {'a': 'say "hi"'} is read like {"a": "say \"hi\""}. A double quote doesn't need to be escaped in them,
+// and a single quote is escaped as \'. The other escapes are the ones of double-quoted strings.
+func AllowSingleQuotedStrings() Option {
+	return func(o *options) {
+		o.singleQuotedStrings = true
+	}
+}
+
+// AllowLineContinuations makes the parser drop the backslashes followed by a line terminator in strings, along with
+// the terminator, like in JSON5 and JavaScript: "a\<newline>b" is read as "ab". The line terminators are line
+// feeds, carriage returns, both in this order, U+2028 and U+2029. As in the rest of the input, the positions of
+// events and errors count lines by line feeds.
+func AllowLineContinuations() Option {
+	return func(o *options) {
+		o.lineContinuations = true
+	}
+}
+
+// AllowUnquotedKeys makes the parser accept keys which are identifiers, like in JavaScript: {foo: 1, bar_baz: 2} is
+// read like {"foo": 1, "bar_baz": 2}. An identifier is made of ASCII letters, digits, underscores and dollar signs,
+// and doesn't start with a digit: a key with other characters, non-ASCII letters included, must be quoted.
+// Literals like true and null are identifiers too as keys.
+func AllowUnquotedKeys() Option {
+	return func(o *options) {
+		o.unquotedKeys = true
+	}
+}
+
+// An OverflowMode tells the parser how to represent integers which don't fit in an int64.
+type OverflowMode int
+
+const (
+	// OverflowBigInt makes such an integer a uint64 if it fits, and a *big.Int otherwise. It is the default.
+	OverflowBigInt OverflowMode = iota
+	// OverflowFloat makes such an integer a float64, which may lose precision.
+	OverflowFloat
+	// OverflowError makes the parser fail with a ParseError wrapping strconv.ErrRange.
+	OverflowError
+)
+
+// IntegerOverflow sets how the parser represents integers which don't fit in an int64.
+//
+// The IsInt field of their NumberEvent is still true, whatever the mode.
+func IntegerOverflow(mode OverflowMode) Option {
+	return func(o *options) {
+		o.overflow = mode
+	}
+}
+
+// RejectLoneSurrogates makes the parser fail on a \u escape of a UTF-16 surrogate which is not part of a valid
+// surrogate pair, like "\ud800", instead of decoding it as U+FFFD.
+func RejectLoneSurrogates() Option {
+	return func(o *options) {
+		o.rejectLoneSurrogates = true
+	}
+}
+
+// DisallowNulInStrings makes the parser fail on a \u0000 escape in a string or a key, since many systems can't
+// store the NUL character. The error tells the JSON pointer of the string, or of the object whose key it is.
+//
+// The NUL byte itself is never allowed in a string, like every other control character.
+func DisallowNulInStrings() Option {
+	return func(o *options) {
+		o.disallowNul = true
+	}
+}
+
+// DisallowInvalidUTF8 makes the parser fail on a string which is not valid UTF-8, instead of replacing every
+// invalid byte with U+FFFD. The ParseError points at the first invalid byte.
+func DisallowInvalidUTF8() Option {
+	return func(o *options) {
+		o.disallowInvalidUTF8 = true
+	}
+}
+
+// DisallowDuplicateKeys makes the parser fail on an object having the same key twice, like {"a": 1, "a": 2},
+// at the second occurrence of the key. Keys are compared after unescaping, and each object has its own keys.
+func DisallowDuplicateKeys() Option {
+	return func(o *options) {
+		o.disallowDuplicateKeys = true
+	}
+}
+
+// JSON5 makes the parser accept the JSON5 grammar (https://spec.json5.org): it combines AllowComments,
+// AllowTrailingCommas, AllowSingleQuotedStrings, AllowUnquotedKeys, AllowHexNumbers, LenientNumbers, AllowNaNInf,
+// AllowLineContinuations and UnicodeWhitespace, and allows any value at the top level of a document.
+//
+// Some of JSON5 isn't supported, and fails with a ParseError naming it:
+//   - unquoted keys which aren't ASCII identifiers, or contain escapes;
+//   - the escapes of single characters which aren't defined by JSON, like \x41, \v or \0.
+//
+// LenientNumbers accepts a few numbers JSON5 doesn't, like 0123.
+func JSON5() Option {
+	return func(o *options) {
+		for _, opt := range []Option{
+			AllowComments(),
+			AllowTrailingCommas(),
+			AllowSingleQuotedStrings(),
+			AllowUnquotedKeys(),
+			AllowHexNumbers(),
+			LenientNumbers(),
+			AllowNaNInf(),
+			AllowLineContinuations(),
+			UnicodeWhitespace(),
+		} {
+			opt(o)
+		}
+		o.json5 = true
+	}
+}
+
+// JSONC makes the parser accept JSON with comments, like the settings of VS Code: it combines AllowComments and
+// AllowTrailingCommas, and nothing else. Single-quoted strings and unquoted keys are still rejected.
+func JSONC() Option {
+	return func(o *options) {
+		AllowComments()(o)
+		AllowTrailingCommas()(o)
+	}
+}
+
+// StrictMode makes the parser only accept input following RFC 8259 to the letter: a single document surrounded by
+// whitespace, which may be any value, and strings which are valid UTF-8 without lone surrogates nor \' escapes.
+// Numbers, whitespace, control characters and the other escapes are checked as strictly as by default.
+//
+// It also turns off LenientNumbers, AllowNaNInf, PythonLiterals, AllowHexNumbers, LenientWhitespace,
+// UnicodeWhitespace, AllowComments, AllowHashComments, AllowTrailingCommas, AllowSingleQuotedStrings,
+// AllowLineContinuations, AllowUnquotedKeys and JSON5 if they were given before it. Duplicate keys and \u0000
+// escapes are allowed by the RFC: use DisallowDuplicateKeys and DisallowNulInStrings to reject them too.
+func StrictMode() Option {
+	return func(o *options) {
+		o.strict = true
+		o.requireDocument = true
+		o.singleDocument = true
+		o.lenientNumbers = false
+		o.nanInf = false
+		o.pythonLiterals = false
+		o.hexNumbers = false
+		o.lenientWhitespace = false
+		o.allowComments = false
+		o.allowHashComments = false
+		o.allowTrailingCommas = false
+		o.singleQuotedStrings = false
+		o.unquotedKeys = false
+		o.lineContinuations = false
+		o.unicodeWhitespace = false
+		o.json5 = false
+		o.rejectLoneSurrogates = true
+		o.disallowInvalidUTF8 = true
+	}
+}
+
+// CollectErrors makes the parser go on after the errors it can recover from, emitting an ErrorEvent for each of
+// them instead of stopping with an EOFEvent. Its ParseError is the one the parser would otherwise stop with.
+//
+// The parser recovers from:
+//   - a missing comma between two members of an object or two elements of an array, as if it was there;
+//   - a trailing comma before the end of an object or an array, as if it wasn't there;
+//   - an invalid escape in a string, whose backslash is dropped;
+//   - a duplicate key, with DisallowDuplicateKeys, which is emitted anyway.
+//
+// Other errors, like the input ending before the document, still stop the parser.
+func CollectErrors() Option {
+	return func(o *options) {
+		o.collectErrors = true
+	}
+}
+
+// SkipInvalidValues makes the parser skip the values of objects and arrays which can't be parsed, like truu in
+// {"a": truu, "b": 1}, and go on with the rest of the object or array. A SkippedEvent is emitted in place of
+// such a value, with the ParseError about it and the text skipped, up to the comma or the closing bracket
+// following the value. Strings and nested objects and arrays are skipped as a whole.
+//
+// The parser still stops if the input ends while skipping, or on errors which are not about a value, like
+// a missing colon in an object.
+func SkipInvalidValues() Option {
+	return func(o *options) {
+		o.skipInvalidValues = true
+	}
+}
@@ -0,0 +1,1351 @@
+package bari_test
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/bari"
+)
+
+func TestExpandStringifiedJSON(t *testing.T) {
+	testCases := []testCase{
+		{
+			`{"payload": "{\"a\": 1}"}`,
+			[]expectedEvent{
+				{bari.ObjectStartEvent, nil, nil},
+				{bari.ObjectKeyEvent, nil, nil},
+				{bari.StringEvent, "payload", nil},
+				{bari.ObjectValueEvent, nil, nil},
+				{bari.ObjectStartEvent, nil, nil},
+				{bari.ObjectKeyEvent, nil, nil},
+				{bari.StringEvent, "a", nil},
+				{bari.ObjectValueEvent, nil, nil},
+				{bari.NumberEvent, int64(1), nil},
+				{bari.ObjectEndEvent, nil, nil},
+				{bari.ObjectEndEvent, nil, nil},
+			},
+		},
+		{
+			`["{\"b\": \"[1, \\\"x\\\"]\"}", "[]"]`,
+			[]expectedEvent{
+				{bari.ArrayStartEvent, nil, nil},
+				{bari.ObjectStartEvent, nil, nil},
+				{bari.ObjectKeyEvent, nil, nil},
+				{bari.StringEvent, "b", nil},
+				{bari.ObjectValueEvent, nil, nil},
+				{bari.ArrayStartEvent, nil, nil},
+				{bari.NumberEvent, int64(1), nil},
+				{bari.StringEvent, "x", nil},
+				{bari.ArrayEndEvent, nil, nil},
+				{bari.ObjectEndEvent, nil, nil},
+				{bari.ArrayStartEvent, nil, nil},
+				{bari.ArrayEndEvent, nil, nil},
+				{bari.ArrayEndEvent, nil, nil},
+			},
+		},
+		{
+			`{"{\"a\": 1}": "{", "b": "{\"a\": }", "c": "[1][2]", "d": "foo"}`,
+			[]expectedEvent{
+				{bari.ObjectStartEvent, nil, nil},
+				{bari.ObjectKeyEvent, nil, nil},
+				{bari.StringEvent, `{"a": 1}`, nil},
+				{bari.ObjectValueEvent, nil, nil},
+				{bari.StringEvent, "{", nil},
+				{bari.ObjectKeyEvent, nil, nil},
+				{bari.StringEvent, "b", nil},
+				{bari.ObjectValueEvent, nil, nil},
+				{bari.StringEvent, `{"a": }`, nil},
+				{bari.ObjectKeyEvent, nil, nil},
+				{bari.StringEvent, "c", nil},
+				{bari.ObjectValueEvent, nil, nil},
+				{bari.StringEvent, "[1][2]", nil},
+				{bari.ObjectKeyEvent, nil, nil},
+				{bari.StringEvent, "d", nil},
+				{bari.ObjectValueEvent, nil, nil},
+				{bari.StringEvent, "foo", nil},
+				{bari.ObjectEndEvent, nil, nil},
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		checkEvents(t, parseAll(c.data, bari.ExpandStringifiedJSON(2)), c.events)
+	}
+}
+
+func TestExpandStringifiedJSONDepth(t *testing.T) {
+	const data = `["{\"b\": \"[1]\"}"]`
+
+	checkEvents(t, parseAll(data, bari.ExpandStringifiedJSON(1)), []expectedEvent{
+		{bari.ArrayStartEvent, nil, nil},
+		{bari.ObjectStartEvent, nil, nil},
+		{bari.ObjectKeyEvent, nil, nil},
+		{bari.StringEvent, "b", nil},
+		{bari.ObjectValueEvent, nil, nil},
+		{bari.StringEvent, "[1]", nil},
+		{bari.ObjectEndEvent, nil, nil},
+		{bari.ArrayEndEvent, nil, nil},
+	})
+
+	checkEvents(t, parseAll(data), []expectedEvent{
+		{bari.ArrayStartEvent, nil, nil},
+		{bari.StringEvent, `{"b": "[1]"}`, nil},
+		{bari.ArrayEndEvent, nil, nil},
+	})
+}
+
+func TestStripXSSIPrefix(t *testing.T) {
+	events := []expectedEvent{
+		{bari.ArrayStartEvent, nil, nil},
+		{bari.StringEvent, ")]}'", nil},
+		{bari.ArrayEndEvent, nil, nil},
+	}
+
+	for _, data := range []string{
+		")]}'\n[\")]}'\"]",
+		")]}',\n[\")]}'\"]",
+		"while(1);[\")]}'\"]",
+		"for(;;); \r\n\t [\")]}'\"]",
+		"[\")]}'\"]",
+	} {
+		checkEvents(t, parseAll(data, bari.StripXSSIPrefix()), events)
+	}
+
+	checkEvents(t, parseAll("abc{}", bari.StripXSSIPrefix("abc")), []expectedEvent{
+		{bari.ObjectStartEvent, nil, nil},
+		{bari.ObjectEndEvent, nil, nil},
+	})
+}
+
+func TestStripXSSIPrefixErrors(t *testing.T) {
+	testCases := []struct {
+		data string
+		err  bari.ParseError
+	}{
+		{"/**/{}", bari.ParseError{Message: "unexpected character /", Line: 1, Position: 1, Offset: 0, Column: 1, DocumentLine: 1, DocumentPosition: 1, Err: bari.ErrInvalidCharacter}},
+		{"{}\n)]}'\n{}", bari.ParseError{Message: "unexpected character )", Line: 2, Position: 1, Offset: 3, Column: 1, Document: 1, DocumentLine: 1, DocumentPosition: 1, Err: bari.ErrInvalidCharacter}},
+		{")]}'\n{\"a\": x}", bari.ParseError{Message: "unexpected character x", Line: 2, Position: 7, Offset: 11, Column: 7, DocumentLine: 1, DocumentPosition: 7, Path: "/a", Context: "in object member \"/a\"", Err: bari.ErrInvalidCharacter}},
+	}
+
+	for _, tc := range testCases {
+		events := parseAll(tc.data, bari.StripXSSIPrefix())
+		require.Equal(t, tc.err, events[len(events)-1].Error, "data: %q", tc.data)
+	}
+
+	require.Empty(t, parseAll(")]}'", bari.StripXSSIPrefix()))
+
+	events := parseAll(")]}'", bari.StripXSSIPrefix(), bari.RequireDocument())
+	require.Equal(t, bari.ParseError{Message: "unexpected end of file", Line: 1, Position: 5, Offset: 4, Column: 5, DocumentLine: 1, DocumentPosition: 5, Err: bari.ErrUnexpectedEOF}, events[0].Error)
+}
+
+func TestStripXSSIPrefixOffsets(t *testing.T) {
+	parser := bari.NewParser(strings.NewReader(")]}'\n{}\n"), bari.StripXSSIPrefix(), bari.IndexLines())
+	ch := make(chan bari.Event)
+
+	go parser.ParseAndClose(ch)
+	for range ch {
+	}
+
+	line, col := parser.PositionIndex().LineCol(5)
+	require.Equal(t, 2, line)
+	require.Equal(t, 1, col)
+}
+
+func TestLenientNumbers(t *testing.T) {
+	events := parseAll(`[+10, 0123, 1., 1.5]`, bari.LenientNumbers())
+	require.Equal(t, 6, len(events))
+	ck(t, events[1], bari.NumberEvent, int64(10), nil)
+	ck(t, events[2], bari.NumberEvent, int64(123), nil)
+	ck(t, events[3], bari.NumberEvent, 1.0, nil)
+	ck(t, events[4], bari.NumberEvent, 1.5, nil)
+}
+
+func TestIntegerOverflow(t *testing.T) {
+	const data = `[18446744073709551616, -9223372036854775809, 1]`
+
+	events := parseAll(data, bari.IntegerOverflow(bari.OverflowFloat))
+	require.Equal(t, 5, len(events))
+	ck(t, events[1], bari.NumberEvent, 18446744073709551616.0, nil)
+	ck(t, events[2], bari.NumberEvent, -9223372036854775809.0, nil)
+	ck(t, events[3], bari.NumberEvent, int64(1), nil)
+
+	events = parseAll(data, bari.IntegerOverflow(bari.OverflowError))
+	require.Equal(t, 2, len(events))
+	require.True(t, errors.Is(events[1].Error, strconv.ErrRange))
+}
+
+func TestAllowNaNInf(t *testing.T) {
+	events := parseAll(`{"a": NaN, "b": [Infinity, -Infinity, -1], "c": -Infinity}`, bari.AllowNaNInf())
+	var values []float64
+	for _, ev := range events {
+		if f, ok := ev.Float64(); ok {
+			require.Equal(t, bari.NumberEvent, ev.Type)
+			values = append(values, f)
+		}
+	}
+	require.Equal(t, 5, len(values))
+	require.True(t, math.IsNaN(values[0]))
+	require.Equal(t, []float64{math.Inf(1), math.Inf(-1), -1, math.Inf(-1)}, values[1:])
+	require.True(t, bari.Valid([]byte(`[NaN, -Infinity]`), bari.AllowNaNInf()))
+
+	// A partial literal fails at the first byte which differs, and the literals are rejected without the option.
+	testCases := []struct {
+		data    string
+		opts    []bari.Option
+		message string
+		offset  int64
+	}{
+		{`[Infin]`, []bari.Option{bari.AllowNaNInf()}, "unexpected character ] in literal Infinity", 6},
+		{`[-Nan]`, []bari.Option{bari.AllowNaNInf()}, "unexpected character n in literal NaN", 4},
+		{`[NaN`, []bari.Option{bari.AllowNaNInf()}, "unexpected end of file while expecting ',' or ']'", 4},
+		{`[NaN]`, nil, "unexpected character N", 1},
+		{`[-Infinity]`, []bari.Option{bari.AllowNaNInf(), bari.StrictMode()}, "invalid number: expected digit", 2},
+	}
+	for _, c := range testCases {
+		events := parseAll(c.data, c.opts...)
+		err := events[len(events)-1].Error.(bari.ParseError)
+		require.Equal(t, c.message, err.Message, "data: %s", c.data)
+		require.Equal(t, c.offset, err.Offset, "data: %s", c.data)
+	}
+}
+
+func TestPythonLiterals(t *testing.T) {
+	data := `{"a": True, "b": [False, None, True], "c": None, "d": False}`
+	checkEvents(t, parseAll(data, bari.PythonLiterals()), []expectedEvent{
+		{bari.ObjectStartEvent, nil, nil},
+		{bari.ObjectKeyEvent, nil, nil},
+		{bari.StringEvent, "a", nil},
+		{bari.ObjectValueEvent, nil, nil},
+		{bari.BooleanEvent, true, nil},
+		{bari.ObjectKeyEvent, nil, nil},
+		{bari.StringEvent, "b", nil},
+		{bari.ObjectValueEvent, nil, nil},
+		{bari.ArrayStartEvent, nil, nil},
+		{bari.BooleanEvent, false, nil},
+		{bari.NullEvent, nil, nil},
+		{bari.BooleanEvent, true, nil},
+		{bari.ArrayEndEvent, nil, nil},
+		{bari.ObjectKeyEvent, nil, nil},
+		{bari.StringEvent, "c", nil},
+		{bari.ObjectValueEvent, nil, nil},
+		{bari.NullEvent, nil, nil},
+		{bari.ObjectKeyEvent, nil, nil},
+		{bari.StringEvent, "d", nil},
+		{bari.ObjectValueEvent, nil, nil},
+		{bari.BooleanEvent, false, nil},
+		{bari.ObjectEndEvent, nil, nil},
+	})
+
+	// Unquoted keys named like the literals stay keys, and Infinity is still read with AllowNaNInf.
+	checkEvents(t, parseAll(`{True: None, None: Infinity}`, bari.PythonLiterals(), bari.AllowUnquotedKeys(), bari.AllowNaNInf()), []expectedEvent{
+		{bari.ObjectStartEvent, nil, nil},
+		{bari.ObjectKeyEvent, nil, nil},
+		{bari.StringEvent, "True", nil},
+		{bari.ObjectValueEvent, nil, nil},
+		{bari.NullEvent, nil, nil},
+		{bari.ObjectKeyEvent, nil, nil},
+		{bari.StringEvent, "None", nil},
+		{bari.ObjectValueEvent, nil, nil},
+		{bari.NumberEvent, math.Inf(1), nil},
+		{bari.ObjectEndEvent, nil, nil},
+	})
+
+	// The literals must match exactly, and are rejected with a hint without the option.
+	testCases := []struct {
+		data    string
+		opts    []bari.Option
+		message string
+	}{
+		{`[TRUE]`, []bari.Option{bari.PythonLiterals()}, "unexpected character R in literal True"},
+		{`[Nonе]`, []bari.Option{bari.PythonLiterals()}, "unexpected character е in literal None"},
+		{`[none]`, []bari.Option{bari.PythonLiterals()}, "unexpected character o in literal null"},
+		{`[True]`, nil, "unexpected character T, did you mean true?"},
+		{`[False]`, nil, "unexpected character F, did you mean false?"},
+		{`[None]`, nil, "unexpected character N, did you mean null?"},
+		{`[None]`, []bari.Option{bari.PythonLiterals(), bari.StrictMode()}, "unexpected character N, did you mean null?"},
+		{`[Tru]`, nil, "unexpected character T"},
+	}
+	for _, c := range testCases {
+		events := parseAll(c.data, c.opts...)
+		require.Equal(t, c.message, events[len(events)-1].Error.(bari.ParseError).Message, "data: %s", c.data)
+	}
+}
+
+func TestAllowHexNumbers(t *testing.T) {
+	events := parseAll(`{"a": 0xFF00, "b": [0x1f, 0Xab, -0x10, 0x0], "c": 0}`, bari.AllowHexNumbers())
+	var values []interface{}
+	for _, ev := range events {
+		if ev.Type == bari.NumberEvent {
+			require.True(t, ev.IsInt)
+			values = append(values, ev.Value)
+		}
+	}
+	require.Equal(t, []interface{}{int64(0xFF00), int64(0x1f), int64(0xab), int64(-0x10), int64(0), int64(0)}, values)
+
+	// Integers beyond 64 bits overflow like decimal ones.
+	events = parseAll(`[0x10000000000000000, -0x8000000000000001, 0xFFFFFFFFFFFFFFFF]`, bari.AllowHexNumbers())
+	huge, _ := new(big.Int).SetString("10000000000000000", 16)
+	low, _ := new(big.Int).SetString("-8000000000000001", 16)
+	require.Equal(t, huge, events[1].Value)
+	require.Equal(t, low, events[2].Value)
+	require.Equal(t, uint64(math.MaxUint64), events[3].Value)
+	events = parseAll(`[0x10000000000000000]`, bari.AllowHexNumbers(), bari.IntegerOverflow(bari.OverflowFloat))
+	require.Equal(t, math.Pow(2, 64), events[1].Value)
+
+	testCases := []struct {
+		data    string
+		opts    []bari.Option
+		message string
+		offset  int64
+	}{
+		{`[0x]`, []bari.Option{bari.AllowHexNumbers()}, "invalid number: expected hexadecimal digit", 3},
+		{`[0xg]`, []bari.Option{bari.AllowHexNumbers()}, "invalid number: expected hexadecimal digit", 3},
+		{`[0x1.5]`, []bari.Option{bari.AllowHexNumbers()}, "invalid number: hexadecimal numbers can't have a fraction", 4},
+		{`[1x1]`, []bari.Option{bari.AllowHexNumbers()}, "expected , but got x", 2},
+		{`[0x1]`, nil, "expected , but got x", 2},
+		{`[0x1]`, []bari.Option{bari.AllowHexNumbers(), bari.StrictMode()}, "expected , but got x", 2},
+	}
+	for _, c := range testCases {
+		events := parseAll(c.data, c.opts...)
+		err := events[len(events)-1].Error.(bari.ParseError)
+		require.Equal(t, c.message, err.Message, "data: %s", c.data)
+		require.Equal(t, c.offset, err.Offset, "data: %s", c.data)
+	}
+}
+
+func TestLenientWhitespace(t *testing.T) {
+	checkEvents(t, parseAll("{\x85\"a\":\xa0[\v1\f]}\x85[]", bari.LenientWhitespace()), []expectedEvent{
+		{bari.ObjectStartEvent, nil, nil},
+		{bari.ObjectKeyEvent, nil, nil},
+		{bari.StringEvent, "a", nil},
+		{bari.ObjectValueEvent, nil, nil},
+		{bari.ArrayStartEvent, nil, nil},
+		{bari.NumberEvent, int64(1), nil},
+		{bari.ArrayEndEvent, nil, nil},
+		{bari.ObjectEndEvent, nil, nil},
+		{bari.ArrayStartEvent, nil, nil},
+		{bari.ArrayEndEvent, nil, nil},
+	})
+}
+
+func TestAllowComments(t *testing.T) {
+	data := "// config\n{\"a\" /* the key */ : [1, // one\n/* two,\n three */ 2]/**/, \"b//\": \"/*\"} // end"
+	expected := []expectedEvent{
+		{bari.ObjectStartEvent, nil, nil},
+		{bari.ObjectKeyEvent, nil, nil},
+		{bari.StringEvent, "a", nil},
+		{bari.ObjectValueEvent, nil, nil},
+		{bari.ArrayStartEvent, nil, nil},
+		{bari.NumberEvent, int64(1), nil},
+		{bari.NumberEvent, int64(2), nil},
+		{bari.ArrayEndEvent, nil, nil},
+		{bari.ObjectKeyEvent, nil, nil},
+		{bari.StringEvent, "b//", nil},
+		{bari.ObjectValueEvent, nil, nil},
+		{bari.StringEvent, "/*", nil},
+		{bari.ObjectEndEvent, nil, nil},
+	}
+	checkEvents(t, parseAll(data, bari.AllowComments()), expected)
+
+	// Lines and columns go on after the comments.
+	events := parseAll(data, bari.AllowComments(), bari.EventPositions())
+	require.Equal(t, []int{4, 11}, []int{events[6].Line, events[6].Column})
+
+	// A skipped value may hold comments.
+	parser := bari.NewParser(strings.NewReader(`[[1, /* ] */ 2], 3]`), bari.AllowComments())
+	_, err := parser.Next()
+	require.Nil(t, err)
+	require.Nil(t, parser.SkipValue())
+	ev, err := parser.Next()
+	require.Nil(t, err)
+	require.Equal(t, int64(3), ev.Value)
+
+	// Comments are only allowed with AllowComments, and a slash alone is not one.
+	events = parseAll(`[1 // one` + "\n]")
+	require.True(t, errors.Is(events[len(events)-1].Error, bari.ErrInvalidCharacter))
+	events = parseAll(`[1 / 2]`, bari.AllowComments())
+	require.True(t, errors.Is(events[len(events)-1].Error, bari.ErrInvalidCharacter))
+
+	// An unterminated comment fails at its start, even after the document.
+	for _, data := range []string{"[1,\n /* two\n\n", "[1] /* two\n\n"} {
+		events = parseAll(data, bari.AllowComments())
+		err := events[len(events)-1].Error.(bari.ParseError)
+		require.Equal(t, "unterminated comment", err.Message)
+		require.Equal(t, bari.ErrUnexpectedEOF, err.Err)
+		require.Equal(t, strings.Index(data, "/*"), int(err.Offset))
+	}
+	perr := parseAll("[1,\n /* two\n\n", bari.AllowComments())[2].Error.(bari.ParseError)
+	require.Equal(t, []int{2, 2, 2}, []int{perr.Line, perr.Position, perr.Column})
+}
+
+func TestAllowHashComments(t *testing.T) {
+	data := "# inventory\n{\"host\": \"#1\"} # first\n# between\n\n[1, # one\n2]"
+	checkEvents(t, parseAll(data, bari.AllowHashComments()), []expectedEvent{
+		{bari.ObjectStartEvent, nil, nil},
+		{bari.ObjectKeyEvent, nil, nil},
+		{bari.StringEvent, "host", nil},
+		{bari.ObjectValueEvent, nil, nil},
+		{bari.StringEvent, "#1", nil},
+		{bari.ObjectEndEvent, nil, nil},
+		{bari.ArrayStartEvent, nil, nil},
+		{bari.NumberEvent, int64(1), nil},
+		{bari.NumberEvent, int64(2), nil},
+		{bari.ArrayEndEvent, nil, nil},
+	})
+
+	// Each kind of comment needs its own option.
+	events := parseAll(data, bari.AllowComments())
+	require.True(t, errors.Is(events[len(events)-1].Error, bari.ErrInvalidCharacter))
+	events = parseAll("[1, // one\n2]", bari.AllowHashComments())
+	require.True(t, errors.Is(events[len(events)-1].Error, bari.ErrInvalidCharacter))
+	require.True(t, bari.Valid([]byte("[1, # one\n/* two */ 2]"), bari.AllowComments(), bari.AllowHashComments()))
+}
+
+func TestAllowTrailingCommas(t *testing.T) {
+	data := `{"a": [1, [2,], {"b": {},},], "c": 3,}`
+	expected := []expectedEvent{
+		{bari.ObjectStartEvent, nil, nil},
+		{bari.ObjectKeyEvent, nil, nil},
+		{bari.StringEvent, "a", nil},
+		{bari.ObjectValueEvent, nil, nil},
+		{bari.ArrayStartEvent, nil, nil},
+		{bari.NumberEvent, int64(1), nil},
+		{bari.ArrayStartEvent, nil, nil},
+		{bari.NumberEvent, int64(2), nil},
+		{bari.ArrayEndEvent, nil, nil},
+		{bari.ObjectStartEvent, nil, nil},
+		{bari.ObjectKeyEvent, nil, nil},
+		{bari.StringEvent, "b", nil},
+		{bari.ObjectValueEvent, nil, nil},
+		{bari.ObjectStartEvent, nil, nil},
+		{bari.ObjectEndEvent, nil, nil},
+		{bari.ObjectEndEvent, nil, nil},
+		{bari.ArrayEndEvent, nil, nil},
+		{bari.ObjectKeyEvent, nil, nil},
+		{bari.StringEvent, "c", nil},
+		{bari.ObjectValueEvent, nil, nil},
+		{bari.NumberEvent, int64(3), nil},
+		{bari.ObjectEndEvent, nil, nil},
+	}
+	checkEvents(t, parseAll(data, bari.AllowTrailingCommas()), expected)
+
+	// Comments may follow the comma.
+	data = "[1, // one\n/* end */ ]"
+	events := parseAll(data, bari.AllowTrailingCommas(), bari.AllowComments())
+	require.Equal(t, 3, len(events))
+	require.Nil(t, events[2].Error)
+
+	// A comma alone is still invalid.
+	for _, data := range []string{`[,]`, `{,}`, `[1,,]`} {
+		events = parseAll(data, bari.AllowTrailingCommas())
+		require.True(t, errors.Is(events[len(events)-1].Error, bari.ErrInvalidCharacter), "data: %s", data)
+	}
+
+	// Without the option, and in strict mode, a trailing comma fails at the bracket following it.
+	for _, opts := range [][]bari.Option{nil, {bari.AllowTrailingCommas(), bari.StrictMode()}} {
+		events = parseAll(`{"a": [1,]}`, opts...)
+		err := events[len(events)-1].Error.(bari.ParseError)
+		require.Equal(t, "trailing comma before ]", err.Message)
+		require.Equal(t, int64(9), err.Offset)
+
+		events = parseAll(`{"a": 1 , }`, opts...)
+		require.Equal(t, "trailing comma before }", events[len(events)-1].Error.(bari.ParseError).Message)
+	}
+}
+
+func TestAllowSingleQuotedStrings(t *testing.T) {
+	data := `{'a': 'say "hi"', "b": ['it\'s', '\u00e9\n', ''], 'c"': "'"}`
+	expected := []expectedEvent{
+		{bari.ObjectStartEvent, nil, nil},
+		{bari.ObjectKeyEvent, nil, nil},
+		{bari.StringEvent, "a", nil},
+		{bari.ObjectValueEvent, nil, nil},
+		{bari.StringEvent, `say "hi"`, nil},
+		{bari.ObjectKeyEvent, nil, nil},
+		{bari.StringEvent, "b", nil},
+		{bari.ObjectValueEvent, nil, nil},
+		{bari.ArrayStartEvent, nil, nil},
+		{bari.StringEvent, "it's", nil},
+		{bari.StringEvent, "\u00e9\n", nil},
+		{bari.StringEvent, "", nil},
+		{bari.ArrayEndEvent, nil, nil},
+		{bari.ObjectKeyEvent, nil, nil},
+		{bari.StringEvent, `c"`, nil},
+		{bari.ObjectValueEvent, nil, nil},
+		{bari.StringEvent, "'", nil},
+		{bari.ObjectEndEvent, nil, nil},
+	}
+	checkEvents(t, parseAll(data, bari.AllowSingleQuotedStrings()), expected)
+
+	// Strings are decoded like double-quoted ones, whatever the way they are read.
+	for _, opts := range [][]bari.Option{{bari.ByteStrings()}, {bari.DisallowDuplicateKeys()}} {
+		var values []string
+		for _, ev := range parseAll(data, append(opts, bari.AllowSingleQuotedStrings())...) {
+			if s, ok := ev.Text(); ok {
+				values = append(values, s)
+			}
+		}
+		require.Equal(t, []string{"a", `say "hi"`, "b", "it's", "\u00e9\n", "", `c"`, "'"}, values)
+	}
+
+	// A skipped value may hold brackets in single-quoted strings.
+	parser := bari.NewParser(strings.NewReader(`[['a]', 2], 3]`), bari.AllowSingleQuotedStrings())
+	_, err := parser.Next()
+	require.Nil(t, err)
+	require.Nil(t, parser.SkipValue())
+	ev, err := parser.Next()
+	require.Nil(t, err)
+	require.Equal(t, int64(3), ev.Value)
+
+	// Without the option, and in strict mode, the error tells about the quotes.
+	for _, opts := range [][]bari.Option{nil, {bari.AllowSingleQuotedStrings(), bari.StrictMode()}} {
+		events := parseAll(`{"a": 'b'}`, opts...)
+		require.Equal(t, "unexpected character ', strings must be double-quoted", events[len(events)-1].Error.(bari.ParseError).Message)
+
+		events = parseAll(`{'a': "b"}`, opts...)
+		require.Equal(t, "expected \" but got ', strings must be double-quoted", events[len(events)-1].Error.(bari.ParseError).Message)
+	}
+}
+
+func TestAllowUnquotedKeys(t *testing.T) {
+	data := `{foo: 1, "bar": 2, $_a1 :3, true: 4, null:5}`
+	var keys []string
+	for _, ev := range parseAll(data, bari.AllowUnquotedKeys(), bari.CompactEvents()) {
+		if ev.Type == bari.ObjectKeyEvent {
+			keys = append(keys, ev.Str)
+		}
+	}
+	require.Equal(t, []string{"foo", "bar", "$_a1", "true", "null"}, keys)
+
+	checkEvents(t, parseAll(`{a_b: [1]}`, bari.AllowUnquotedKeys()), []expectedEvent{
+		{bari.ObjectStartEvent, nil, nil},
+		{bari.ObjectKeyEvent, nil, nil},
+		{bari.StringEvent, "a_b", nil},
+		{bari.ObjectValueEvent, nil, nil},
+		{bari.ArrayStartEvent, nil, nil},
+		{bari.NumberEvent, int64(1), nil},
+		{bari.ArrayEndEvent, nil, nil},
+		{bari.ObjectEndEvent, nil, nil},
+	})
+
+	// Duplicates are found whether the keys are quoted or not.
+	events := parseAll(`{a: 1, "a": 2}`, bari.AllowUnquotedKeys(), bari.DisallowDuplicateKeys())
+	require.True(t, errors.Is(events[len(events)-1].Error, bari.ErrDuplicateKey))
+
+	// Identifiers are ASCII, don't start with a digit, and still need a colon, even at the end of the input.
+	testCases := []struct {
+		data    string
+		message string
+	}{
+		{`{é: 1}`, `expected " but got é`},
+		{`{1a: 1}`, "object keys must be strings, got number"},
+		{`{a-b: 1}`, "expected : but got -"},
+		{`{a 1}`, "expected : but got 1"},
+		{`{abc`, "unexpected end of file while expecting ':'"},
+	}
+	for _, c := range testCases {
+		events := parseAll(c.data, bari.AllowUnquotedKeys())
+		require.Equal(t, c.message, events[len(events)-1].Error.(bari.ParseError).Message, "data: %s", c.data)
+	}
+
+	// Strict mode rejects them.
+	events = parseAll(data, bari.AllowUnquotedKeys(), bari.StrictMode())
+	require.Equal(t, "object keys must be strings, got unquoted key", events[len(events)-1].Error.(bari.ParseError).Message)
+}
+
+func TestAllowLineContinuations(t *testing.T) {
+	data := "[\"a\\\nb\", \"c\\\r\nd\\\re\", 'f\\\u2028g\\\\\\\u2029h', \"i\\\\\"]"
+	checkEvents(t, parseAll(data, bari.AllowLineContinuations(), bari.AllowSingleQuotedStrings()), []expectedEvent{
+		{bari.ArrayStartEvent, nil, nil},
+		{bari.StringEvent, "ab", nil},
+		{bari.StringEvent, "cde", nil},
+		{bari.StringEvent, `fg\h`, nil},
+		{bari.StringEvent, `i\`, nil},
+		{bari.ArrayEndEvent, nil, nil},
+	})
+
+	// Unescaped line terminators and continuations without the option are still invalid.
+	testCases := []struct {
+		data    string
+		opts    []bari.Option
+		message string
+	}{
+		{"[\"a\nb\"]", []bari.Option{bari.AllowLineContinuations()}, "invalid control character 0x0a in string"},
+		{"[\"a\\\n\nb\"]", []bari.Option{bari.AllowLineContinuations()}, "invalid control character 0x0a in string"},
+		{"[\"a\\\nb\"]", nil, "invalid control character 0x0a in string"},
+		{"[\"a\\\u2028b\"]", nil, "invalid escape \"\\0xe2\" in string"},
+	}
+	for _, c := range testCases {
+		events := parseAll(c.data, c.opts...)
+		require.Equal(t, c.message, events[len(events)-1].Error.(bari.ParseError).Message, "data: %q", c.data)
+	}
+}
+
+func TestLineContinuationPositions(t *testing.T) {
+	// A string spanning three lines, followed by an error.
+	data := "{\"a\": \"one \\\n  two \\\r\nthree\" 1}"
+	events := parseAll(data, bari.AllowLineContinuations())
+	require.Equal(t, "one   two three", events[4].Value)
+	err := events[len(events)-1].Error.(bari.ParseError)
+	require.Equal(t, "expected , but got 1", err.Message)
+	require.Equal(t, 3, err.Line)
+	require.Equal(t, 8, err.Position)
+	require.Equal(t, int64(len(data)-2), err.Offset)
+
+	// Errors in the string are reported on their own line.
+	testCases := []struct {
+		data     string
+		line     int
+		position int
+		column   int
+	}{
+		{"[\"\\x \\\n\"]", 1, 3, 3},
+		{"[\"a\\\n\\x\"]", 2, 1, 1},
+		{"[\"a\\\né\\\n\\\nb\\x\"]", 4, 2, 2},
+		{"[\"a\\\né\\x\\\n\"]", 2, 3, 2},
+	}
+	for _, c := range testCases {
+		events := parseAll(c.data, bari.AllowLineContinuations())
+		err := events[len(events)-1].Error.(bari.ParseError)
+		require.Equal(t, `invalid escape "\x" in string`, err.Message, "data: %q", c.data)
+		require.Equal(t, c.line, err.Line, "data: %q", c.data)
+		require.Equal(t, c.position, err.Position, "data: %q", c.data)
+		require.Equal(t, c.column, err.Column, "data: %q", c.data)
+	}
+
+	// Strict mode rejects the raw newlines, even after JSON5.
+	events = parseAll(data, bari.JSON5(), bari.StrictMode())
+	require.Equal(t, "invalid control character 0x0a in string", events[len(events)-1].Error.(bari.ParseError).Message)
+}
+
+func TestUnicodeWhitespace(t *testing.T) {
+	data := "\ufeff[\v1,\f2\u00a0,\u20283\u3000]"
+	events := parseAll(data, bari.UnicodeWhitespace())
+	require.Nil(t, events[len(events)-1].Error)
+	require.Equal(t, 5, len(events))
+
+	// Other characters still fail, and so does the whitespace without the option.
+	events = parseAll("[1,\u00a9]", bari.UnicodeWhitespace())
+	require.Equal(t, "unexpected character \u00a9", events[len(events)-1].Error.(bari.ParseError).Message)
+	events = parseAll(data)
+	require.NotNil(t, events[len(events)-1].Error)
+}
+
+func TestJSON5(t *testing.T) {
+	events := parseAll("{a: +Infinity, b: [.5, +0x10, 'c\\\n'],}", bari.JSON5())
+	require.Nil(t, events[len(events)-1].Error)
+
+	// JSON5 is made of the individual options: StrictMode turns it off.
+	events = parseAll("{a: 1}", bari.JSON5(), bari.StrictMode())
+	require.Equal(t, "object keys must be strings, got unquoted key", events[len(events)-1].Error.(bari.ParseError).Message)
+
+	// Without JSON5, the same unsupported syntax fails with the usual messages.
+	events = parseAll(`{\u0061: 1}`, bari.AllowUnquotedKeys())
+	require.Equal(t, `expected " but got \`, events[len(events)-1].Error.(bari.ParseError).Message)
+	events = parseAll(`["\x41"]`)
+	require.Equal(t, `invalid escape "\x" in string`, events[len(events)-1].Error.(bari.ParseError).Message)
+}
+
+func TestJSON5Corpus(t *testing.T) {
+	files, err := filepath.Glob("./testdata/json5/*.json5")
+	require.Nil(t, err)
+	require.NotEmpty(t, files)
+
+	for _, name := range files {
+		t.Run(filepath.Base(name), func(t *testing.T) {
+			data, err := ioutil.ReadFile(name)
+			require.Nil(t, err)
+
+			var buf strings.Builder
+			for _, ev := range parseAll(string(data), bari.JSON5()) {
+				buf.WriteString(ev.String() + "\n")
+			}
+
+			golden := name + ".golden"
+			if *update {
+				require.Nil(t, ioutil.WriteFile(golden, []byte(buf.String()), 0644))
+			}
+
+			exp, err := ioutil.ReadFile(golden)
+			require.Nil(t, err)
+			require.Equal(t, string(exp), buf.String())
+		})
+	}
+}
+
+func TestJSONC(t *testing.T) {
+	data, err := ioutil.ReadFile("./testdata/jsonc/settings.jsonc")
+	require.Nil(t, err)
+
+	var buf strings.Builder
+	for _, ev := range parseAll(string(data), bari.JSONC()) {
+		buf.WriteString(ev.String() + "\n")
+	}
+
+	golden := "./testdata/jsonc/settings.jsonc.golden"
+	if *update {
+		require.Nil(t, ioutil.WriteFile(golden, []byte(buf.String()), 0644))
+	}
+
+	exp, err := ioutil.ReadFile(golden)
+	require.Nil(t, err)
+	require.Equal(t, string(exp), buf.String())
+
+	// The rest of the lenient syntax fails like without JSONC.
+	testCases := []struct {
+		data    string
+		message string
+	}{
+		{`{"a": 'b'}`, "unexpected character ', strings must be double-quoted"},
+		{`{'a': 1}`, "expected \" but got ', strings must be double-quoted"},
+		{`{a: 1}`, "object keys must be strings, got unquoted key"},
+		{`[0x10]`, "expected , but got x"},
+		{"[1] # comment", "unexpected character #"},
+	}
+	for _, c := range testCases {
+		events := parseAll(c.data, bari.JSONC())
+		require.Equal(t, c.message, events[len(events)-1].Error.(bari.ParseError).Message, "data: %s", c.data)
+	}
+}
+
+func TestRejectLoneSurrogates(t *testing.T) {
+	testCases := []struct {
+		data string
+		err  bari.ParseError
+	}{
+		{`["ab\ud800"]`, bari.ParseError{Message: `lone surrogate \ud800 in string`, Line: 1, Position: 5, Offset: 4, Column: 5, DocumentLine: 1, DocumentPosition: 5, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidString}},
+		{`["\uDC00ab"]`, bari.ParseError{Message: `lone surrogate \uDC00 in string`, Line: 1, Position: 3, Offset: 2, Column: 3, DocumentLine: 1, DocumentPosition: 3, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidString}},
+		{`["\ud83d\u0041"]`, bari.ParseError{Message: `lone surrogate \ud83d in string`, Line: 1, Position: 3, Offset: 2, Column: 3, DocumentLine: 1, DocumentPosition: 3, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidString}},
+		{`["\\\ud83d\ude00\ud83d"]`, bari.ParseError{Message: `lone surrogate \ud83d in string`, Line: 1, Position: 17, Offset: 16, Column: 17, DocumentLine: 1, DocumentPosition: 17, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidString}},
+	}
+
+	for _, c := range testCases {
+		events := parseAll(c.data, bari.RejectLoneSurrogates())
+		require.Equal(t, c.err, events[len(events)-1].Error, "data: %s", c.data)
+
+		events = parseAll(c.data)
+		require.Equal(t, 3, len(events))
+		require.Contains(t, events[1].Value, "\uFFFD")
+	}
+
+	checkEvents(t, parseAll(`["\ud83d\ude00", "\\ud800"]`, bari.RejectLoneSurrogates()), []expectedEvent{
+		{bari.ArrayStartEvent, nil, nil},
+		{bari.StringEvent, "\U0001F600", nil},
+		{bari.StringEvent, `\ud800`, nil},
+		{bari.ArrayEndEvent, nil, nil},
+	})
+}
+
+func TestDisallowInvalidUTF8(t *testing.T) {
+	testCases := []struct {
+		data string
+		err  bari.ParseError
+	}{
+		// overlong encoding of /
+		{"[\"a\xc0\xafb\"]", bari.ParseError{Message: "invalid UTF-8 byte 0xc0 in string", Line: 1, Position: 4, Offset: 3, Column: 4, DocumentLine: 1, DocumentPosition: 4, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidString}},
+		// overlong encoding of U+20AC
+		{"[\"\xf0\x82\x82\xac\"]", bari.ParseError{Message: "invalid UTF-8 byte 0xf0 in string", Line: 1, Position: 3, Offset: 2, Column: 3, DocumentLine: 1, DocumentPosition: 3, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidString}},
+		// truncated sequences
+		{"[\"ab\xe2\x82\"]", bari.ParseError{Message: "invalid UTF-8 byte 0xe2 in string", Line: 1, Position: 5, Offset: 4, Column: 5, DocumentLine: 1, DocumentPosition: 5, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidString}},
+		{"{\"k\": \"\xf0\x9f\x98\"}", bari.ParseError{Message: "invalid UTF-8 byte 0xf0 in string", Line: 1, Position: 8, Offset: 7, Column: 8, DocumentLine: 1, DocumentPosition: 8, Path: "/k", Context: "in object member \"/k\"", Err: bari.ErrInvalidString}},
+		{"[\"\xff\"]", bari.ParseError{Message: "invalid UTF-8 byte 0xff in string", Line: 1, Position: 3, Offset: 2, Column: 3, DocumentLine: 1, DocumentPosition: 3, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidString}},
+		// encoded surrogate
+		{"[\"\xed\xa0\x80\"]", bari.ParseError{Message: "invalid UTF-8 byte 0xed in string", Line: 1, Position: 3, Offset: 2, Column: 3, DocumentLine: 1, DocumentPosition: 3, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidString}},
+	}
+
+	for _, c := range testCases {
+		events := parseAll(c.data, bari.DisallowInvalidUTF8())
+		require.Equal(t, c.err, events[len(events)-1].Error, "data: %q", c.data)
+
+		events = parseAll(c.data)
+		require.Nil(t, events[len(events)-1].Error, "data: %q", c.data)
+	}
+
+	// Multi-byte sequences straddling the buffer of the reader.
+	for i := 4090; i < 4100; i++ {
+		s := strings.Repeat("a", i) + "\u00e9\U0001F600"
+		events := parseAll(`["`+s+`"]`, bari.DisallowInvalidUTF8())
+		require.Equal(t, 3, len(events))
+		require.Equal(t, s, events[1].Value)
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	data := strings.Repeat("[", 1000000)
+
+	events := parseAll(data)
+	require.Equal(t, bari.DefaultMaxDepth+1, len(events))
+
+	err := events[len(events)-1].Error
+	require.Equal(t, bari.ParseError{
+		Message:          "max depth limit of 10000 exceeded",
+		Line:             1,
+		Position:         10001,
+		Offset:           10000,
+		Column:           10001,
+		DocumentLine:     1,
+		DocumentPosition: 10001,
+		Path:             "/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0",
+		Context:          "in array element \"/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0/0\"",
+		Err:              bari.LimitError{Limit: bari.MaxDepthLimit, Value: 10000, Line: 1, Position: 10001},
+	}, err)
+	require.True(t, errors.Is(err, bari.ErrLimitExceeded))
+
+	events = parseAll(`{"a": [{"b": 1}]} [[]]`, bari.MaxDepth(3))
+	require.Nil(t, events[len(events)-1].Error)
+
+	events = parseAll(`{"a": [{"b": [1]}]}`, bari.MaxDepth(3))
+	require.Equal(t, "max depth limit of 3 exceeded", events[len(events)-1].Error.(bari.ParseError).Message)
+}
+
+func TestMaxDepthUnlimited(t *testing.T) {
+	const depth = 1000000
+	data := strings.Repeat("[", depth) + strings.Repeat("]", depth)
+
+	ch := make(chan bari.Event)
+	go bari.NewParser(strings.NewReader(data), bari.MaxDepth(0)).ParseAndClose(ch)
+
+	var n int
+	for ev := range ch {
+		require.Nil(t, ev.Error)
+		n++
+	}
+	require.Equal(t, 2*depth+1, n)
+}
+
+func TestSingleDocument(t *testing.T) {
+	testCases := []struct {
+		data     string
+		n        int
+		line     int
+		position int
+		offset   int64
+	}{
+		{`{"foo": "bar", "qux": "baz"}}`, 10, 1, 29, 28},
+		{`{"a":1}{"b":2}`, 6, 1, 8, 7},
+		{"{\"a\":1} \n garbage", 6, 2, 2, 10},
+		{"[1,\n2] [", 4, 2, 4, 7},
+	}
+
+	for _, tc := range testCases {
+		events := parseAll(tc.data, bari.SingleDocument())
+		require.Equal(t, tc.n+1, len(events), "data: %q", tc.data)
+		require.Equal(t, bari.ParseError{
+			Message:          "unexpected data after top-level value",
+			Line:             tc.line,
+			Position:         tc.position,
+			Offset:           tc.offset,
+			Column:           tc.position,
+			DocumentLine:     tc.line,
+			DocumentPosition: tc.position,
+			Err:              bari.ErrInvalidCharacter,
+		}, events[tc.n].Error, "data: %q", tc.data)
+	}
+
+	for _, data := range []string{`{"a":1}`, "\n [1, 2] \r\n\n", ""} {
+		events := parseAll(data, bari.SingleDocument())
+		for _, ev := range events {
+			require.Nil(t, ev.Error, "data: %q", data)
+		}
+	}
+}
+
+func TestDocumentEvents(t *testing.T) {
+	type boundary struct {
+		typ      bari.EventType
+		document int
+		line     int
+		column   int
+		offset   int64
+	}
+	boundaries := func(events []bari.Event) []boundary {
+		var b []boundary
+		for _, ev := range events {
+			if ev.Type == bari.DocumentStartEvent || ev.Type == bari.DocumentEndEvent {
+				b = append(b, boundary{ev.Type, ev.Document, ev.Line, ev.Column, ev.Offset})
+			}
+		}
+		return b
+	}
+
+	const data = "{\"a\": 1} {\"b\": [2]}\n{}"
+	expected := []boundary{
+		{bari.DocumentStartEvent, 0, 1, 1, 0},
+		{bari.DocumentEndEvent, 0, 1, 9, 8},
+		{bari.DocumentStartEvent, 1, 1, 10, 9},
+		{bari.DocumentEndEvent, 1, 1, 20, 19},
+		{bari.DocumentStartEvent, 2, 2, 1, 20},
+		{bari.DocumentEndEvent, 2, 2, 3, 22},
+	}
+
+	events := parseAll(data, bari.DocumentEvents())
+	require.Equal(t, expected, boundaries(events))
+	require.Equal(t, bari.DocumentStartEvent, events[0].Type)
+	require.Equal(t, bari.ObjectStartEvent, events[1].Type)
+	require.Equal(t, bari.ObjectEndEvent, events[len(events)-2].Type)
+	require.Equal(t, bari.DocumentEndEvent, events[len(events)-1].Type)
+
+	// Without the document events, the events are the same.
+	var rest []bari.Event
+	for _, ev := range events {
+		if ev.Type != bari.DocumentStartEvent && ev.Type != bari.DocumentEndEvent {
+			rest = append(rest, ev)
+		}
+	}
+	require.Equal(t, parseAll(data), rest)
+
+	// The positions of the other events don't apply to the document events.
+	require.Equal(t, expected, boundaries(parseAll(data, bari.DocumentEvents(), bari.EventPositions(), bari.CompactEvents())))
+
+	// Top-level scalars are documents too.
+	require.Equal(t, []boundary{
+		{bari.DocumentStartEvent, 0, 1, 2, 1},
+		{bari.DocumentEndEvent, 0, 1, 4, 3},
+	}, boundaries(parseAll(" 10\n", bari.DocumentEvents(), bari.StrictMode())))
+	require.Equal(t, []boundary{
+		{bari.DocumentStartEvent, 0, 2, 1, 1},
+		{bari.DocumentEndEvent, 0, 2, 4, 4},
+	}, boundaries(parseAll("\n\"x\"", bari.DocumentEvents(), bari.StrictMode())))
+
+	// A document which doesn't end has no DocumentEndEvent.
+	require.Equal(t, []boundary{
+		{bari.DocumentStartEvent, 0, 1, 1, 0},
+		{bari.DocumentEndEvent, 0, 1, 3, 2},
+		{bari.DocumentStartEvent, 1, 1, 4, 3},
+	}, boundaries(parseAll(`[] {"a": `, bari.DocumentEvents())))
+
+	// A skipped document still ends.
+	parser := bari.NewParser(strings.NewReader(data), bari.DocumentEvents())
+	ev, err := parser.Next()
+	require.NoError(t, err)
+	require.Equal(t, bari.DocumentStartEvent, ev.Type)
+	require.NoError(t, parser.SkipValue())
+	ev, err = parser.Next()
+	require.NoError(t, err)
+	require.Equal(t, bari.Event{Type: bari.DocumentEndEvent, Line: 1, Column: 9, Offset: 8}, ev)
+	ev, err = parser.Next()
+	require.NoError(t, err)
+	require.Equal(t, bari.Event{Type: bari.DocumentStartEvent, Document: 1, Line: 1, Column: 10, Offset: 9}, ev)
+}
+
+func TestSequenceNumbers(t *testing.T) {
+	codeJSON := readTestdata(t)
+	data := append(append(codeJSON, '\n'), codeJSON...)
+
+	// The numbers are contiguous across documents, the EOFEvent included.
+	var n uint64
+	err := bari.NewBytesParser(data, bari.SequenceNumbers(), bari.CompactEvents()).ParseFunc(func(ev bari.Event) bool {
+		require.Equal(t, n, ev.Seq)
+		n++
+		return true
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(len(parseAll(string(data), bari.CompactEvents()))+1), n)
+
+	// Peek doesn't consume a number, and discarded events don't take any.
+	parser := bari.NewParser(strings.NewReader(`[1, {"a": [2]}, 3] [4]`), bari.SequenceNumbers())
+	next := func(seq uint64) bari.Event {
+		ev, err := parser.Peek()
+		require.NoError(t, err)
+		require.Equal(t, seq, ev.Seq)
+		ev, err = parser.Next()
+		require.NoError(t, err)
+		require.Equal(t, seq, ev.Seq)
+		return ev
+	}
+	next(0)
+	next(1)
+	require.NoError(t, parser.SkipValue())
+	require.Equal(t, int64(3), next(2).Int)
+	next(3)
+	next(4)
+
+	// Reset starts over.
+	parser.Reset(strings.NewReader(`[1]`))
+	next(0)
+	next(1)
+	next(2)
+	ev, err := parser.Next()
+	require.Equal(t, io.EOF, err)
+	require.Equal(t, uint64(3), ev.Seq)
+
+	// The events of a failed parser are numbered too.
+	parser = bari.NewParser(strings.NewReader(`[x]`), bari.SequenceNumbers())
+	next(0)
+	for i := uint64(1); i < 3; i++ {
+		ev, err := parser.Next()
+		require.Error(t, err)
+		require.Equal(t, bari.EOFEvent, ev.Type)
+		require.Equal(t, i, ev.Seq)
+	}
+
+	// Without SequenceNumbers, every number is 0.
+	for _, ev := range parseAll(`[1, 2] {}`) {
+		require.Equal(t, uint64(0), ev.Seq)
+	}
+}
+
+func TestDisallowDuplicateKeys(t *testing.T) {
+	testCases := []struct {
+		data     string
+		message  string
+		line     int
+		position int
+		offset   int64
+		path     string
+		context  string
+	}{
+		{`{"a":1,"a":2}`, `duplicate key "a"`, 1, 8, 7, "", "in a key of the top-level object"},
+		{"{\"a\": 1,\n  \"b\": {\"a\": 2},\n  \"b\": 3}", `duplicate key "b"`, 3, 3, 28, "", "in a key of the top-level object"},
+		{`[{"a": [{"b": 1, "c": 2, "b": 3}]}]`, `duplicate key "b"`, 1, 26, 25, "/0/a/0", `in a key of object "/0/a/0"`},
+		{`{"a": 1, "\u0061": 2}`, `duplicate key "a"`, 1, 10, 9, "", "in a key of the top-level object"},
+	}
+
+	for _, tc := range testCases {
+		events := parseAll(tc.data, bari.DisallowDuplicateKeys())
+		require.Equal(t, bari.ParseError{
+			Message:          tc.message,
+			Line:             tc.line,
+			Position:         tc.position,
+			Offset:           tc.offset,
+			Column:           tc.position,
+			DocumentLine:     tc.line,
+			DocumentPosition: tc.position,
+			Path:             tc.path,
+			Context:          tc.context,
+			Err:              bari.ErrDuplicateKey,
+		}, events[len(events)-1].Error, "data: %q", tc.data)
+
+		events = parseAll(tc.data)
+		require.Nil(t, events[len(events)-1].Error, "data: %q", tc.data)
+	}
+
+	// Sibling objects, and the objects of every document, have their own keys.
+	for _, data := range []string{`[{"a": 1}, {"a": 2}]`, `{"a": {"a": {"a": 1}}, "b": {"a": 2}}`, `{"a": 1} {"a": 2}`} {
+		events := parseAll(data, bari.DisallowDuplicateKeys())
+		require.Nil(t, events[len(events)-1].Error, "data: %q", data)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("{")
+	for i := 0; i < 100000; i++ {
+		sb.WriteString(`"k` + strconv.Itoa(i) + `": 1, `)
+	}
+	sb.WriteString(`"k99999": 2}`)
+
+	events := parseAll(sb.String(), bari.DisallowDuplicateKeys())
+	require.Equal(t, `duplicate key "k99999"`, events[len(events)-1].Error.(bari.ParseError).Message)
+}
+
+func TestDisallowNulInStrings(t *testing.T) {
+	testCases := []struct {
+		data     string
+		message  string
+		position int
+	}{
+		{`{"a\u0000": 1}`, `\u0000 in a key of the object at ""`, 4},
+		{`{"a": {"b": 1, "c\u0000d": 2}}`, `\u0000 in a key of the object at "/a"`, 18},
+		{`{"a": "\u0000"}`, `\u0000 in the string at "/a"`, 8},
+		{`{"a": [1, "x", "foo\u0000bar"]}`, `\u0000 in the string at "/a/2"`, 20},
+		{`[{"a/b": ["é\u0000"]}]`, `\u0000 in the string at "/0/a~1b/0"`, 14},
+	}
+
+	for _, tc := range testCases {
+		events := parseAll(tc.data, bari.DisallowNulInStrings())
+		err := events[len(events)-1].Error.(bari.ParseError)
+		require.Equal(t, tc.message, err.Message, "data: %q", tc.data)
+		require.Equal(t, tc.position, err.Position, "data: %q", tc.data)
+
+		events = parseAll(tc.data)
+		require.Nil(t, events[len(events)-1].Error, "data: %q", tc.data)
+	}
+
+	for _, data := range []string{`["\u0001"]`, `["\\u0000"]`, `["\u1000"]`} {
+		events := parseAll(data, bari.DisallowNulInStrings())
+		require.Nil(t, events[len(events)-1].Error, "data: %q", data)
+	}
+
+	events := parseAll("[\"a\x00\"]", bari.DisallowNulInStrings())
+	require.Equal(t, "invalid control character 0x00 in string", events[len(events)-1].Error.(bari.ParseError).Message)
+}
+
+// jsonTestSuiteImplementationDefined tells whether StrictMode accepts each i_ file of testdata/jsontestsuite, whose
+// outcome RFC 8259 leaves to the implementation. The y_ files must be accepted and the n_ files rejected.
+var jsonTestSuiteImplementationDefined = map[string]bool{
+	// Numbers are only rejected when they overflow a float64.
+	"i_number_double_huge_neg_exp.json":   true,
+	"i_number_huge_exp.json":              false,
+	"i_number_neg_int_huge_exp.json":      false,
+	"i_number_pos_double_huge_exp.json":   false,
+	"i_number_real_neg_overflow.json":     false,
+	"i_number_real_pos_overflow.json":     false,
+	"i_number_real_underflow.json":        true,
+	"i_number_too_big_neg_int.json":       true,
+	"i_number_too_big_pos_int.json":       true,
+	"i_number_very_big_negative_int.json": true,
+
+	// Lone surrogates and invalid UTF-8 are rejected.
+	"i_object_key_lone_2nd_surrogate.json":                false,
+	"i_string_1st_surrogate_but_2nd_missing.json":         false,
+	"i_string_1st_valid_surrogate_2nd_invalid.json":       false,
+	"i_string_UTF-8_invalid_sequence.json":                false,
+	"i_string_UTF8_surrogate_U+D800.json":                 false,
+	"i_string_incomplete_surrogate_and_escape_valid.json": false,
+	"i_string_incomplete_surrogate_pair.json":             false,
+	"i_string_incomplete_surrogates_escape_valid.json":    false,
+	"i_string_invalid_lonely_surrogate.json":              false,
+	"i_string_invalid_surrogate.json":                     false,
+	"i_string_invalid_utf-8.json":                         false,
+	"i_string_inverted_surrogates_U+1D11E.json":           false,
+	"i_string_iso_latin_1.json":                           false,
+	"i_string_lone_second_surrogate.json":                 false,
+	"i_string_lone_utf8_continuation_byte.json":           false,
+	"i_string_not_in_unicode_range.json":                  false,
+	"i_string_overlong_sequence_2_bytes.json":             false,
+	"i_string_overlong_sequence_6_bytes.json":             false,
+	"i_string_overlong_sequence_6_bytes_null.json":        false,
+	"i_string_truncated-utf-8.json":                       false,
+
+	// The input must be UTF-8, without a byte order mark.
+	"i_string_UTF-16LE_with_BOM.json":         false,
+	"i_string_utf16BE_no_BOM.json":            false,
+	"i_string_utf16LE_no_BOM.json":            false,
+	"i_structure_UTF-8_BOM_empty_object.json": false,
+
+	// Within DefaultMaxDepth.
+	"i_structure_500_nested_arrays.json": true,
+}
+
+func TestStrictModeJSONTestSuite(t *testing.T) {
+	files, err := ioutil.ReadDir("./testdata/jsontestsuite")
+	require.Nil(t, err)
+
+	for _, fi := range files {
+		name := fi.Name()
+
+		t.Run(name, func(t *testing.T) {
+			data, err := ioutil.ReadFile("./testdata/jsontestsuite/" + name)
+			require.Nil(t, err)
+
+			var parseErr error
+			for _, ev := range parseAll(string(data), bari.StrictMode()) {
+				if ev.Error != nil {
+					parseErr = ev.Error
+				}
+			}
+
+			switch name[0] {
+			case 'y':
+				require.Nil(t, parseErr)
+			case 'n':
+				_, ok := parseErr.(bari.ParseError)
+				require.True(t, ok, "expected a ParseError, got %v", parseErr)
+			case 'i':
+				accepted, ok := jsonTestSuiteImplementationDefined[name]
+				require.True(t, ok, "undocumented file")
+				require.Equal(t, accepted, parseErr == nil, "error: %v", parseErr)
+			}
+		})
+	}
+}
+
+func TestStrictMode(t *testing.T) {
+	testCases := []testCase{
+		{
+			`"foo"`,
+			[]expectedEvent{
+				{bari.StringEvent, "foo", nil},
+			},
+		},
+		{
+			` -1.5 `,
+			[]expectedEvent{
+				{bari.NumberEvent, -1.5, nil},
+			},
+		},
+		{
+			`null`,
+			[]expectedEvent{
+				{bari.NullEvent, nil, nil},
+			},
+		},
+		{
+			`["\'"]`,
+			[]expectedEvent{
+				{bari.ArrayStartEvent, nil, nil},
+				{bari.EOFEvent, nil, bari.ParseError{Message: `invalid escape "\'" in string`, Line: 1, Position: 3, Offset: 2, Column: 3, DocumentLine: 1, DocumentPosition: 3, Path: "/0", Context: "in array element \"/0\"", Err: bari.ErrInvalidString}},
+			},
+		},
+		{
+			`1 2`,
+			[]expectedEvent{
+				{bari.NumberEvent, int64(1), nil},
+				{bari.EOFEvent, nil, bari.ParseError{Message: "unexpected data after top-level value", Line: 1, Position: 3, Offset: 2, Column: 3, DocumentLine: 1, DocumentPosition: 3, Err: bari.ErrInvalidCharacter}},
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		checkEvents(t, parseAll(c.data, bari.StrictMode()), c.events)
+	}
+
+	// StrictMode turns off the lenient options given before it.
+	events := parseAll("[+1]", bari.LenientNumbers(), bari.StrictMode())
+	require.Equal(t, "invalid number: leading +", events[len(events)-1].Error.(bari.ParseError).Message)
+
+	// Without StrictMode, \' is accepted.
+	checkEvents(t, parseAll(`["\'"]`), []expectedEvent{
+		{bari.ArrayStartEvent, nil, nil},
+		{bari.StringEvent, "'", nil},
+		{bari.ArrayEndEvent, nil, nil},
+	})
+}
+
+func TestCollectErrors(t *testing.T) {
+	const data = `{
+  "a": [1, 2,],
+  "b": "bad \q escape"
+  "c": true
+}`
+
+	events := parseAll(data, bari.CollectErrors())
+	checkEvents(t, events, []expectedEvent{
+		{bari.ObjectStartEvent, nil, nil},
+		{bari.ObjectKeyEvent, nil, nil},
+		{bari.StringEvent, "a", nil},
+		{bari.ObjectValueEvent, nil, nil},
+		{bari.ArrayStartEvent, nil, nil},
+		{bari.NumberEvent, int64(1), nil},
+		{bari.NumberEvent, int64(2), nil},
+		{bari.ErrorEvent, nil, bari.ParseError{Message: "trailing comma before ]", Line: 2, Position: 14, Offset: 15, Column: 14, DocumentLine: 2, DocumentPosition: 14, Path: "/a/1", Context: `after array element "/a/1"`, Err: bari.ErrInvalidCharacter}},
+		{bari.ArrayEndEvent, nil, nil},
+		{bari.ObjectKeyEvent, nil, nil},
+		{bari.StringEvent, "b", nil},
+		{bari.ObjectValueEvent, nil, nil},
+		{bari.ErrorEvent, nil, bari.ParseError{Message: `invalid escape "\q" in string`, Line: 3, Position: 13, Offset: 30, Column: 13, DocumentLine: 3, DocumentPosition: 13, Path: "/b", Context: `in object member "/b"`, Err: bari.ErrInvalidString}},
+		{bari.StringEvent, "bad q escape", nil},
+		{bari.ErrorEvent, nil, bari.ParseError{Message: `expected , but got "`, Line: 4, Position: 3, Offset: 43, Column: 3, DocumentLine: 4, DocumentPosition: 3, Path: "/b", Context: `after object member "/b"`, Err: bari.ErrInvalidCharacter}},
+		{bari.ObjectKeyEvent, nil, nil},
+		{bari.StringEvent, "c", nil},
+		{bari.ObjectValueEvent, nil, nil},
+		{bari.BooleanEvent, true, nil},
+		{bari.ObjectEndEvent, nil, nil},
+	})
+
+	// Without the option, the parser stops at the first error.
+	events = parseAll(data)
+	require.Equal(t, 8, len(events))
+	require.Equal(t, "trailing comma before ]", events[7].Error.(bari.ParseError).Message)
+
+	testCases := []struct {
+		data     string
+		opts     []bari.Option
+		messages []string
+		values   []interface{}
+	}{
+		{`[1 2 "a"]`, nil, []string{"expected , but got 2", `expected , but got "`}, []interface{}{int64(1), int64(2), "a"}},
+		{`{"a": 1,}`, nil, []string{"trailing comma before }"}, []interface{}{"a", int64(1)}},
+		{`["\x\u12G4\\"]`, nil, []string{`invalid escape "\x" in string`, `invalid \u escape "\u12G4" (expected 4 hex digits)`}, []interface{}{`xu12G4\`}},
+		{`{"a": 1, "a": 2}`, []bari.Option{bari.DisallowDuplicateKeys()}, []string{`duplicate key "a"`}, []interface{}{"a", int64(1), "a", int64(2)}},
+		// Unrecoverable errors still stop the parser.
+		{`[1 2 x]`, nil, []string{"expected , but got 2", "expected , but got x"}, []interface{}{int64(1), int64(2)}},
+		{`{"a": [1, 2`, nil, []string{"unexpected end of file while expecting ',' or ']'"}, []interface{}{"a", int64(1), int64(2)}},
+	}
+
+	for _, tc := range testCases {
+		var (
+			errs   []bari.ParseError
+			values []interface{}
+		)
+		for _, ev := range parseAll(tc.data, append(tc.opts, bari.CollectErrors())...) {
+			switch {
+			case ev.Error != nil:
+				errs = append(errs, ev.Error.(bari.ParseError))
+			case ev.Value != nil:
+				values = append(values, ev.Value)
+			}
+		}
+
+		var messages []string
+		for _, err := range errs {
+			messages = append(messages, err.Message)
+		}
+		require.Equal(t, tc.messages, messages, "data: %s", tc.data)
+		require.Equal(t, tc.values, values, "data: %s", tc.data)
+	}
+}
+
+func TestSkipInvalidValues(t *testing.T) {
+	checkEvents(t, parseAll(`{"a": truu, "b": 1}`, bari.SkipInvalidValues()), []expectedEvent{
+		{bari.ObjectStartEvent, nil, nil},
+		{bari.ObjectKeyEvent, nil, nil},
+		{bari.StringEvent, "a", nil},
+		{bari.ObjectValueEvent, nil, nil},
+		{bari.SkippedEvent, "truu", bari.ParseError{Message: "unexpected character u in literal true", Line: 1, Position: 10, Offset: 9, Column: 10, DocumentLine: 1, DocumentPosition: 10, Path: "/a", Context: `in object member "/a"`, Err: bari.ErrInvalidCharacter}},
+		{bari.ObjectKeyEvent, nil, nil},
+		{bari.StringEvent, "b", nil},
+		{bari.ObjectValueEvent, nil, nil},
+		{bari.NumberEvent, int64(1), nil},
+		{bari.ObjectEndEvent, nil, nil},
+	})
+
+	checkEvents(t, parseAll(`[1, 01, "x`+"\t"+`y", [2, x], ,4]`, bari.SkipInvalidValues()), []expectedEvent{
+		{bari.ArrayStartEvent, nil, nil},
+		{bari.NumberEvent, int64(1), nil},
+		{bari.SkippedEvent, "01", bari.ParseError{Message: "invalid number: leading zero", Line: 1, Position: 6, Offset: 5, Column: 6, DocumentLine: 1, DocumentPosition: 6, Path: "/1", Context: `in array element "/1"`, Err: bari.ErrInvalidNumber}},
+		{bari.SkippedEvent, "\"x\ty\"", bari.ParseError{Message: "invalid control character 0x09 in string", Line: 1, Position: 11, Offset: 10, Column: 11, DocumentLine: 1, DocumentPosition: 11, Path: "/2", Context: `in array element "/2"`, Err: bari.ErrInvalidString}},
+		{bari.ArrayStartEvent, nil, nil},
+		{bari.NumberEvent, int64(2), nil},
+		{bari.SkippedEvent, "x", bari.ParseError{Message: "unexpected character x", Line: 1, Position: 20, Offset: 19, Column: 20, DocumentLine: 1, DocumentPosition: 20, Path: "/3/1", Context: `in array element "/3/1"`, Err: bari.ErrInvalidCharacter}},
+		{bari.ArrayEndEvent, nil, nil},
+		{bari.SkippedEvent, "", bari.ParseError{Message: "unexpected character ,", Line: 1, Position: 24, Offset: 23, Column: 24, DocumentLine: 1, DocumentPosition: 24, Path: "/4", Context: `in array element "/4"`, Err: bari.ErrInvalidCharacter}},
+		{bari.NumberEvent, int64(4), nil},
+		{bari.ArrayEndEvent, nil, nil},
+	})
+
+	// Strings and nested containers are skipped as a whole.
+	testCases := []struct {
+		data    string
+		skipped string
+		values  []interface{}
+	}{
+		{`{"a": tru "b,c}", "d": 1}`, `tru "b,c}"`, []interface{}{"a", "d", int64(1)}},
+		{`[1, x{"a": [1, 2], "b": "]"}, 3]`, `x{"a": [1, 2], "b": "]"}`, []interface{}{int64(1), int64(3)}},
+		{`[1, x"a\",b", 2]`, `x"a\",b"`, []interface{}{int64(1), int64(2)}},
+	}
+
+	for _, tc := range testCases {
+		var values []interface{}
+		for _, ev := range parseAll(tc.data, bari.SkipInvalidValues()) {
+			switch {
+			case ev.Type == bari.SkippedEvent:
+				require.Equal(t, tc.skipped, ev.Value, "data: %s", tc.data)
+			case ev.Error != nil:
+				require.Nil(t, ev.Error, "data: %s", tc.data)
+			case ev.Value != nil:
+				values = append(values, ev.Value)
+			}
+		}
+		require.Equal(t, tc.values, values, "data: %s", tc.data)
+	}
+
+	// The parser stops when the input ends while skipping, with the error about the value.
+	events := parseAll(`{"a": tru "b": [1, 2`, bari.SkipInvalidValues())
+	require.Equal(t, 5, len(events))
+	require.Equal(t, bari.EOFEvent, events[4].Type)
+	require.Equal(t, "unexpected character   in literal true", events[4].Error.(bari.ParseError).Message)
+
+	// Or on errors which are not about a value.
+	events = parseAll(`{"a": "x, "b": 1}`, bari.SkipInvalidValues())
+	require.Equal(t, `expected , but got b`, events[len(events)-1].Error.(bari.ParseError).Message)
+	events = parseAll(`{"a" 1, "b": 1}`, bari.SkipInvalidValues())
+	require.Equal(t, `expected : but got 1`, events[len(events)-1].Error.(bari.ParseError).Message)
+}
@@ -0,0 +1,237 @@
+package bari
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+)
+
+// escapePointerToken escapes tok to be used as a reference token of a JSON Pointer.
+func escapePointerToken(tok string) string {
+	return pointerEscaper.Replace(tok)
+}
+
+// parsePointer splits a JSON Pointer as defined by RFC 6901 into its unescaped reference tokens.
+func parsePointer(ptr string) ([]string, error) {
+	if ptr == "" {
+		return nil, nil
+	}
+	if ptr[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON pointer %q: must start with /", ptr)
+	}
+
+	tokens := strings.Split(ptr[1:], "/")
+	for i, tok := range tokens {
+		tokens[i] = pointerUnescaper.Replace(tok)
+	}
+
+	return tokens, nil
+}
+
+// pathTracker follows the position of an event stream in the document.
+type pathTracker struct {
+	frames []pathFrame
+	key    bool
+
+	// depth is the number of frames making up the path of the value started by the last event.
+	depth int
+	value bool
+}
+
+type pathFrame struct {
+	array bool
+	index int
+	// key is reused by the next members, and by the frames pushed in place of this one.
+	key []byte
+}
+
+// update moves the tracker past ev.
+func (t *pathTracker) update(ev Event) {
+	if ev.Type == DocumentStartEvent || ev.Type == DocumentEndEvent {
+		return
+	}
+	t.value = false
+
+	switch ev.Type {
+	case ObjectKeyEvent:
+		if ev.ValueKind != NoValue || ev.Value != nil {
+			// The key of a member, with CompactEvents.
+			t.setKey(ev)
+			return
+		}
+		t.key = true
+		return
+	case StringEvent:
+		if t.key {
+			t.setKey(ev)
+			t.key = false
+			return
+		}
+	case ObjectEndEvent, ArrayEndEvent:
+		t.frames = t.frames[:len(t.frames)-1]
+		return
+	case ObjectValueEvent, EOFEvent, ErrorEvent, UnknownEvent:
+		return
+	}
+
+	if n := len(t.frames); n > 0 && t.frames[n-1].array {
+		t.frames[n-1].index++
+	}
+
+	t.value = true
+	t.depth = len(t.frames)
+
+	switch ev.Type {
+	case ObjectStartEvent:
+		t.push(false)
+	case ArrayStartEvent:
+		t.push(true)
+	}
+}
+
+// setKey sets the key of the innermost frame to the key of ev, which is empty if Valid did not decode it.
+func (t *pathTracker) setKey(ev Event) {
+	f := &t.frames[len(t.frames)-1]
+	if ev.ValueKind == BytesValue {
+		f.key = append(f.key[:0], ev.Bytes...)
+		return
+	}
+
+	key, _ := ev.Text()
+	f.key = append(f.key[:0], key...)
+}
+
+func (t *pathTracker) push(array bool) {
+	n := len(t.frames)
+	if n == cap(t.frames) {
+		t.frames = append(t.frames, pathFrame{})
+	}
+	t.frames = t.frames[:n+1]
+
+	f := &t.frames[n]
+	f.array, f.index, f.key = array, 0, f.key[:0]
+	if array {
+		f.index = -1
+	}
+}
+
+// at reports whether the last event started the value located by tokens.
+func (t *pathTracker) at(tokens []string) bool {
+	if !t.value || t.depth != len(tokens) {
+		return false
+	}
+
+	for i, tok := range tokens {
+		if !t.frames[i].matches(tok) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// onPath reports whether the innermost container encloses the value located by tokens, if it is there at all.
+func (t *pathTracker) onPath(tokens []string) bool {
+	n := len(t.frames) - 1
+	if n < 0 || n >= len(tokens) {
+		return false
+	}
+
+	for i, tok := range tokens[:n] {
+		if !t.frames[i].matches(tok) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// pointer returns the JSON pointer of the value started by the last event, or of the container
+// ended by it. If pattern is true, array indices are replaced by "-".
+func (t *pathTracker) pointer(pattern bool) string {
+	frames := t.frames
+	if t.value {
+		frames = frames[:t.depth]
+	}
+
+	var sb strings.Builder
+	for _, f := range frames {
+		f.write(&sb, pattern)
+	}
+
+	return sb.String()
+}
+
+// arrayIndex returns the index of the current element of the innermost array enclosing the last event.
+func (t *pathTracker) arrayIndex() (int, bool) {
+	frames := t.frames
+	if t.value {
+		frames = frames[:t.depth]
+	}
+
+	for i := len(frames) - 1; i >= 0; i-- {
+		if frames[i].array {
+			return frames[i].index, true
+		}
+	}
+
+	return 0, false
+}
+
+// containerPointer returns the JSON pointer of the innermost container.
+func (t *pathTracker) containerPointer() string {
+	var sb strings.Builder
+	for _, f := range t.frames[:len(t.frames)-1] {
+		f.write(&sb, false)
+	}
+
+	return sb.String()
+}
+
+// childPointer returns the JSON pointer of the last member or element of the innermost container.
+func (t *pathTracker) childPointer() string {
+	var sb strings.Builder
+	for _, f := range t.frames {
+		f.write(&sb, false)
+	}
+
+	return sb.String()
+}
+
+// nextPointer returns the JSON pointer of the value which the next event starts, if it isn't an end event.
+func (t *pathTracker) nextPointer() string {
+	var sb strings.Builder
+	for i, f := range t.frames {
+		if i == len(t.frames)-1 && f.array {
+			f.index++
+		}
+		f.write(&sb, false)
+	}
+
+	return sb.String()
+}
+
+// matches reports whether the current member or element of the container of f is located by tok.
+func (f pathFrame) matches(tok string) bool {
+	if f.array {
+		return strconv.Itoa(f.index) == tok
+	}
+	return string(f.key) == tok
+}
+
+func (f pathFrame) write(sb *strings.Builder, pattern bool) {
+	sb.WriteByte('/')
+	switch {
+	case f.array && pattern:
+		sb.WriteByte('-')
+	case f.array:
+		sb.WriteString(strconv.Itoa(f.index))
+	default:
+		sb.WriteString(escapePointerToken(string(f.key)))
+	}
+}
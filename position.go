@@ -0,0 +1,42 @@
+package bari
+
+import "sort"
+
+// A PositionIndex converts between byte offsets in the input of a Parser and line/column positions.
+//
+// Lines and columns are 1-based and columns count bytes, like the Position of a ParseError.
+// Lines are counted from the start of the input, across every document of a stream.
+type PositionIndex struct {
+	// lineStarts holds the offset of the first byte of every line but the first one.
+	lineStarts []int64
+}
+
+// LineCol returns the line and column of the byte at offset.
+func (idx *PositionIndex) LineCol(offset int64) (line, col int) {
+	i := sort.Search(len(idx.lineStarts), func(i int) bool {
+		return idx.lineStarts[i] > offset
+	})
+
+	start := int64(0)
+	if i > 0 {
+		start = idx.lineStarts[i-1]
+	}
+
+	return i + 1, int(offset-start) + 1
+}
+
+// Offset returns the offset of the byte at line and col, or -1 if the line was not read by the parser.
+//
+// It does not check that col is within the line.
+func (idx *PositionIndex) Offset(line, col int) int64 {
+	if line < 1 || line > len(idx.lineStarts)+1 {
+		return -1
+	}
+
+	start := int64(0)
+	if line > 1 {
+		start = idx.lineStarts[line-2]
+	}
+
+	return start + int64(col) - 1
+}
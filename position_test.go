@@ -0,0 +1,70 @@
+package bari_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/bari"
+)
+
+func TestPositionIndex(t *testing.T) {
+	const data = "{\"a\": \"é\",\n \"b\": [1,\n2]}\n\n[\"☂\"]\n"
+
+	require.Nil(t, bari.NewParser(strings.NewReader(data)).PositionIndex())
+
+	parser := bari.NewParser(strings.NewReader(data), bari.IndexLines())
+	ch := make(chan bari.Event)
+
+	go parser.ParseAndClose(ch)
+	for range ch {
+	}
+
+	idx := parser.PositionIndex()
+	require.NotNil(t, idx)
+
+	testCases := []struct {
+		offset    int64
+		line, col int
+	}{
+		{0, 1, 1},                                      // line start
+		{int64(strings.Index(data, "é")), 1, 8},        // first byte of a 2-byte rune
+		{int64(strings.Index(data, "é")) + 1, 1, 9},    // inside a 2-byte rune
+		{int64(strings.Index(data, "\n")), 1, 12},      // line end
+		{int64(strings.Index(data, " \"b\"")), 2, 1},   // line start
+		{int64(strings.Index(data, "2]}")), 3, 1},      // line start
+		{int64(strings.Index(data, "\n\n")) + 1, 4, 1}, // empty line
+		{int64(strings.Index(data, "[\"☂")), 5, 1},     // second document
+		{int64(strings.Index(data, "☂")) + 2, 5, 5},    // inside a 3-byte rune
+		{int64(len(data) - 1), 5, 8},                   // last byte
+	}
+
+	for _, c := range testCases {
+		line, col := idx.LineCol(c.offset)
+		require.Equal(t, c.line, line, "offset %d", c.offset)
+		require.Equal(t, c.col, col, "offset %d", c.offset)
+		require.Equal(t, c.offset, idx.Offset(c.line, c.col))
+	}
+
+	require.Equal(t, int64(-1), idx.Offset(0, 1))
+	require.Equal(t, int64(-1), idx.Offset(7, 1))
+}
+
+func TestPositionIndexMatchesParseError(t *testing.T) {
+	const data = "{\"a\": [1,\n  2,\n  x]}"
+
+	parser := bari.NewParser(strings.NewReader(data), bari.IndexLines())
+	ch := make(chan bari.Event)
+
+	go parser.ParseAndClose(ch)
+
+	var perr bari.ParseError
+	for ev := range ch {
+		if ev.Error != nil {
+			perr = ev.Error.(bari.ParseError)
+		}
+	}
+
+	offset := parser.PositionIndex().Offset(perr.Line, perr.Position)
+	require.Equal(t, int64(strings.Index(data, "x")), offset)
+}
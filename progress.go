@@ -0,0 +1,27 @@
+package bari
+
+import "math"
+
+// resetProgress schedules the next call to the Progress callback, every bytes after the current offset.
+func (p *Parser) resetProgress() {
+	p.progressAt = -1
+	p.nextProgress = math.MaxInt64
+	if p.opts.progress != nil {
+		p.nextProgress = p.offset + p.opts.progressEvery
+	}
+}
+
+// reportProgress calls the Progress callback with the current offset, which reached nextProgress.
+func (p *Parser) reportProgress() {
+	p.opts.progress(p.offset)
+	p.progressAt = p.offset
+	p.nextProgress = p.offset + p.opts.progressEvery
+}
+
+// endProgress calls the Progress callback once the end of the input is reached, unless it was already called with
+// the current offset.
+func (p *Parser) endProgress() {
+	if p.opts.progress != nil && p.progressAt != p.offset {
+		p.reportProgress()
+	}
+}
@@ -0,0 +1,60 @@
+package bari_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/bari"
+)
+
+func TestProgress(t *testing.T) {
+	codeJSON := readTestdata(t)
+	const every = 64 * 1024
+
+	var offsets []int64
+	p := bari.NewParser(bytes.NewReader(codeJSON), bari.Progress(every, func(offset int64) {
+		offsets = append(offsets, offset)
+	}))
+	require.NoError(t, p.ParseFunc(func(bari.Event) bool { return true }))
+
+	// The callback is called every 64KiB, strings being skipped in chunks, and at the end.
+	expected := len(codeJSON)/every + 1
+	require.InDelta(t, expected, len(offsets), 1)
+	for i := 1; i < len(offsets); i++ {
+		require.True(t, offsets[i] > offsets[i-1])
+		if i < len(offsets)-1 {
+			require.InDelta(t, every, offsets[i]-offsets[i-1], 1024)
+		}
+	}
+	require.Equal(t, int64(len(codeJSON)), offsets[len(offsets)-1])
+}
+
+func TestProgressEnd(t *testing.T) {
+	var offsets []int64
+	progress := bari.Progress(4, func(offset int64) {
+		offsets = append(offsets, offset)
+	})
+
+	// The end of the input is reported once, even if the parser reads it again.
+	p := bari.NewParser(strings.NewReader(`[1, 2] {}`), progress)
+	for p.More() {
+		_, err := p.Next()
+		require.NoError(t, err)
+	}
+	require.False(t, p.More())
+	require.Equal(t, []int64{4, 8, 9}, offsets)
+
+	// The end of the input is not reported again if the last byte was.
+	offsets = nil
+	p.Reset(strings.NewReader(`[1,2, 3]`))
+	require.NoError(t, p.ParseFunc(func(bari.Event) bool { return true }))
+	require.Equal(t, []int64{4, 8}, offsets)
+
+	// An input which ends in the middle of a document is reported too.
+	offsets = nil
+	p.Reset(strings.NewReader(`[1, `))
+	require.Error(t, p.ParseFunc(func(bari.Event) bool { return true }))
+	require.Equal(t, []int64{4}, offsets)
+}
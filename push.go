@@ -0,0 +1,186 @@
+package bari
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrFinished is returned by Feed and Finish once Finish or Close was called.
+var ErrFinished = errors.New("push parser finished")
+
+var errPushClosed = errors.New("push parser closed")
+
+// A PushParser parses input which is pushed to it in chunks, like the frames of a websocket, instead of being read
+// from an io.Reader.
+//
+// Each call to Feed returns the events which could be parsed with the input fed so far. A token may be split across
+// chunks: its event is returned once the chunk ending it is fed. Finish tells the parser that the input is over, so
+// that a document which doesn't end is reported.
+//
+// The parser runs in a goroutine which waits for the next chunk between calls: Finish or Close must be called to
+// release it, or the goroutine leaks.
+type PushParser struct {
+	parser *Parser
+	opts   []Option
+
+	// chunks hands the input over to the reader of the parser, which signals on idle when it needs more of it,
+	// setting waiting. Closing quit makes the reader fail. done is closed once the parser returned, with err.
+	chunks  chan []byte
+	idle    chan struct{}
+	quit    chan struct{}
+	waiting bool
+	done    chan struct{}
+	err     error
+
+	events   []Event
+	started  bool
+	finished bool
+}
+
+// NewPushParser creates a push parser configured with opts.
+func NewPushParser(opts ...Option) *PushParser {
+	return &PushParser{
+		opts:   opts,
+		chunks: make(chan []byte),
+		idle:   make(chan struct{}),
+		quit:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Feed parses chunk, following the input fed before, and returns the events parsed up to the end of chunk. The
+// chunk may be reused once Feed returns.
+//
+// If the input is not valid, the last event is an EOFEvent holding the ParseError, which is returned too, and every
+// later call returns it.
+func (pp *PushParser) Feed(chunk []byte) ([]Event, error) {
+	if pp.finished {
+		return nil, ErrFinished
+	}
+	pp.start()
+
+	if pp.ready() && len(chunk) > 0 {
+		pp.waiting = false
+		pp.chunks <- chunk
+		pp.ready()
+	}
+
+	return pp.flush()
+}
+
+// Finish ends the input and returns the last events, the last one being an EOFEvent, and the error of the parser if
+// the input is not valid.
+func (pp *PushParser) Finish() ([]Event, error) {
+	if pp.finished {
+		return nil, ErrFinished
+	}
+	pp.start()
+	pp.finished = true
+
+	if pp.ready() {
+		pp.waiting = false
+		close(pp.chunks)
+	}
+	<-pp.done
+
+	return pp.flush()
+}
+
+// Close stops the parser without ending the input, for instance when the connection feeding it is lost, and
+// discards the events not returned yet. It does nothing once Finish or Close was called.
+func (pp *PushParser) Close() error {
+	if pp.finished {
+		return nil
+	}
+	pp.finished = true
+
+	if pp.started {
+		close(pp.quit)
+		<-pp.done
+	}
+	pp.events = nil
+
+	return nil
+}
+
+// start starts the parser on the first call.
+func (pp *PushParser) start() {
+	if pp.started {
+		return
+	}
+	pp.started = true
+
+	pp.parser = NewParser(&pushReader{chunks: pp.chunks, idle: pp.idle, quit: pp.quit}, pp.opts...)
+	go func() {
+		defer close(pp.done)
+		pp.err = pp.parser.ParseFunc(func(ev Event) bool {
+			pp.events = append(pp.events, ev.Copy())
+			return true
+		})
+	}()
+}
+
+// ready waits until the parser needs more input or returned, and reports whether it needs more input.
+func (pp *PushParser) ready() bool {
+	if !pp.waiting {
+		select {
+		case <-pp.idle:
+			pp.waiting = true
+		case <-pp.done:
+		}
+	}
+
+	return pp.waiting
+}
+
+// flush returns the events parsed since the last call, and the error of the parser once it returned.
+func (pp *PushParser) flush() ([]Event, error) {
+	events := pp.events
+	pp.events = nil
+
+	select {
+	case <-pp.done:
+		return events, pp.err
+	default:
+		return events, nil
+	}
+}
+
+// pushReader reads the chunks fed to a PushParser.
+type pushReader struct {
+	chunks <-chan []byte
+	idle   chan<- struct{}
+	quit   <-chan struct{}
+	chunk  []byte
+	eof    bool
+}
+
+func (r *pushReader) Read(b []byte) (int, error) {
+	for len(r.chunk) == 0 {
+		if r.eof {
+			return 0, io.EOF
+		}
+
+		select {
+		case r.idle <- struct{}{}:
+		case <-r.quit:
+			return 0, errPushClosed
+		}
+
+		select {
+		case chunk, ok := <-r.chunks:
+			if !ok {
+				r.eof = true
+				return 0, io.EOF
+			}
+			r.chunk = chunk
+		case <-r.quit:
+			return 0, errPushClosed
+		}
+	}
+
+	n := copy(b, r.chunk)
+	r.chunk = r.chunk[n:]
+
+	return n, nil
+}
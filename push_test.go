@@ -0,0 +1,162 @@
+package bari_test
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/bari"
+)
+
+// parseFuncAll returns all the events of data emitted by ParseFunc, the final EOFEvent included.
+func parseFuncAll(data string, opts ...bari.Option) ([]bari.Event, error) {
+	var events []bari.Event
+	err := bari.NewParser(strings.NewReader(data), opts...).ParseFunc(func(ev bari.Event) bool {
+		events = append(events, ev.Copy())
+		return true
+	})
+
+	return events, err
+}
+
+// feedAll feeds the chunks of data ending at splits to a push parser, and returns all of its events.
+func feedAll(t *testing.T, data string, splits []int, opts ...bari.Option) ([]bari.Event, error) {
+	pp := bari.NewPushParser(opts...)
+
+	var all []bari.Event
+	start := 0
+	for _, end := range append(splits, len(data)) {
+		events, err := pp.Feed([]byte(data[start:end]))
+		all = append(all, events...)
+		if err != nil {
+			_, ferr := pp.Finish()
+			require.Equal(t, err, ferr)
+			return all, err
+		}
+		start = end
+	}
+
+	events, err := pp.Finish()
+	return append(all, events...), err
+}
+
+func TestPushParser(t *testing.T) {
+	for _, c := range testCases {
+		expected, expectedErr := parseFuncAll(c.data)
+
+		// Split at every byte.
+		for i := 0; i <= len(c.data); i++ {
+			events, err := feedAll(t, c.data, []int{i})
+			require.Equal(t, expectedErr, err, "data: %q, split: %d", c.data, i)
+			require.Equal(t, expected, events, "data: %q, split: %d", c.data, i)
+		}
+
+		// One byte at a time.
+		var splits []int
+		for i := 0; i < len(c.data); i++ {
+			splits = append(splits, i)
+		}
+		events, err := feedAll(t, c.data, splits)
+		require.Equal(t, expectedErr, err, "data: %q", c.data)
+		require.Equal(t, expected, events, "data: %q", c.data)
+	}
+
+	// The options are those of the parser, and the events don't refer to the chunks.
+	data := `{"a": "xyz", "b": [1, 2]} {"c": "é"}`
+	opts := []bari.Option{bari.ByteStrings(), bari.EventPositions(), bari.DocumentEvents(), bari.SequenceNumbers()}
+	expected, err := parseFuncAll(data, opts...)
+	require.NoError(t, err)
+	for i := 0; i <= len(data); i++ {
+		events, err := feedAll(t, data, []int{i}, opts...)
+		require.NoError(t, err)
+		require.Equal(t, expected, events, "split: %d", i)
+	}
+}
+
+func TestPushParserEvents(t *testing.T) {
+	pp := bari.NewPushParser()
+
+	// The events are returned as soon as they are parsed.
+	events, err := pp.Feed([]byte(`[1, "ab`))
+	require.NoError(t, err)
+	checkEvents(t, events, []expectedEvent{
+		{bari.ArrayStartEvent, nil, nil},
+		{bari.NumberEvent, int64(1), nil},
+	})
+
+	events, err = pp.Feed(nil)
+	require.NoError(t, err)
+	require.Empty(t, events)
+
+	events, err = pp.Feed([]byte(`c"] [2`))
+	require.NoError(t, err)
+	checkEvents(t, events, []expectedEvent{
+		{bari.StringEvent, "abc", nil},
+		{bari.ArrayEndEvent, nil, nil},
+		{bari.ArrayStartEvent, nil, nil},
+	})
+
+	// The input ending in the middle of a document is an error.
+	events, err = pp.Finish()
+	require.Error(t, err)
+	require.Equal(t, 2, len(events))
+	require.Equal(t, bari.Event{Type: bari.NumberEvent, Value: int64(2), ValueKind: bari.IntValue, Int: 2, IsInt: true, Depth: 1}, events[0])
+	require.Equal(t, bari.Event{Type: bari.EOFEvent, Error: err}, events[1])
+
+	_, err = pp.Feed([]byte(`[]`))
+	require.Equal(t, bari.ErrFinished, err)
+	_, err = pp.Finish()
+	require.Equal(t, bari.ErrFinished, err)
+
+	// Once the input is invalid, every call returns the error.
+	pp = bari.NewPushParser()
+	events, err = pp.Feed([]byte(`[1, x`))
+	require.Error(t, err)
+	require.Equal(t, bari.EOFEvent, events[len(events)-1].Type)
+	events, err2 := pp.Feed([]byte(`]`))
+	require.Empty(t, events)
+	require.Equal(t, err, err2)
+	events, err2 = pp.Finish()
+	require.Empty(t, events)
+	require.Equal(t, err, err2)
+
+	// An empty input is no document.
+	events, err = bari.NewPushParser().Finish()
+	require.NoError(t, err)
+	require.Equal(t, []bari.Event{{Type: bari.EOFEvent}}, events)
+}
+
+func TestPushParserClose(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	for i := 0; i < 100; i++ {
+		pp := bari.NewPushParser()
+		events, err := pp.Feed([]byte(`[1, {"a": "b`))
+		require.NoError(t, err)
+		require.NotEmpty(t, events)
+
+		require.NoError(t, pp.Close())
+		require.NoError(t, pp.Close())
+
+		_, err = pp.Feed([]byte(`"}]`))
+		require.Equal(t, bari.ErrFinished, err)
+		_, err = pp.Finish()
+		require.Equal(t, bari.ErrFinished, err)
+	}
+
+	// Closing a parser which was never fed, or after Finish, is fine too.
+	require.NoError(t, bari.NewPushParser().Close())
+	pp := bari.NewPushParser()
+	_, err := pp.Finish()
+	require.NoError(t, err)
+	require.NoError(t, pp.Close())
+
+	// Close waits for the parsers to return, but their goroutines may still be exiting.
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	require.True(t, runtime.NumGoroutine() <= before, "%d goroutines left running", runtime.NumGoroutine()-before)
+}
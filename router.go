@@ -0,0 +1,161 @@
+package bari
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// A Router distributes the documents of a newline-delimited JSON stream to a set of outputs,
+// based on the value found at a JSON pointer in each document.
+//
+// Each document is delivered as its raw bytes, without the trailing newline. An output applies
+// back-pressure through its own buffer: the router only blocks when the output a document is
+// routed to is full.
+type Router struct {
+	// Pointer locates the routing key in each document, for example "/tenant_id".
+	Pointer string
+
+	// Route maps a routing key to the index of an output in Outputs. The key is the value of the
+	// StringEvent, NumberEvent, BooleanEvent or NullEvent found at Pointer.
+	Route func(key interface{}) int
+
+	Outputs []chan<- []byte
+
+	// Default receives the documents without a scalar value at Pointer and those for which Route
+	// returned an index out of range. If it is nil these documents are dropped.
+	Default chan<- []byte
+
+	// Filter, if set, selects the documents to route. The others are dropped.
+	Filter *RecordFilter
+}
+
+// Run routes the documents read from r until the end of the input, an invalid document or
+// the cancellation of ctx.
+//
+// A document is only parsed up to its routing key, or up to the end of the object or array which
+// would hold it, so that it is only invalid if its beginning is. Documents are parsed entirely
+// when Filter.Match is set.
+//
+// Run takes ownership of Outputs and Default, and closes them when it returns: the caller must not
+// close them itself. A channel used for several outputs, or as an output and Default, is closed once.
+func (rt *Router) Run(ctx context.Context, r io.Reader) error {
+	defer func() {
+		closed := make(map[chan<- []byte]bool)
+		closeOnce := func(out chan<- []byte) {
+			if out != nil && !closed[out] {
+				close(out)
+				closed[out] = true
+			}
+		}
+		for _, out := range rt.Outputs {
+			closeOnce(out)
+		}
+		closeOnce(rt.Default)
+	}()
+
+	tokens, err := parsePointer(rt.Pointer)
+	if err != nil {
+		return err
+	}
+
+	return readRecords(r, func(line int, raw []byte) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if rt.Filter != nil && !rt.Filter.prefilter(raw) {
+			return nil
+		}
+
+		var (
+			key   interface{}
+			found bool
+		)
+		if rt.Filter != nil && rt.Filter.Match != nil {
+			// Match needs every event of the document.
+			events, err := parseRecord(raw)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+			if !rt.Filter.Match(events) {
+				return nil
+			}
+			key, found = findPointer(events, tokens)
+		} else {
+			var err error
+			if key, found, err = extractPointer(raw, tokens); err != nil {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+
+		out := rt.Default
+		if found {
+			if i := rt.Route(key); i >= 0 && i < len(rt.Outputs) {
+				out = rt.Outputs[i]
+			}
+		}
+		if out == nil {
+			return nil
+		}
+
+		select {
+		case out <- append([]byte(nil), raw...):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+// extractPointer returns the scalar value located by tokens in the document raw, parsing it only up to the value,
+// or up to the end of the object or array which would hold it. The rest of raw isn't validated.
+func extractPointer(raw []byte, tokens []string) (interface{}, bool, error) {
+	p := NewBytesParser(raw)
+
+	var tracker pathTracker
+	for {
+		ev, err := p.Next()
+		if err == io.EOF {
+			return nil, false, nil
+		} else if err != nil {
+			return nil, false, err
+		}
+
+		if (ev.Type == ObjectEndEvent || ev.Type == ArrayEndEvent) && tracker.onPath(tokens) {
+			return nil, false, nil
+		}
+
+		tracker.update(ev)
+		if !tracker.at(tokens) {
+			continue
+		}
+
+		switch ev.Type {
+		case StringEvent, NumberEvent, BooleanEvent, NullEvent:
+			return ev.Value, true, nil
+		default:
+			return nil, false, nil
+		}
+	}
+}
+
+// findPointer returns the scalar value located by tokens in a document.
+func findPointer(events []Event, tokens []string) (interface{}, bool) {
+	var tracker pathTracker
+	for _, ev := range events {
+		tracker.update(ev)
+		if !tracker.at(tokens) {
+			continue
+		}
+
+		switch ev.Type {
+		case StringEvent, NumberEvent, BooleanEvent, NullEvent:
+			return ev.Value, true
+		default:
+			return nil, false
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,190 @@
+package bari_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/bari"
+)
+
+func collectRoutes(outs []chan []byte, delays []time.Duration) ([][]string, *sync.WaitGroup) {
+	res := make([][]string, len(outs))
+
+	var wg sync.WaitGroup
+	for i := range outs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for raw := range outs[i] {
+				time.Sleep(delays[i])
+				res[i] = append(res[i], string(raw))
+			}
+		}(i)
+	}
+
+	return res, &wg
+}
+
+func TestRouter(t *testing.T) {
+	var (
+		lines []string
+		exp   = make([][]string, 3)
+	)
+	for i := 0; i < 100; i++ {
+		var line string
+		switch i % 5 {
+		case 0, 1:
+			line = fmt.Sprintf(`{"tenant_id": "fast", "n": %d}`, i)
+			exp[0] = append(exp[0], line)
+		case 2, 3:
+			line = fmt.Sprintf(`{"n": %d, "tenant_id": "slow"}`, i)
+			exp[1] = append(exp[1], line)
+		case 4:
+			line = fmt.Sprintf(`{"n": %d, "nested": {"tenant_id": "fast"}}`, i)
+			exp[2] = append(exp[2], line)
+		}
+		lines = append(lines, line)
+	}
+
+	outs := []chan []byte{make(chan []byte, 4), make(chan []byte), make(chan []byte, 4)}
+	res, wg := collectRoutes(outs, []time.Duration{0, time.Millisecond, 0})
+
+	router := bari.Router{
+		Pointer: "/tenant_id",
+		Route: func(key interface{}) int {
+			if key == "slow" {
+				return 1
+			}
+			return 0
+		},
+		Outputs: []chan<- []byte{outs[0], outs[1]},
+		Default: outs[2],
+	}
+
+	err := router.Run(context.Background(), strings.NewReader(strings.Join(lines, "\n")+"\n\n"))
+	require.Nil(t, err)
+
+	wg.Wait()
+	require.Equal(t, exp, res)
+}
+
+func TestRouterFilter(t *testing.T) {
+	out := make(chan []byte, 10)
+
+	router := bari.Router{
+		Pointer: "/a",
+		Route:   func(key interface{}) int { return 0 },
+		Outputs: []chan<- []byte{out},
+		Filter: &bari.RecordFilter{
+			Substrings: [][]byte{[]byte("keep")},
+		},
+	}
+
+	err := router.Run(context.Background(), strings.NewReader("{\"a\": \"keep\"}\n{\"a\": \"drop\"}\n{\"a\": \"keep too\"}\n"))
+	require.Nil(t, err)
+
+	var res []string
+	for raw := range out {
+		res = append(res, string(raw))
+	}
+	require.Equal(t, []string{`{"a": "keep"}`, `{"a": "keep too"}`}, res)
+	require.Equal(t, int64(1), router.Filter.Skipped)
+}
+
+func TestRouterInvalidDocument(t *testing.T) {
+	outs := []chan []byte{make(chan []byte), make(chan []byte)}
+	res, wg := collectRoutes(outs, []time.Duration{time.Millisecond, 0})
+
+	router := bari.Router{
+		Pointer: "/a~1b",
+		Route:   func(key interface{}) int { return 0 },
+		Outputs: []chan<- []byte{outs[0]},
+		Default: outs[1],
+	}
+
+	const data = "{\"a/b\": 1}\n{\"c\": 2}\n{\"a/b\": }\n{\"a/b\": 3}\n"
+
+	err := router.Run(context.Background(), strings.NewReader(data))
+
+	var perr bari.ParseError
+	require.True(t, errors.As(err, &perr))
+	require.True(t, strings.HasPrefix(err.Error(), "line 3: "))
+
+	wg.Wait()
+	require.Equal(t, [][]string{{`{"a/b": 1}`}, {`{"c": 2}`}}, res)
+}
+
+func TestRouterEarlyExit(t *testing.T) {
+	outs := []chan []byte{make(chan []byte), make(chan []byte)}
+	res, wg := collectRoutes(outs, []time.Duration{0, 0})
+
+	router := bari.Router{
+		Pointer: "/a/b",
+		Route:   func(key interface{}) int { return 0 },
+		Outputs: []chan<- []byte{outs[0]},
+		Default: outs[1],
+	}
+
+	// Parsing stops at the key, or at the end of the object which would hold it, before the invalid bytes.
+	const data = "{\"a\": {\"b\": 1, \"c\": }\n{\"a\": {\"c\": 1}, \"d\": ]\n{\"x\": [1, 2], \"a\": {\"b\": \"y\"}} {\n"
+
+	require.Nil(t, router.Run(context.Background(), strings.NewReader(data)))
+
+	wg.Wait()
+	require.Equal(t, [][]string{
+		{`{"a": {"b": 1, "c": }`, `{"x": [1, 2], "a": {"b": "y"}} {`},
+		{`{"a": {"c": 1}, "d": ]`},
+	}, res)
+}
+
+func TestRouterSharedOutputs(t *testing.T) {
+	out := make(chan []byte, 10)
+
+	router := bari.Router{
+		Pointer: "/a",
+		Route:   func(key interface{}) int { return int(key.(int64)) },
+		Outputs: []chan<- []byte{out, out},
+		Default: out,
+	}
+
+	const data = "{\"a\": 0}\n{\"a\": 1}\n{\"a\": 2}\n"
+
+	require.Nil(t, router.Run(context.Background(), strings.NewReader(data)))
+
+	var res []string
+	for raw := range out {
+		res = append(res, string(raw))
+	}
+	require.Equal(t, []string{`{"a": 0}`, `{"a": 1}`, `{"a": 2}`}, res)
+}
+
+func TestRouterCancel(t *testing.T) {
+	out := make(chan []byte)
+
+	router := bari.Router{
+		Pointer: "/a",
+		Route:   func(key interface{}) int { return 0 },
+		Outputs: []chan<- []byte{out},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error)
+	go func() {
+		done <- router.Run(ctx, strings.NewReader("{\"a\": 1}\n{\"a\": 2}\n"))
+	}()
+
+	require.Equal(t, `{"a": 1}`, string(<-out))
+	cancel()
+
+	require.Equal(t, context.Canceled, <-done)
+
+	_, ok := <-out
+	require.False(t, ok)
+}
@@ -0,0 +1,46 @@
+package bari
+
+// eventTypes is the number of event types.
+const eventTypes = int(DocumentEndEvent) + 1
+
+// Stats holds counters of what a parser parsed since it was created or Reset.
+type Stats struct {
+	// Bytes is the number of bytes of the input read.
+	Bytes int64
+	// Events counts the events parsed, indexed by type. The EOFEvent of an input which ends cleanly is not counted,
+	// nor are the events discarded by SkipValue.
+	Events [eventTypes]int
+	// MaxDepth is the deepest nesting of objects and arrays.
+	MaxDepth int
+	// Documents is the number of documents started.
+	Documents int
+	// StringBytes is the number of bytes of the strings decoded, the keys included.
+	StringBytes int64
+}
+
+// Stats returns the counters of the parser. Only Bytes is counted by a parser created with DisableStats.
+//
+// Stats may be called concurrently with Parse.
+func (p *Parser) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s := p.stats
+	s.Bytes = p.offset
+
+	return s
+}
+
+// count updates the counters of the parser with ev, which is emitted.
+func (p *Parser) count(ev Event) {
+	p.stats.Events[ev.Type]++
+
+	switch ev.Type {
+	case ObjectStartEvent, ArrayStartEvent:
+		if ev.Depth+1 > p.stats.MaxDepth {
+			p.stats.MaxDepth = ev.Depth + 1
+		}
+	case StringEvent, ObjectKeyEvent:
+		p.stats.StringBytes += int64(len(ev.Str) + len(ev.Bytes))
+	}
+}
@@ -0,0 +1,96 @@
+package bari_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/bari"
+)
+
+func TestStats(t *testing.T) {
+	const data = `{"a": [1, "bc", {"d": null}]} ["é", true]`
+
+	p := bari.NewParser(strings.NewReader(data))
+	require.Equal(t, bari.Stats{}, p.Stats())
+	require.NoError(t, p.ParseFunc(func(bari.Event) bool { return true }))
+
+	var events [len(bari.Stats{}.Events)]int
+	events[bari.ObjectStartEvent] = 2
+	events[bari.ObjectEndEvent] = 2
+	events[bari.ArrayStartEvent] = 2
+	events[bari.ArrayEndEvent] = 2
+	events[bari.ObjectKeyEvent] = 2
+	events[bari.ObjectValueEvent] = 2
+	events[bari.StringEvent] = 4
+	events[bari.NumberEvent] = 1
+	events[bari.NullEvent] = 1
+	events[bari.BooleanEvent] = 1
+
+	expected := bari.Stats{
+		Bytes:       int64(len(data)),
+		Events:      events,
+		MaxDepth:    3,
+		Documents:   2,
+		StringBytes: 6,
+	}
+	require.Equal(t, expected, p.Stats())
+
+	// The same events are counted for the same input.
+	p.Reset(strings.NewReader(data))
+	require.Equal(t, bari.Stats{}, p.Stats())
+	for {
+		_, err := p.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+	}
+	require.Equal(t, expected, p.Stats())
+
+	// Only the bytes are counted without the counters.
+	p = bari.NewParser(strings.NewReader(data), bari.DisableStats())
+	require.NoError(t, p.ParseFunc(func(bari.Event) bool { return true }))
+	require.Equal(t, bari.Stats{Bytes: int64(len(data))}, p.Stats())
+}
+
+func TestStatsTestdata(t *testing.T) {
+	codeJSON := readTestdata(t)
+
+	p := bari.NewParser(bytes.NewReader(codeJSON))
+
+	var prev bari.Stats
+	for i := 0; ; i++ {
+		_, err := p.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		if i%1000 != 0 {
+			continue
+		}
+		stats := p.Stats()
+		require.True(t, stats.Bytes >= prev.Bytes)
+		require.True(t, stats.MaxDepth >= prev.MaxDepth)
+		require.True(t, stats.StringBytes >= prev.StringBytes)
+		for typ, n := range stats.Events {
+			require.True(t, n >= prev.Events[typ])
+		}
+		prev = stats
+	}
+
+	stats := p.Stats()
+	require.Equal(t, int64(len(codeJSON)), stats.Bytes)
+	require.Equal(t, 1, stats.Documents)
+	require.True(t, stats.MaxDepth > 1)
+	require.True(t, stats.StringBytes > 0)
+	require.Equal(t, stats.Events[bari.ObjectStartEvent], stats.Events[bari.ObjectEndEvent])
+	require.Equal(t, stats.Events[bari.ArrayStartEvent], stats.Events[bari.ArrayEndEvent])
+	require.Equal(t, stats.Events[bari.ObjectKeyEvent], stats.Events[bari.ObjectValueEvent])
+	require.True(t, stats.Events[bari.StringEvent] > 0)
+	require.True(t, stats.Events[bari.NumberEvent] > 0)
+	require.Equal(t, 0, stats.Events[bari.EOFEvent])
+}
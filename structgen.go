@@ -0,0 +1,418 @@
+package bari
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GenConfig configures GenerateStructs.
+type GenConfig struct {
+	// Package is the name used in the package clause. It defaults to "main".
+	Package string
+	// TypeName is the name of the type of the documents. It defaults to "Document".
+	TypeName string
+
+	// TypeNames overrides the names of the struct types generated for objects, keyed by the JSON pointer of
+	// the object in the documents. The elements of an array are referred to with the "-" token, so the objects
+	// in {"users": [{"name": "foo"}]} are named by the "/users/-" entry.
+	//
+	// By default, a struct type is named after its parent type and the field holding it.
+	TypeNames map[string]string
+
+	// RawMessage makes values observed with conflicting types use json.RawMessage instead of interface{}.
+	RawMessage bool
+}
+
+var errNoSamples = errors.New("no sample documents")
+
+// GenerateStructs reads sample documents from samples and returns the Go source of types they can be decoded
+// into with encoding/json.
+//
+// Objects become struct types with a json tag for each key, except for the keys encoding/json can't match with a
+// tag, like the empty key or one holding a comma or a quote, whose fields are tagged "-". A field which was missing
+// from some of the objects is tagged with omitempty, and one which was missing or null is a pointer, unless its
+// type is a slice or an interface. Numbers are int64 if all the samples at a path are integers and float64
+// otherwise. A value which was observed with types that can't be reconciled, like a string and an object, is an
+// interface{} or a json.RawMessage.
+//
+// The output is gofmt-formatted and only depends on the samples and cfg.
+func GenerateStructs(samples io.Reader, cfg GenConfig) ([]byte, error) {
+	if cfg.Package == "" {
+		cfg.Package = "main"
+	}
+	if cfg.TypeName == "" {
+		cfg.TypeName = "Document"
+	}
+
+	root, err := inferShape(samples)
+	if err != nil {
+		return nil, err
+	}
+
+	g := structGen{
+		cfg:  cfg,
+		used: make(map[string]bool),
+	}
+	g.generate(root)
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "// Code generated by bari. DO NOT EDIT.\n\npackage %s\n\n", cfg.Package)
+	if g.rawMessage {
+		buf.WriteString("import \"encoding/json\"\n\n")
+	}
+	buf.Write(g.decls.Bytes())
+
+	return format.Source(buf.Bytes())
+}
+
+type shapeKind uint
+
+const (
+	boolKind shapeKind = 1 << iota
+	intKind
+	floatKind
+	stringKind
+	objectKind
+	arrayKind
+)
+
+// A shape accumulates what was observed at a path of the sample documents.
+type shape struct {
+	kinds shapeKind
+	nulls int
+
+	// objects is the number of objects observed, and fields their keys in order of appearance.
+	objects int
+	fields  []*shapeField
+
+	// elem is the shape of the elements of the arrays, or nil if they were all empty.
+	elem *shape
+}
+
+type shapeField struct {
+	key     string
+	present int
+	shape   shape
+}
+
+func (s *shape) field(key string) *shapeField {
+	for _, f := range s.fields {
+		if f.key == key {
+			return f
+		}
+	}
+
+	f := &shapeField{key: key}
+	s.fields = append(s.fields, f)
+
+	return f
+}
+
+// inferShape parses every document of r and merges their shapes.
+func inferShape(r io.Reader) (*shape, error) {
+	parser := NewParser(r)
+	ch := make(chan Event)
+	go parser.ParseAndClose(ch)
+	defer parser.Stop()
+
+	next := func() (Event, error) {
+		ev, ok := <-ch
+		switch {
+		case !ok:
+			return ev, ErrUnexpectedEOF
+		case ev.Error != nil:
+			return ev, ev.Error
+		}
+		return ev, nil
+	}
+
+	var (
+		root shape
+		n    int
+	)
+	for ev := range ch {
+		switch {
+		case ev.Error != nil:
+			return nil, ev.Error
+		case ev.Type == EOFEvent:
+			continue
+		}
+		if err := root.observe(next, ev); err != nil {
+			return nil, err
+		}
+		n++
+	}
+
+	if n == 0 {
+		return nil, errNoSamples
+	}
+
+	return &root, nil
+}
+
+// observe merges the value starting with ev into s, reading the rest of its events with next.
+func (s *shape) observe(next func() (Event, error), ev Event) error {
+	switch ev.Type {
+	case NullEvent:
+		s.nulls++
+	case BooleanEvent:
+		s.kinds |= boolKind
+	case StringEvent:
+		s.kinds |= stringKind
+	case NumberEvent:
+		if ev.IsInt {
+			s.kinds |= intKind
+		} else {
+			s.kinds |= floatKind
+		}
+	case ObjectStartEvent:
+		s.kinds |= objectKind
+		s.objects++
+
+		for {
+			ev, err := next()
+			if err != nil {
+				return err
+			}
+			if ev.Type == ObjectEndEvent {
+				return nil
+			}
+
+			// ObjectKeyEvent, the key, ObjectValueEvent and the value
+			key, err := next()
+			if err != nil {
+				return err
+			}
+			if _, err := next(); err != nil {
+				return err
+			}
+			value, err := next()
+			if err != nil {
+				return err
+			}
+
+			f := s.field(key.Value.(string))
+			f.present++
+			if err := f.shape.observe(next, value); err != nil {
+				return err
+			}
+		}
+	case ArrayStartEvent:
+		s.kinds |= arrayKind
+
+		for {
+			ev, err := next()
+			if err != nil {
+				return err
+			}
+			if ev.Type == ArrayEndEvent {
+				return nil
+			}
+
+			if s.elem == nil {
+				s.elem = &shape{}
+			}
+			if err := s.elem.observe(next, ev); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unexpected event %s", ev.Type)
+	}
+
+	return nil
+}
+
+type structGen struct {
+	cfg GenConfig
+
+	decls      bytes.Buffer
+	used       map[string]bool
+	rawMessage bool
+
+	// queue holds the struct types named but not declared yet.
+	queue []pendingStruct
+}
+
+type pendingStruct struct {
+	name string
+	s    *shape
+	path string
+}
+
+func (g *structGen) generate(root *shape) {
+	name := g.typeName(g.cfg.TypeName, "")
+
+	if root.kinds == objectKind {
+		g.queue = append(g.queue, pendingStruct{name, root, ""})
+	} else {
+		fmt.Fprintf(&g.decls, "type %s %s\n\n", name, g.goType(root, name+"Item", "", true))
+	}
+
+	for len(g.queue) > 0 {
+		ps := g.queue[0]
+		g.queue = g.queue[1:]
+		g.declareStruct(ps)
+	}
+}
+
+func (g *structGen) declareStruct(ps pendingStruct) {
+	fmt.Fprintf(&g.decls, "type %s struct {\n", ps.name)
+
+	names := make(map[string]bool)
+	for _, f := range ps.s.fields {
+		name := exportedName(f.key)
+		for i := 2; names[name]; i++ {
+			name = exportedName(f.key) + strconv.Itoa(i)
+		}
+		names[name] = true
+
+		var (
+			missing = f.present < ps.s.objects
+			typ     = g.goType(&f.shape, ps.name+name, ps.path+"/"+escapePointerToken(f.key), false)
+		)
+
+		if missing || f.shape.nulls > 0 {
+			if !strings.HasPrefix(typ, "[]") && typ != "interface{}" && typ != "json.RawMessage" {
+				typ = "*" + typ
+			}
+		}
+
+		tag, ok := jsonTag(f.key, missing)
+		if !ok {
+			fmt.Fprintf(&g.decls, "\t// The key %s can't be named in a struct tag, so it isn't decoded.\n", strconv.Quote(f.key))
+		}
+
+		fmt.Fprintf(&g.decls, "\t%s %s `%s`\n", name, typ, tag)
+	}
+
+	g.decls.WriteString("}\n\n")
+}
+
+// jsonTag returns the json struct tag of the field holding the values of key. It reports false if encoding/json
+// can't match key with a tag, like a key holding a comma or a quote, in which case the field is ignored.
+func jsonTag(key string, omitempty bool) (string, bool) {
+	if !isValidTagName(key) {
+		return `json:"-"`, false
+	}
+
+	switch {
+	case omitempty:
+		key += ",omitempty"
+	case key == "-":
+		// "-" alone ignores the field.
+		key += ","
+	}
+
+	return `json:"` + key + `"`, true
+}
+
+// isValidTagName reports whether encoding/json accepts s as the name of a field in a struct tag.
+func isValidTagName(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, r := range s {
+		switch {
+		case strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", r):
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			return false
+		}
+	}
+
+	return true
+}
+
+// goType returns the Go type of the values of shape s, queueing the struct types it needs.
+//
+// name is the default name of the struct type of s if it is an object.
+func (g *structGen) goType(s *shape, name, path string, root bool) string {
+	switch s.kinds {
+	case boolKind:
+		return "bool"
+	case intKind:
+		return "int64"
+	case intKind | floatKind, floatKind:
+		return "float64"
+	case stringKind:
+		return "string"
+	case objectKind:
+		name = g.typeName(name, path)
+		g.queue = append(g.queue, pendingStruct{name, s, path})
+		return name
+	case arrayKind:
+		if s.elem == nil {
+			return "[]interface{}"
+		}
+		if !root {
+			name += "Item"
+		}
+		return "[]" + g.goType(s.elem, name, path+"/-", false)
+	case 0:
+		return "interface{}"
+	}
+
+	if g.cfg.RawMessage {
+		g.rawMessage = true
+		return "json.RawMessage"
+	}
+
+	return "interface{}"
+}
+
+// typeName returns a unique type name for the object at path.
+func (g *structGen) typeName(name, path string) string {
+	if n, ok := g.cfg.TypeNames[path]; ok {
+		name = n
+	}
+
+	unique := name
+	for i := 2; g.used[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	g.used[unique] = true
+
+	return unique
+}
+
+var commonInitialisms = map[string]bool{
+	"API": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true,
+	"SQL": true, "TCP": true, "TTL": true, "UI": true, "URI": true, "URL": true, "UUID": true,
+}
+
+// exportedName turns a key into an exported Go identifier, like "user_id" into "UserID".
+func exportedName(key string) string {
+	words := strings.FieldsFunc(key, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var sb strings.Builder
+	for _, w := range words {
+		if upper := strings.ToUpper(w); commonInitialisms[upper] {
+			sb.WriteString(upper)
+			continue
+		}
+
+		rs := []rune(w)
+		rs[0] = unicode.ToUpper(rs[0])
+		sb.WriteString(string(rs))
+	}
+
+	name := sb.String()
+	switch {
+	case name == "":
+		return "Field"
+	case !unicode.IsUpper([]rune(name)[0]):
+		return "X" + name
+	}
+
+	return name
+}
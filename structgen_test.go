@@ -0,0 +1,157 @@
+package bari_test
+
+import (
+	"encoding/json"
+	"flag"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/bari"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestGenerateStructs(t *testing.T) {
+	testCases := []struct {
+		sample string
+		cfg    bari.GenConfig
+	}{
+		{"users.ndjson", bari.GenConfig{Package: "users", TypeName: "User"}},
+		{"keys.ndjson", bari.GenConfig{TypeName: "Keys"}},
+		{"events.json", bari.GenConfig{
+			Package:    "events",
+			RawMessage: true,
+			TypeNames:  map[string]string{"/-/meta": "Modifiers"},
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.sample, func(t *testing.T) {
+			f, err := os.Open("./testdata/structgen/" + tc.sample)
+			require.Nil(t, err)
+			defer f.Close()
+
+			src, err := bari.GenerateStructs(f, tc.cfg)
+			require.Nil(t, err)
+
+			golden := "./testdata/structgen/" + tc.sample + ".golden"
+			if *update {
+				require.Nil(t, ioutil.WriteFile(golden, src, 0644))
+			}
+
+			exp, err := ioutil.ReadFile(golden)
+			require.Nil(t, err)
+			require.Equal(t, string(exp), string(src))
+
+			typeCheck(t, src)
+		})
+	}
+}
+
+func typeCheck(t *testing.T, src []byte) {
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, "generated.go", src, 0)
+	require.Nil(t, err)
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check(file.Name.Name, fset, []*ast.File{file}, nil)
+	require.Nil(t, err)
+}
+
+// TestGenerateStructsRoundTrip decodes the samples with the generated types and encodes them again, which must
+// give back the keys which can be named in a struct tag.
+func TestGenerateStructsRoundTrip(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+
+	data, err := ioutil.ReadFile("./testdata/structgen/keys.ndjson")
+	require.Nil(t, err)
+
+	src, err := bari.GenerateStructs(strings.NewReader(string(data)), bari.GenConfig{TypeName: "Keys"})
+	require.Nil(t, err)
+
+	dir := t.TempDir()
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "types.go"), src, 0644))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte(roundTripMain), 0644))
+
+	cmd := exec.Command(goBin, "run", "types.go", "main.go")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GO111MODULE=off")
+	cmd.Stdin = strings.NewReader(string(data))
+	out, err := cmd.Output()
+	require.Nil(t, err, "%s", out)
+
+	// The keys which can't be named in a tag are left out.
+	unnamed := []string{`a"b`, "c,d", "", "it's"}
+
+	expected := json.NewDecoder(strings.NewReader(string(data)))
+	actual := json.NewDecoder(strings.NewReader(string(out)))
+	for expected.More() {
+		var exp, act map[string]interface{}
+		require.Nil(t, expected.Decode(&exp))
+		require.Nil(t, actual.Decode(&act))
+
+		for _, key := range unnamed {
+			delete(exp, key)
+			delete(exp["nested"].(map[string]interface{}), key)
+		}
+		require.Equal(t, exp, act)
+	}
+	require.False(t, actual.More())
+}
+
+const roundTripMain = `package main
+
+import (
+	"encoding/json"
+	"os"
+)
+
+func main() {
+	dec := json.NewDecoder(os.Stdin)
+	enc := json.NewEncoder(os.Stdout)
+	for dec.More() {
+		var doc Keys
+		if err := dec.Decode(&doc); err != nil {
+			panic(err)
+		}
+		if err := enc.Encode(doc); err != nil {
+			panic(err)
+		}
+	}
+}
+`
+
+func TestGenerateStructsDeterministic(t *testing.T) {
+	data, err := ioutil.ReadFile("./testdata/structgen/users.ndjson")
+	require.Nil(t, err)
+
+	src1, err := bari.GenerateStructs(strings.NewReader(string(data)), bari.GenConfig{})
+	require.Nil(t, err)
+	src2, err := bari.GenerateStructs(strings.NewReader(string(data)), bari.GenConfig{})
+	require.Nil(t, err)
+
+	require.Equal(t, src1, src2)
+	require.True(t, strings.HasPrefix(string(src1), "// Code generated by bari. DO NOT EDIT.\n\npackage main\n"))
+}
+
+func TestGenerateStructsErrors(t *testing.T) {
+	_, err := bari.GenerateStructs(strings.NewReader(""), bari.GenConfig{})
+	require.NotNil(t, err)
+
+	_, err = bari.GenerateStructs(strings.NewReader(`{"a": 1} {"a": `), bari.GenConfig{})
+	require.NotNil(t, err)
+}
@@ -0,0 +1,13 @@
+package bari
+
+// flushTee writes the bytes parsed since the last call to the writer given to Tee.
+func (p *Parser) flushTee() error {
+	if p.opts.tee == nil || len(p.teed) == 0 {
+		return nil
+	}
+
+	_, err := p.opts.tee.Write(p.teed)
+	p.teed = p.teed[:0]
+
+	return err
+}
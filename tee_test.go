@@ -0,0 +1,79 @@
+package bari_test
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/bari"
+)
+
+func TestTee(t *testing.T) {
+	const data = " {}[1]\n{\"a\": \"b\"}  [[], {\"c\": 2.5e1}]\t[\"é\"]{}\n "
+
+	for _, r := range []io.Reader{strings.NewReader(data), bufio.NewReader(strings.NewReader(data)), &byteScanner{data: data}} {
+		var mirror bytes.Buffer
+		p := bari.NewParser(r, bari.Tee(&mirror), bari.DocumentEvents())
+
+		documents := 0
+		for {
+			ev, err := p.Next()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+
+			// The mirror holds every document returned so far, even though the parser read further.
+			if ev.Type == bari.DocumentEndEvent {
+				require.Equal(t, data[:ev.Offset], mirror.String())
+				documents++
+			}
+		}
+		require.Equal(t, 6, documents)
+		require.Equal(t, data, mirror.String())
+	}
+
+	// The value of a document which is not a container ends after the byte following it.
+	var mirror bytes.Buffer
+	p := bari.NewParser(strings.NewReader("12 "), bari.Tee(&mirror), bari.StrictMode())
+	ev, err := p.Next()
+	require.NoError(t, err)
+	require.Equal(t, bari.NumberEvent, ev.Type)
+	require.Equal(t, "12", mirror.String())
+	require.NoError(t, p.ParseFunc(func(bari.Event) bool { return true }))
+	require.Equal(t, "12 ", mirror.String())
+
+	// The bytes discarded by SkipValue are written too.
+	mirror.Reset()
+	p = bari.NewParser(strings.NewReader(`[{"a": [1, "]"]}, 2]`), bari.Tee(&mirror))
+	_, err = p.Next()
+	require.NoError(t, err)
+	require.NoError(t, p.SkipValue())
+	require.NoError(t, p.ParseFunc(func(bari.Event) bool { return true }))
+	require.Equal(t, `[{"a": [1, "]"]}, 2]`, mirror.String())
+}
+
+type failingWriter struct{}
+
+var errWrite = errors.New("write failed")
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errWrite
+}
+
+func TestTeeError(t *testing.T) {
+	p := bari.NewParser(strings.NewReader(`[1] [2]`), bari.Tee(failingWriter{}))
+
+	var events []bari.Event
+	err := p.ParseFunc(func(ev bari.Event) bool {
+		events = append(events, ev)
+		return true
+	})
+	require.True(t, errors.Is(err, errWrite))
+	require.Equal(t, bari.EOFEvent, events[len(events)-1].Type)
+	require.Equal(t, bari.ArrayEndEvent, events[len(events)-2].Type)
+}
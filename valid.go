@@ -0,0 +1,38 @@
+package bari
+
+import "io"
+
+// Valid reports whether data holds one or more valid documents, parsed like by NewBytesParser with opts. Every
+// document must be valid, and input holding no document is not.
+//
+// It checks the syntax of the input, without decoding its strings nor converting its numbers.
+func Valid(data []byte, opts ...Option) bool {
+	return validate(NewBytesParser(data, opts...), false) == nil
+}
+
+// ValidReader is like Valid for the input read from r, returning the ParseError of the first error if it is not
+// valid.
+func ValidReader(r io.Reader, opts ...Option) error {
+	return validate(NewParser(r, opts...), true)
+}
+
+// validate parses the input of p without decoding its values, nor its keys unless keys is set.
+func validate(p *Parser, keys bool) error {
+	p.opts.validate = true
+	// Duplicates are found among the decoded keys.
+	p.opts.validateKeys = keys || p.opts.disallowDuplicateKeys
+	p.opts.requireDocument = true
+
+	for {
+		ev, err := p.Next()
+		switch {
+		case err == io.EOF:
+			return nil
+		case err != nil:
+			return err
+		case ev.Error != nil:
+			// A recoverable error, if the parser collects errors or skips invalid values.
+			return ev.Error
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package bari_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vrischmann/bari"
+)
+
+func TestValid(t *testing.T) {
+	testCases := []struct {
+		data  string
+		valid bool
+	}{
+		{`{}`, true},
+		{`[1, -2.5e10, "aé\n", true, false, null, {"a": [{}]}]`, true},
+		{`{"a": 1e400, "b": 123456789012345678901234567890}`, true},
+		// Every document must be valid.
+		{`{} [] {"a": 1}`, true},
+		{"[1]\n[2]\n", true},
+		{`{} [] {"a": }`, false},
+		{`[1] x`, false},
+		// There must be a document.
+		{``, false},
+		{"  \n", false},
+		{`[1, 2`, false},
+		{`["\x"]`, false},
+		{`[01]`, false},
+		{`{"a" 1}`, false},
+		{`1`, false},
+	}
+
+	for _, c := range testCases {
+		require.Equal(t, c.valid, bari.Valid([]byte(c.data)), "data: %q", c.data)
+
+		err := bari.ValidReader(strings.NewReader(c.data))
+		require.Equal(t, c.valid, err == nil, "data: %q", c.data)
+		if !c.valid {
+			// The error is the one the parser emits.
+			events := parseAll(c.data, bari.RequireDocument())
+			require.Equal(t, events[len(events)-1].Error, err, "data: %q", c.data)
+		}
+	}
+
+	// The options of the parser apply.
+	require.True(t, bari.Valid([]byte(`1`), bari.StrictMode()))
+	require.False(t, bari.Valid([]byte(`1 2`), bari.StrictMode()))
+	require.True(t, bari.Valid([]byte(`{"a": 1, "b": {"a": 2}}`), bari.DisallowDuplicateKeys()))
+	require.False(t, bari.Valid([]byte(`{"a": 1, "b": 2, "a": 3}`), bari.DisallowDuplicateKeys()))
+
+	// Recoverable errors make the input invalid too.
+	err := bari.ValidReader(strings.NewReader(`[1, 2,]`), bari.CollectErrors())
+	require.Equal(t, "trailing comma before ]", err.(bari.ParseError).Message)
+
+	var parseErr bari.ParseError
+	require.True(t, errors.As(bari.ValidReader(strings.NewReader(`{"a": [1, x]}`)), &parseErr))
+	require.Equal(t, "/a/1", parseErr.Path)
+}
+
+func BenchmarkValid(b *testing.B) {
+	codeJSON := readTestdata(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if !bari.Valid(codeJSON) {
+			b.Fatal("invalid")
+		}
+	}
+
+	b.SetBytes(int64(len(codeJSON)))
+}
+
+func BenchmarkEncodingJSONValid(b *testing.B) {
+	codeJSON := readTestdata(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if !json.Valid(codeJSON) {
+			b.Fatal("invalid")
+		}
+	}
+
+	b.SetBytes(int64(len(codeJSON)))
+}